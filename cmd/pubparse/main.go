@@ -1,16 +1,24 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/ashahide/pubparse/internal/customErrors"
+	"github.com/ashahide/pubparse/internal/exportTools"
 	"github.com/ashahide/pubparse/internal/fileIO"
 	"github.com/ashahide/pubparse/internal/jsonTools"
+	"github.com/ashahide/pubparse/internal/xmlTools"
 )
 
 //
@@ -22,11 +30,21 @@ main is the entry point for the pubparse command-line tool.
 
 Behavior:
   - Delegates execution to run().
-  - Logs any error returned and exits with status code 1.
+  - Logs any error returned and exits with status code 1, 2 when a
+    --continue-on-error run wrote some outputs but not all, or 130 when the
+    run was interrupted by SIGINT or SIGTERM.
 */
 func main() {
 	if err := run(); err != nil {
 		log.Println("Error:", err)
+		var partial *customErrors.PartialFailureError
+		var interrupted *customErrors.InterruptedError
+		switch {
+		case errors.As(err, &interrupted):
+			os.Exit(130)
+		case errors.As(err, &partial):
+			os.Exit(2)
+		}
 		os.Exit(1)
 	}
 }
@@ -43,9 +61,28 @@ Returns:
   - An error if any stage in the processing pipeline fails.
 
 Behavior:
-  - Supports subcommands: "pubmed" or "pmc".
+  - Supports subcommands: "pubmed" or "pmc", "cite" to convert existing JSON
+    output to bibtex, ris or csl-json, and "validate" (see runValidate).
   - Required flags: -i (input), -o (output).
   - Optional flag: --workers (number of concurrent goroutines, default 8).
+  - Optional flag: --format (json, jsonl, parquet, tsv, sqlite, bibtex, ris, csl-json, text, markdown, chunks, bioc-xml, bioc-json or es-bulk, default json).
+  - Optional flag: --lenient (retry malformed XML with a non-strict decoder).
+  - Optional flags: --split / --split-versions (one JSON file per PubMed article).
+  - Optional flags: --drop-references / --strip-citations (text and markdown output).
+  - Optional flags: --chunk-size / --chunk-unit (chunks output, default 1000 chars).
+  - Optional flag: --index (index name for es-bulk output, default pubparse).
+  - Optional flags: --fields / --exclude (comma-separated dotted field paths to keep or drop).
+  - Optional flag: --legacy-keys (JSON keys as Go field names, schema_version 1.0).
+  - Optional flag: --on-invalid (fail, warn or quarantine documents failing schema validation).
+  - Optional flags: --profile / --schema (validation profile and user schemas on top of the bundled ones).
+  - Optional flag: --disable-rules (semantic rules to skip).
+  - Optional flag: --continue-on-error (per-input status in the report and a failures.txt
+    that -i accepts; exit status 2 on partial failure).
+  - Optional flag: --incremental (alias --resume; skip inputs whose outputs are current
+    according to manifest.jsonl in the output directory, keeping those outputs).
+  - On SIGINT or SIGTERM, pubmed and pmc runs finish the files being written, list the
+    rest in pending.txt (which -i accepts) and exit with status 130; a second signal
+    exits immediately.
   - Validates file count alignment between input/output.
   - Creates a report file and logs session metadata.
   - Delegates parallel file processing to jsonTools.ProcessAllFiles.
@@ -53,11 +90,14 @@ Behavior:
 func run() error {
 	// Ensure a valid subcommand is provided
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: pubparse [pubmed|pmc] -i input_path -o output_path [--workers N]")
+		fmt.Println("Usage: pubparse [pubmed|pmc|cite|validate] -i input_path -o output_path [--workers N]")
 		os.Exit(1)
 	}
 
 	mode := os.Args[1] // Subcommand: "pubmed" or "pmc"
+	if mode == "validate" {
+		return runValidate(os.Args[2:])
+	}
 	var args fileIO.Arguments
 	var workers int
 
@@ -68,11 +108,85 @@ func run() error {
 		cmd.StringVar(&args.InputPath.Path, "i", "", "Path to the input file or directory")
 		cmd.StringVar(&args.OutputPath.Path, "o", "", "Path to the output file or directory")
 		cmd.IntVar(&workers, "workers", 8, "Number of concurrent workers (default: 8)")
+		cmd.StringVar(&args.Format, "format", "json", "Output format: json, jsonl, parquet, tsv, sqlite, bibtex, ris, csl-json, text, markdown, chunks, bioc-xml, bioc-json or es-bulk")
+		cmd.BoolVar(&args.Lenient, "lenient", false, "Retry malformed XML with a non-strict decoder")
+		cmd.BoolVar(&args.Split, "split", false, "Write each PubMed article to <pmid>.json")
+		cmd.BoolVar(&args.SplitVersions, "split-versions", false, "With --split, name files <pmid>.v<version>.json")
+		cmd.BoolVar(&args.DropReferences, "drop-references", false, "Omit the reference list from text and markdown output")
+		cmd.BoolVar(&args.StripCitations, "strip-citations", false, "Remove in-text citation markers from text and markdown output")
+		cmd.IntVar(&args.ChunkSize, "chunk-size", 1000, "Maximum chunk size for --format chunks, in --chunk-unit")
+		cmd.StringVar(&args.ChunkUnit, "chunk-unit", "chars", "Unit of --chunk-size: chars or tokens (approx. 4 chars each)")
+		cmd.StringVar(&args.IndexName, "index", exportTools.DefaultIndexName, "Index name for --format es-bulk")
+		fields := cmd.String("fields", "", "Comma-separated field paths to keep, e.g. medline_citation.article.article_title")
+		exclude := cmd.String("exclude", "", "Comma-separated field paths to drop")
+		cmd.BoolVar(&args.LegacyKeys, "legacy-keys", false, "Write the pre-2.0 JSON keys (Go field names) instead of snake_case")
+		cmd.StringVar(&args.OnInvalid, "on-invalid", "fail", "Policy for documents failing schema validation: fail, warn or quarantine")
+		cmd.StringVar(&args.Profile, "profile", "default", "Validation profile: default, citation or text-mining")
+		cmd.StringVar(&args.SchemaPath, "schema", "", "Extra article schema file, or a directory of per-type schemas")
+		disableRules := cmd.String("disable-rules", "", "Comma-separated semantic rules to skip, or all")
+		cmd.BoolVar(&args.ContinueOnError, "continue-on-error", false, "Process every input and list the failed ones in failures.txt")
+		cmd.BoolVar(&args.Incremental, "incremental", false, "Skip inputs whose outputs are current according to manifest.jsonl")
+		cmd.BoolVar(&args.Incremental, "resume", false, "Same as --incremental")
+		if err := cmd.Parse(os.Args[2:]); err != nil {
+			return err
+		}
+
+		// Validate field paths before touching any input
+		projection, err := xmlTools.NewProjection(mode, splitList(*fields), splitList(*exclude))
+		if err != nil {
+			return err
+		}
+		args.Projection = projection
+		args.DisabledRules = splitList(*disableRules)
+	case "cite":
+		cmd := flag.NewFlagSet(mode, flag.ExitOnError)
+		cmd.StringVar(&args.InputPath.Path, "i", "", "Path to a pubparse JSON/JSONL file or directory of JSON/JSONL files")
+		cmd.StringVar(&args.OutputPath.Path, "o", "", "Path to the output directory")
+		cmd.StringVar(&args.Format, "format", "bibtex", "Citation format: bibtex, ris or csl-json")
 		if err := cmd.Parse(os.Args[2:]); err != nil {
 			return err
 		}
+		args.InputExts = []string{"json", "jsonl"}
+		workers = 1
+	default:
+		return fmt.Errorf("unknown subcommand: %s\nUsage: pubparse [pubmed|pmc|cite|validate] -i input -o output [--workers N]", mode)
+	}
+
+	// Validate output format
+	switch {
+	case mode == "cite" && !exportTools.IsCitationFormat(args.Format):
+		return fmt.Errorf("unknown citation format: %s (expected bibtex, ris or csl-json)", args.Format)
+	case mode == "cite":
+	case exportTools.IsCitationFormat(args.Format), exportTools.IsTextFormat(args.Format), exportTools.IsBioCFormat(args.Format):
+	case args.Format == "json", args.Format == "jsonl", args.Format == "parquet", args.Format == "tsv", args.Format == "sqlite", args.Format == "chunks", args.Format == "es-bulk":
 	default:
-		return fmt.Errorf("unknown subcommand: %s\nUsage: pubparse [pubmed|pmc] -i input -o output [--workers N]", mode)
+		return fmt.Errorf("unknown output format: %s (expected json, jsonl, parquet, tsv, sqlite, bibtex, ris, csl-json, text, markdown, chunks, bioc-xml, bioc-json or es-bulk)", args.Format)
+	}
+	if args.Format == "chunks" && (args.ChunkSize <= 0 || (args.ChunkUnit != "chars" && args.ChunkUnit != "tokens")) {
+		return fmt.Errorf("invalid chunk size: %d %s (expected a positive size in chars or tokens)", args.ChunkSize, args.ChunkUnit)
+	}
+	if args.Format == "es-bulk" {
+		if err := exportTools.ValidateIndexName(args.IndexName); err != nil {
+			return err
+		}
+	}
+	if mode != "cite" && !jsonTools.IsInvalidPolicy(args.OnInvalid) {
+		return fmt.Errorf("unknown --on-invalid policy: %s (expected fail, warn or quarantine)", args.OnInvalid)
+	}
+	if _, err := jsonTools.LoadValidation(args.Profile, args.SchemaPath); err != nil {
+		return err
+	}
+	if _, err := jsonTools.CheckRuleNames(args.DisabledRules); err != nil {
+		return err
+	}
+	if args.Split && args.Format != "json" {
+		return fmt.Errorf("--split writes one JSON document per article and cannot be combined with --format %s", args.Format)
+	}
+	if args.Incremental && args.Split {
+		return fmt.Errorf("--incremental needs one output file per input and cannot be combined with --split")
+	}
+	if args.Incremental && fileIO.AggregateFormat(args.Format) {
+		return fmt.Errorf("--incremental needs one output file per input and cannot be combined with --format %s", args.Format)
 	}
 
 	// Validate worker count
@@ -82,6 +196,14 @@ func run() error {
 		fmt.Printf("Warning: Specified %d workers, but only %d CPU cores available. Setting workers = %d\n", workers, runtime.NumCPU(), runtime.NumCPU())
 	}
 
+	// From here on, the first SIGINT/SIGTERM stops a conversion run cleanly
+	ctx := context.Background()
+	if mode != "cite" {
+		var stop context.CancelFunc
+		ctx, stop = interruptContext()
+		defer stop()
+	}
+
 	// Validate and resolve input/output paths and match file counts
 	if err := fileIO.HandleInputs(&args); err != nil {
 		return fmt.Errorf("input handling failed: %w", err)
@@ -128,15 +250,182 @@ func run() error {
 	fmt.Println(">>> Workers:", workers)
 	fmt.Println(">>> Starting Time:", startTime.Format("2006-01-02 15:04:05"))
 
-	// Begin concurrent file processing
-	if err := jsonTools.ProcessAllFiles(args, mode, report, workers); err != nil {
+	// Begin concurrent file processing (cite converts existing JSON instead).
+	// With --continue-on-error, a partial failure still ends with the summary.
+	// An interrupted run ends with the summary too.
+	var partial *customErrors.PartialFailureError
+	var interrupted *customErrors.InterruptedError
+	if mode == "cite" {
+		if err := jsonTools.CiteAllFiles(args, report); err != nil {
+			return fmt.Errorf("citation export failed: %w", err)
+		}
+	} else if err := jsonTools.ProcessAllFiles(ctx, args, mode, report, workers); err != nil && !errors.As(err, &partial) && !errors.As(err, &interrupted) {
 		return fmt.Errorf("processing failed: %w", err)
 	}
 
+	// Record the end of the run in the report
+	reportFooter := fmt.Sprintf(">>> Ending Time: %s\n>>> Elapsed Time: %s\n", time.Now().Format("2006-01-02 15:04:05"), time.Since(startTime))
+	if _, err := report.WriteString(reportFooter); err != nil {
+		return fmt.Errorf("failed to write to report file %q: %w", reportPath, err)
+	}
+
 	// Final summary
 	fmt.Println("\n>>> Finished processing files.")
 	fmt.Println(">>> Report file:", reportPath)
 	fmt.Println(">>> Elapsed Time:", time.Since(startTime))
+	if interrupted != nil {
+		fmt.Printf(">>> Interrupted: %d completed, %d failed, %d pending\n", interrupted.Completed, interrupted.Failed, interrupted.Pending)
+		fmt.Println(">>> Pending inputs:", interrupted.Manifest)
+		return interrupted
+	}
+	if partial != nil {
+		fmt.Println(">>> Failed inputs:", partial.Manifest)
+		return partial
+	}
 	fmt.Println(">>> Exiting...")
 	return nil
 }
+
+//
+// ------------------------ interruptContext ------------------------
+//
+
+/*
+interruptContext returns a context cancelled by the first SIGINT or SIGTERM.
+
+Behavior:
+  - Prints a notice when the signal arrives, then restores the default handling,
+    so a second signal terminates the process at once.
+
+Returns:
+  - The context and a function releasing the signal handler.
+*/
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			fmt.Printf("\n>>> Received %s: finishing the files being written (send again to abort)\n", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+	}()
+	return ctx, cancel
+}
+
+//
+// ------------------------ runValidate ------------------------
+//
+
+/*
+runValidate implements `pubparse validate`: it checks existing .json/.jsonl output
+against the current schemas without re-parsing any XML.
+
+Parameters:
+  - argv: The arguments after the subcommand.
+
+Behavior:
+  - Required flag: -i (an output file, or a directory searched recursively).
+  - Optional flag: -o (failure list, JSON Lines, default validation_failures.jsonl).
+  - Optional flags: --workers, --profile, --schema, as for conversion.
+  - Prints a summary and one line per failing file, and writes every violation to the failure list.
+
+Returns:
+  - An error if the flags are invalid, the list cannot be written or any file failed.
+*/
+func runValidate(argv []string) error {
+	var args fileIO.Arguments
+	var workers int
+	var failuresPath string
+
+	cmd := flag.NewFlagSet("validate", flag.ExitOnError)
+	cmd.StringVar(&args.InputPath.Path, "i", "", "Path to a pubparse JSON/JSONL file or directory tree")
+	cmd.StringVar(&failuresPath, "o", "validation_failures.jsonl", "Path to the JSON Lines failure list")
+	cmd.IntVar(&workers, "workers", 8, "Number of concurrent workers (default: 8)")
+	cmd.StringVar(&args.Profile, "profile", "default", "Validation profile: default, citation or text-mining")
+	cmd.StringVar(&args.SchemaPath, "schema", "", "Extra article schema file, or a directory of per-type schemas")
+	if err := cmd.Parse(argv); err != nil {
+		return err
+	}
+
+	if args.InputPath.Path == "" {
+		return fmt.Errorf("input path is required")
+	}
+	if workers <= 0 {
+		return fmt.Errorf("invalid number of workers: %d", workers)
+	}
+	if _, err := jsonTools.LoadValidation(args.Profile, args.SchemaPath); err != nil {
+		return err
+	}
+
+	// Find every .json/.jsonl file under the input path
+	absPath, err := filepath.Abs(args.InputPath.Path)
+	if err != nil {
+		return fmt.Errorf("input handling failed: %w", err)
+	}
+	args.InputPath.Path = absPath
+	info, err := fileIO.VerifyPath(absPath, "")
+	if err != nil {
+		return fmt.Errorf("input handling failed: %w", err)
+	}
+	args.InputPath.Info = info
+	if args.InputPath, err = fileIO.LoadFilesInTree(args.InputPath, "json", "jsonl"); err != nil {
+		return fmt.Errorf("input handling failed: %w", err)
+	}
+
+	startTime := time.Now()
+	fmt.Println(">>> Input Path:", args.InputPath.Path)
+	fmt.Println(">>> Number of Inputs:", len(args.InputPath.Files))
+	fmt.Println(">>> Workers:", workers)
+
+	results, err := jsonTools.ValidateAllFiles(args, workers)
+	if err != nil {
+		return err
+	}
+	if err := jsonTools.WriteValidationFailures(results, failuresPath); err != nil {
+		return err
+	}
+
+	// Summary: counts, then each failing file
+	var documents, skipped, failed int
+	fmt.Println()
+	for _, result := range results {
+		documents += result.Documents
+		switch {
+		case result.Skipped:
+			skipped++
+		case result.Err != nil:
+			failed++
+			fmt.Printf(">>> Error: %s\t %v\n", result.File, result.Err)
+		case result.Invalid != nil:
+			failed++
+			fmt.Printf(">>> Invalid: %s\t %d violations\n", result.File, len(result.Invalid.Violations))
+		}
+	}
+	fmt.Println(">>> Files validated:", len(results)-skipped)
+	fmt.Println(">>> Files skipped (not pubparse output):", skipped)
+	fmt.Println(">>> Documents validated:", documents)
+	fmt.Println(">>> Files failing:", failed)
+	fmt.Println(">>> Failure list:", failuresPath)
+	fmt.Println(">>> Elapsed Time:", time.Since(startTime))
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed validation", failed, len(results)-skipped)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/ashahide/pubparse/internal/jsonTools"
+)
+
+//
+// ------------------------ main ------------------------
+//
+
+/*
+main regenerates the bundled JSON schemas from the xmlTools structs.
+
+Behavior:
+  - Writes pubmed_json_schema.json and pmc_json_schema.json to the -o directory
+    (default internal/jsonTools), replacing the checked-in files.
+  - Run through `go generate ./internal/jsonTools` after changing the structs;
+    the jsonTools tests fail while the checked-in schemas are stale.
+*/
+func main() {
+	if err := run(); err != nil {
+		log.Println("Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	outputDir := flag.String("o", filepath.Join("internal", "jsonTools"), "Directory receiving the generated schemas")
+	flag.Parse()
+
+	schemas, err := jsonTools.GenerateSchemas()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(*outputDir, name)
+		if err := os.WriteFile(path, schemas[name], 0644); err != nil {
+			return fmt.Errorf("failed to write %q: %w", path, err)
+		}
+		fmt.Println(">>> Wrote", path)
+	}
+	return nil
+}
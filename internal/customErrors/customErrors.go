@@ -13,3 +13,82 @@ type WrongExtensionError struct {
 func (e *WrongExtensionError) Error() string {
 	return fmt.Sprintf("wrong file extension: expected %s, got %s", e.Expected, e.Actual)
 }
+
+// Violation is one way a JSON document fails its schema.
+type Violation struct {
+	Line        int    // 1-based record of a JSON Lines file; 0 for single documents
+	Pointer     string // JSON pointer to the offending value, e.g. "/pubmed_articles/1/pubmed_data/history"; "" for the root
+	Description string // What the schema expected
+}
+
+// ValidationError reports a JSON document that does not conform to its schema.
+type ValidationError struct {
+	File       string // Output file the document was written or destined to
+	Schema     string // Schema reference it was validated against
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msg := fmt.Sprintf("%d schema violation(s) against %s", len(e.Violations), e.Schema)
+	if e.File != "" {
+		msg = e.File + ": " + msg
+	}
+	for i, v := range e.Violations {
+		if i == 3 {
+			return msg + fmt.Sprintf("; and %d more", len(e.Violations)-i)
+		}
+		msg += "; " + v.String()
+	}
+	return msg
+}
+
+// String formats the violation as "[line N ]<pointer>: <description>".
+func (v Violation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	if v.Line > 0 {
+		pointer = fmt.Sprintf("line %d %s", v.Line, pointer)
+	}
+	return pointer + ": " + v.Description
+}
+
+// ParseError reports an input file whose XML could not be decoded.
+type ParseError struct {
+	File string // Input XML file
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse XML %q: %v", e.File, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// PartialFailureError reports a --continue-on-error run in which some inputs failed
+// while the others were written.
+type PartialFailureError struct {
+	Failed   int    // Inputs that failed
+	Total    int    // Inputs in the run
+	Manifest string // failures.txt listing the failed inputs, one per line
+}
+
+func (e *PartialFailureError) Error() string {
+	return fmt.Sprintf("%d of %d inputs failed; see %s", e.Failed, e.Total, e.Manifest)
+}
+
+// InterruptedError reports a run stopped by SIGINT or SIGTERM before every input
+// was processed.
+type InterruptedError struct {
+	Completed int    // Inputs written before the run stopped
+	Failed    int    // Inputs that failed before the run stopped
+	Pending   int    // Inputs not processed
+	Manifest  string // pending.txt listing the pending inputs, one per line
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("interrupted with %d input(s) pending (%d completed, %d failed); see %s", e.Pending, e.Completed, e.Failed, e.Manifest)
+}
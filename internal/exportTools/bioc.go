@@ -0,0 +1,389 @@
+package exportTools
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+// BioCFormats lists the BioC encodings accepted by --format.
+var BioCFormats = []string{"bioc-xml", "bioc-json"}
+
+// IsBioCFormat reports whether format is one of BioCFormats.
+func IsBioCFormat(format string) bool {
+	for _, f := range BioCFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// BioCCollection is one input file; each article is a document.
+type BioCCollection struct {
+	XMLName   xml.Name       `xml:"collection" json:"-"`
+	Source    string         `xml:"source" json:"source"`
+	Date      string         `xml:"date" json:"date"`
+	Key       string         `xml:"key" json:"key"`
+	Infons    BioCInfons     `xml:"infon" json:"infons"`
+	Documents []BioCDocument `xml:"document" json:"documents"`
+}
+
+// BioCDocument is one article.
+type BioCDocument struct {
+	ID          string        `xml:"id" json:"id"`
+	Infons      BioCInfons    `xml:"infon" json:"infons"`
+	Passages    []BioCPassage `xml:"passage" json:"passages"`
+	Annotations []interface{} `xml:"-" json:"annotations"`
+	Relations   []interface{} `xml:"-" json:"relations"`
+}
+
+// BioCPassage is a title, paragraph, caption or reference. Offset is the
+// character offset of Text in the document.
+type BioCPassage struct {
+	Infons      BioCInfons    `xml:"infon" json:"infons"`
+	Offset      int           `xml:"offset" json:"offset"`
+	Text        string        `xml:"text" json:"text"`
+	Sentences   []interface{} `xml:"-" json:"sentences"`
+	Annotations []interface{} `xml:"-" json:"annotations"`
+	Relations   []interface{} `xml:"-" json:"relations"`
+}
+
+// BioCInfons are key-value annotations: an object in BioC JSON and a list of
+// <infon key="..."> elements in BioC XML.
+type BioCInfons map[string]string
+
+// MarshalXML writes one <infon> element per key, sorted by key.
+func (in BioCInfons) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	keys := make([]string, 0, len(in))
+	for k := range in {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		el := xml.StartElement{Name: xml.Name{Local: "infon"}, Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: k}}}
+		if err := e.EncodeElement(in[k], el); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//
+// ------------------------ WriteBioC ------------------------
+//
+
+/*
+WriteBioC writes a parsed input as a BioC collection.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle,
+    already normalized.
+  - fileName: Path of the output file.
+  - format: "bioc-xml" or "bioc-json".
+  - date: Collection date, e.g. the modification time of the input, so that the same
+    input always gives the same file.
+
+Returns:
+  - An error if conversion or writing fails.
+*/
+func WriteBioC(data interface{}, fileName, format string, date time.Time) error {
+	collection, err := NewBioCCollection(data, date)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("failed to create BioC file %q: %w", fileName, err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := EncodeBioC(w, collection, format); err != nil {
+		return fmt.Errorf("failed to write BioC file %q: %w", fileName, err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write BioC file %q: %w", fileName, err)
+	}
+	return f.Close()
+}
+
+// EncodeBioC writes a collection as BioC XML (with the BioC.dtd doctype) or BioC JSON.
+func EncodeBioC(w io.Writer, collection BioCCollection, format string) error {
+	switch format {
+	case "bioc-xml":
+		if _, err := io.WriteString(w, xml.Header+`<!DOCTYPE collection SYSTEM "BioC.dtd">`+"\n"); err != nil {
+			return err
+		}
+		enc := xml.NewEncoder(w)
+		enc.Indent("", "  ")
+		if err := enc.Encode(collection); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+
+	case "bioc-json":
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(collection)
+
+	default:
+		return fmt.Errorf("unknown BioC format: %s (expected %s)", format, strings.Join(BioCFormats, ", "))
+	}
+}
+
+//
+// ------------------------ NewBioCCollection ------------------------
+//
+
+/*
+NewBioCCollection converts a parsed input into a BioC collection.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle.
+  - date: Collection date, written as YYYYMMDD in UTC.
+
+Behavior:
+  - Each article is a document with the PMID (PubMed) or PMCID (PMC) as its id.
+  - Passages follow the PMC BioC conventions: a "front" passage with the title, then
+    the abstract, section titles ("title_1", "title_2", ...), paragraphs, figure and
+    table captions, acknowledgments and references, each with a "type" and a
+    "section_type" infon (TITLE, ABSTRACT, INTRO, METHODS, RESULTS, DISCUSS, CONCL,
+    FIG, TABLE, REF, ...).
+  - Offsets count characters (Unicode code points); consecutive passages are
+    separated by one character, so a passage starts at the previous offset plus the
+    previous text length plus one.
+
+Returns:
+  - The collection and an error for unsupported types.
+*/
+func NewBioCCollection(data interface{}, date time.Time) (BioCCollection, error) {
+	collection := BioCCollection{
+		Date:   date.UTC().Format("20060102"),
+		Key:    "BioC.key",
+		Infons: BioCInfons{},
+	}
+
+	switch v := data.(type) {
+	case *xmlTools.PMCArticle:
+		collection.Source = "PMC"
+		collection.Documents = []BioCDocument{pmcBioCDocument(v)}
+
+	case *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet:
+		collection.Source = "PubMed"
+		records, err := FlattenArticles(v)
+		if err != nil {
+			return collection, err
+		}
+		for _, r := range records {
+			doc := newBioCDocument(r.PMID)
+			doc.add("TITLE", "front", r.Title, biocFrontInfons(r))
+			doc.add("ABSTRACT", "abstract", strings.ReplaceAll(r.Abstract, "\n\n", " "), nil)
+			collection.Documents = append(collection.Documents, doc.BioCDocument)
+		}
+
+	default:
+		return collection, fmt.Errorf("unsupported data type for export: %T", data)
+	}
+	return collection, nil
+}
+
+// biocBuilder appends passages to a document and tracks the running offset.
+type biocBuilder struct {
+	BioCDocument
+	offset int
+}
+
+func newBioCDocument(id string) *biocBuilder {
+	return &biocBuilder{BioCDocument: BioCDocument{
+		ID:          id,
+		Infons:      BioCInfons{},
+		Annotations: []interface{}{},
+		Relations:   []interface{}{},
+	}}
+}
+
+// add appends a passage unless text is empty.
+func (b *biocBuilder) add(sectionType, passageType, text string, infons BioCInfons) {
+	text = cleanText(text)
+	if text == "" {
+		return
+	}
+	if infons == nil {
+		infons = BioCInfons{}
+	}
+	infons["section_type"] = sectionType
+	infons["type"] = passageType
+
+	b.Passages = append(b.Passages, BioCPassage{
+		Infons:      infons,
+		Offset:      b.offset,
+		Text:        text,
+		Sentences:   []interface{}{},
+		Annotations: []interface{}{},
+		Relations:   []interface{}{},
+	})
+	b.offset += utf8.RuneCountInString(text) + 1
+}
+
+// biocFrontInfons holds the article identifiers, as on the PMC BioC front passage.
+func biocFrontInfons(r ArticleRecord) BioCInfons {
+	infons := BioCInfons{}
+	for key, value := range map[string]string{
+		"article-id_pmid": r.PMID,
+		"article-id_pmc":  strings.TrimPrefix(r.PMCID, "PMC"),
+		"article-id_doi":  r.DOI,
+		"source":          r.Journal,
+		"year":            r.Year,
+		"volume":          r.Volume,
+		"issue":           r.Issue,
+	} {
+		if value != "" {
+			infons[key] = value
+		}
+	}
+	return infons
+}
+
+func pmcBioCDocument(a *xmlTools.PMCArticle) BioCDocument {
+	r := FlattenPMCArticle(a)
+	doc := newBioCDocument(r.PMCID)
+	doc.add("TITLE", "front", r.Title, biocFrontInfons(r))
+
+	if abstract := a.Front.ArticleMeta.Abstract; abstract != nil {
+		for _, p := range abstract.Paragraphs {
+			doc.add("ABSTRACT", "abstract", p, nil)
+		}
+		for _, sec := range abstract.Sec {
+			doc.add("ABSTRACT", "abstract_title_1", sec.Title, nil)
+			for _, p := range sec.Paragraphs {
+				doc.add("ABSTRACT", "abstract", p, nil)
+			}
+		}
+	}
+
+	if a.Body != nil {
+		doc.sections(a.Body.Sections, 1, "")
+	}
+	if a.FloatsGroup != nil {
+		doc.floats(a.FloatsGroup.Figures, a.FloatsGroup.Tables)
+	}
+
+	if a.Back != nil {
+		if ack := a.Back.Acknowledgments; ack != nil {
+			for _, p := range ack.Paragraphs {
+				doc.add("ACK_FUND", "paragraph", p, nil)
+			}
+		}
+		if refs := a.Back.References; refs != nil {
+			for _, ref := range refs.References {
+				rec := pmcReference(ref)
+				infons := biocID(rec.ID)
+				if infons == nil {
+					infons = BioCInfons{}
+				}
+				if rec.PMID != "" {
+					infons["pub-id_pmid"] = rec.PMID
+				}
+				if rec.DOI != "" {
+					infons["pub-id_doi"] = rec.DOI
+				}
+				doc.add("REF", "ref", rec.Citation, infons)
+			}
+		}
+	}
+	return doc.BioCDocument
+}
+
+// sections adds section titles and paragraphs depth-first. Subsections without a
+// recognizable type inherit the section_type of their parent.
+func (b *biocBuilder) sections(sections []xmlTools.PMCSection, depth int, parentType string) {
+	for _, sec := range sections {
+		sectionType := biocSectionType(sec.SecType, sec.Title)
+		if sectionType == "" {
+			sectionType = parentType
+		}
+		if sectionType == "" {
+			sectionType = "OTHER"
+		}
+
+		b.add(sectionType, "title_"+strconv.Itoa(depth), sec.Title, nil)
+		for _, p := range sec.Paragraphs {
+			b.add(sectionType, "paragraph", p, nil)
+		}
+		b.floats(sec.Figures, sec.Tables)
+		b.sections(sec.SubSections, depth+1, sectionType)
+	}
+}
+
+// floats adds figure and table captions, prefixed with their labels.
+func (b *biocBuilder) floats(figures []xmlTools.PMCFigure, tables []xmlTools.PMCTableWrap) {
+	for _, fig := range figures {
+		b.add("FIG", "fig_caption", fig.Label+" "+strings.Join(fig.Caption.Paragraphs, " "), biocID(fig.ID))
+	}
+	for _, tab := range tables {
+		b.add("TABLE", "table_caption", tab.Label+" "+strings.Join(tab.Caption.Paragraphs, " "), biocID(tab.ID))
+	}
+}
+
+// biocID returns an "id" infon, or no infons for an empty id.
+func biocID(id string) BioCInfons {
+	if id == "" {
+		return nil
+	}
+	return BioCInfons{"id": id}
+}
+
+// biocSectionKeywords maps words in a JATS sec-type or section title to PMC BioC
+// section types. The first matching entry wins, so "Results and discussion" is RESULTS.
+var biocSectionKeywords = []struct {
+	keyword     string
+	sectionType string
+}{
+	{"intro", "INTRO"},
+	{"background", "INTRO"},
+	{"method", "METHODS"},
+	{"material", "METHODS"},
+	{"result", "RESULTS"},
+	{"finding", "RESULTS"},
+	{"discussion", "DISCUSS"},
+	{"conclusion", "CONCL"},
+	{"case", "CASE"},
+	{"supplementary", "SUPPL"},
+	{"abbreviation", "ABBR"},
+	{"acknowledg", "ACK_FUND"},
+	{"funding", "ACK_FUND"},
+	{"contribution", "AUTH_CONT"},
+	{"competing", "COMP_INT"},
+	{"conflict", "COMP_INT"},
+	{"appendix", "APPENDIX"},
+}
+
+// biocSectionType derives the section_type infon from the sec-type attribute,
+// falling back to the title. It returns "" when neither is recognized.
+func biocSectionType(secType, title string) string {
+	for _, candidate := range []string{secType, title} {
+		candidate = strings.ToLower(candidate)
+		if candidate == "" {
+			continue
+		}
+		for _, k := range biocSectionKeywords {
+			if strings.Contains(candidate, k.keyword) {
+				return k.sectionType
+			}
+		}
+	}
+	return ""
+}
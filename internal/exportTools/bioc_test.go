@@ -0,0 +1,103 @@
+package exportTools_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ashahide/pubparse/internal/exportTools"
+)
+
+//
+// ------------------------ Test: NewBioCCollection ------------------------
+//
+
+// TestNewBioCCollection checks passage types, section types and offsets.
+func TestNewBioCCollection(t *testing.T) {
+	collection, err := exportTools.NewBioCCollection(textArticle, time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("NewBioCCollection failed: %v", err)
+	}
+	if collection.Source != "PMC" || collection.Date != "20200501" || len(collection.Documents) != 1 {
+		t.Fatalf("unexpected collection %+v", collection)
+	}
+
+	want := []struct {
+		sectionType string // Expected section_type infon
+		typ         string // Expected type infon
+		text        string // Expected passage text
+	}{
+		{"TITLE", "front", "Testing software"},
+		{"ABSTRACT", "abstract", "We tested things [1]."},
+		{"METHODS", "title_1", "Methods"},
+		{"METHODS", "paragraph", "As before (Smith et al., 2019; Lee 2020a), we did stuff [2, 3]."},
+		{"FIG", "fig_caption", "Figure 1 Study design."},
+		{"METHODS", "title_2", "Statistics"},
+		{"METHODS", "paragraph", "We used R."},
+		{"REF", "ref", "Prior work. J Tests. 2019."},
+	}
+	passages := collection.Documents[0].Passages
+	if len(passages) != len(want) {
+		t.Fatalf("expected %d passages, got %+v", len(want), passages)
+	}
+	offset := 0
+	for i, p := range passages {
+		if p.Infons["section_type"] != want[i].sectionType || p.Infons["type"] != want[i].typ || p.Text != want[i].text {
+			t.Errorf("passage %d: expected %+v, got %+v", i, want[i], p)
+		}
+		if p.Offset != offset {
+			t.Errorf("passage %d: expected offset %d, got %d", i, offset, p.Offset)
+		}
+		offset += utf8.RuneCountInString(p.Text) + 1
+	}
+}
+
+//
+// ------------------------ Test: EncodeBioC ------------------------
+//
+
+// TestEncodeBioC checks that both encodings are well-formed and carry infons.
+func TestEncodeBioC(t *testing.T) {
+	collection, err := exportTools.NewBioCCollection(textArticle, time.Now())
+	if err != nil {
+		t.Fatalf("NewBioCCollection failed: %v", err)
+	}
+
+	t.Run("bioc-xml", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := exportTools.EncodeBioC(&buf, collection, "bioc-xml"); err != nil {
+			t.Fatalf("EncodeBioC failed: %v", err)
+		}
+		if !strings.Contains(buf.String(), `<infon key="section_type">METHODS</infon>`) {
+			t.Errorf("expected section_type infon, got:\n%s", buf.String())
+		}
+		var parsed struct {
+			Documents []struct {
+				Passages []struct {
+					Offset int `xml:"offset"`
+				} `xml:"passage"`
+			} `xml:"document"`
+		}
+		if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil || len(parsed.Documents) != 1 {
+			t.Errorf("expected one well-formed document, got %+v (%v)", parsed, err)
+		}
+	})
+
+	t.Run("bioc-json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := exportTools.EncodeBioC(&buf, collection, "bioc-json"); err != nil {
+			t.Fatalf("EncodeBioC failed: %v", err)
+		}
+		var parsed map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &parsed); err != nil {
+			t.Fatalf("invalid BioC JSON: %v", err)
+		}
+		if !strings.Contains(buf.String(), `"section_type":"METHODS"`) || !strings.Contains(buf.String(), `"annotations":[]`) {
+			t.Errorf("expected infons and empty annotation lists, got:\n%s", buf.String())
+		}
+	})
+}
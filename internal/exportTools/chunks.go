@@ -0,0 +1,267 @@
+package exportTools
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CharsPerToken approximates the tokenizer of common embedding models for
+// --chunk-unit tokens: a chunk of N tokens holds at most N*CharsPerToken characters.
+const CharsPerToken = 4
+
+// ChunkOptions bounds the size of each chunk.
+type ChunkOptions struct {
+	Size int    // Maximum chunk size in Unit
+	Unit string // "chars" (default) or "tokens"
+}
+
+// MaxChars returns the chunk size in characters.
+func (o ChunkOptions) MaxChars() int {
+	if o.Unit == "tokens" {
+		return o.Size * CharsPerToken
+	}
+	return o.Size
+}
+
+// Chunk is one line of --format chunks output.
+type Chunk struct {
+	Source      string `json:"source"`
+	PMID        string `json:"pmid,omitempty"`
+	PMCID       string `json:"pmcid,omitempty"`
+	SectionPath string `json:"section_path"`
+	ChunkIndex  int    `json:"chunk_index"`
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Text        string `json:"text"`
+}
+
+//
+// ------------------------ WriteChunks ------------------------
+//
+
+/*
+WriteChunks splits every article of a parsed input into chunks and writes them as JSON Lines.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle,
+    already normalized.
+  - fileName: Path of the output file.
+  - opts: Chunk size bound.
+
+Returns:
+  - An error if flattening or writing fails.
+*/
+func WriteChunks(data interface{}, fileName string, opts ChunkOptions) error {
+	records, err := FlattenArticles(data)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("failed to create chunk file %q: %w", fileName, err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, r := range records {
+		if err := EncodeChunks(w, ChunkArticle(r, opts.MaxChars())); err != nil {
+			return fmt.Errorf("failed to write chunk file %q: %w", fileName, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write chunk file %q: %w", fileName, err)
+	}
+	return f.Close()
+}
+
+// EncodeChunks writes one compact JSON object per chunk.
+func EncodeChunks(w io.Writer, chunks []Chunk) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, c := range chunks {
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//
+// ------------------------ ChunkArticle ------------------------
+//
+
+/*
+ChunkArticle splits an article's abstract and body sections into chunks.
+
+Parameters:
+  - r: The flattened article.
+  - maxChars: Maximum chunk length in characters (runes).
+
+Behavior:
+  - The article text is the abstract followed by every body section in document
+    order (subsections after their parent), each section's paragraphs joined by a
+    blank line and sections separated by a blank line. Start and End are rune offsets
+    into that text, so Text == text[Start:End].
+  - Chunks never cross a section boundary and never cut through a sentence; a
+    single sentence longer than maxChars becomes a chunk of its own.
+  - Sections without paragraphs (e.g. a heading with only subsections) produce no chunks.
+  - ChunkIndex counts chunks across the whole article, starting at 0.
+
+Returns:
+  - The chunks in document order.
+*/
+func ChunkArticle(r ArticleRecord, maxChars int) []Chunk {
+	type section struct{ path, text string }
+	var sections []section
+	if r.Abstract != "" {
+		sections = append(sections, section{"Abstract", r.Abstract})
+	}
+	for _, s := range r.Sections {
+		if text := strings.Join(s.Paragraphs, "\n\n"); text != "" {
+			sections = append(sections, section{s.Path, text})
+		}
+	}
+
+	var chunks []Chunk
+	offset := 0
+	for _, s := range sections {
+		runes := []rune(s.text)
+		for _, span := range packSentences(SplitSentences(s.text), maxChars) {
+			chunks = append(chunks, Chunk{
+				Source:      r.Source,
+				PMID:        r.PMID,
+				PMCID:       r.PMCID,
+				SectionPath: s.path,
+				ChunkIndex:  len(chunks),
+				Start:       offset + span[0],
+				End:         offset + span[1],
+				Text:        string(runes[span[0]:span[1]]),
+			})
+		}
+		offset += len(runes) + len("\n\n")
+	}
+	return chunks
+}
+
+// packSentences greedily groups consecutive sentence spans into chunks of at
+// most maxChars runes, measured from the first sentence's start to the last one's end.
+func packSentences(sentences [][2]int, maxChars int) [][2]int {
+	var chunks [][2]int
+	for _, s := range sentences {
+		if n := len(chunks); n > 0 && s[1]-chunks[n-1][0] <= maxChars {
+			chunks[n-1][1] = s[1]
+			continue
+		}
+		chunks = append(chunks, s)
+	}
+	return chunks
+}
+
+//
+// ------------------------ SplitSentences ------------------------
+//
+
+// sentenceAbbrevs are words that end in a period without ending the sentence.
+var sentenceAbbrevs = map[string]bool{
+	"al": true, "e.g": true, "i.e": true, "cf": true, "vs": true, "etc": true,
+	"fig": true, "figs": true, "eq": true, "eqs": true, "ref": true, "refs": true,
+	"tab": true, "no": true, "nos": true, "vol": true, "pp": true, "approx": true,
+	"ca": true, "dr": true, "prof": true, "mr": true, "mrs": true, "ms": true,
+	"st": true, "jr": true, "sr": true, "inc": true, "ltd": true, "co": true,
+	"sp": true, "spp": true, "var": true, "subsp": true, "resp": true,
+}
+
+/*
+SplitSentences returns the rune spans [start, end) of the sentences in text.
+
+Behavior:
+  - A sentence ends at ".", "!" or "?" (plus any closing quotes or brackets) that is
+    followed by whitespace and then an upper-case letter, digit or opening bracket,
+    or at a paragraph break.
+  - Periods after common abbreviations ("et al.", "e.g.", "Fig.", "Dr.") and single
+    initials ("J. Smith") do not end a sentence.
+  - Spans exclude surrounding whitespace.
+*/
+func SplitSentences(text string) [][2]int {
+	runes := []rune(text)
+	var spans [][2]int
+	start := -1
+
+	for i := 0; i < len(runes); i++ {
+		if start < 0 {
+			if unicode.IsSpace(runes[i]) {
+				continue
+			}
+			start = i
+		}
+
+		end := -1
+		switch {
+		case runes[i] == '\n' && i+1 < len(runes) && runes[i+1] == '\n':
+			end = i
+		case runes[i] == '.' || runes[i] == '!' || runes[i] == '?':
+			j := i + 1
+			for j < len(runes) && strings.ContainsRune(`"')]’”`, runes[j]) {
+				j++
+			}
+			if j == len(runes) || (unicode.IsSpace(runes[j]) && startsSentence(runes, j) && !abbreviationAt(runes, start, i)) {
+				end = j
+				i = j - 1
+			}
+		}
+		if end < 0 {
+			continue
+		}
+
+		for end > start && unicode.IsSpace(runes[end-1]) {
+			end--
+		}
+		if end > start {
+			spans = append(spans, [2]int{start, end})
+		}
+		start = -1
+	}
+
+	if start >= 0 {
+		end := len(runes)
+		for end > start && unicode.IsSpace(runes[end-1]) {
+			end--
+		}
+		spans = append(spans, [2]int{start, end})
+	}
+	return spans
+}
+
+// startsSentence reports whether the first non-space rune at or after i could begin a sentence.
+func startsSentence(runes []rune, i int) bool {
+	for ; i < len(runes); i++ {
+		if r := runes[i]; !unicode.IsSpace(r) {
+			return unicode.IsUpper(r) || unicode.IsDigit(r) || strings.ContainsRune(`"'([“‘`, r)
+		}
+	}
+	return true
+}
+
+// abbreviationAt reports whether the period at runes[dot] ends an abbreviation or
+// an initial rather than the sentence that began at start.
+func abbreviationAt(runes []rune, start, dot int) bool {
+	if runes[dot] != '.' {
+		return false
+	}
+	wordStart := dot
+	for wordStart > start && !unicode.IsSpace(runes[wordStart-1]) && runes[wordStart-1] != '(' {
+		wordStart--
+	}
+	word := string(runes[wordStart:dot])
+	if utf8.RuneCountInString(word) == 1 && unicode.IsUpper(runes[wordStart]) {
+		return true
+	}
+	return sentenceAbbrevs[strings.ToLower(word)]
+}
@@ -0,0 +1,88 @@
+package exportTools_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/exportTools"
+)
+
+//
+// ------------------------ Test: SplitSentences ------------------------
+//
+
+// TestSplitSentences checks sentence ends, abbreviations and paragraph breaks.
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name string   // Descriptive name for subtest
+		text string   // Input text
+		want []string // Expected sentences
+	}{
+		{"simple", "One. Two! Three?", []string{"One.", "Two!", "Three?"}},
+		{"abbreviations", "As Smith et al. showed (see Fig. 2), it works. Done.",
+			[]string{"As Smith et al. showed (see Fig. 2), it works.", "Done."}},
+		{"decimal and lowercase", "The value was 2.5 mg. then dropped. Next one.",
+			[]string{"The value was 2.5 mg. then dropped.", "Next one."}},
+		{"paragraph break", "no period here\n\nsecond paragraph.", []string{"no period here", "second paragraph."}},
+		{"closing quote", `He said "stop." Then left.`, []string{`He said "stop."`, "Then left."}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			runes := []rune(test.text)
+			var got []string
+			for _, span := range exportTools.SplitSentences(test.text) {
+				got = append(got, string(runes[span[0]:span[1]]))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+//
+// ------------------------ Test: ChunkArticle ------------------------
+//
+
+// TestChunkArticle checks that chunks respect the size bound, section boundaries
+// and report offsets into the article text.
+func TestChunkArticle(t *testing.T) {
+	record := exportTools.ArticleRecord{
+		Source:   "pmc",
+		PMCID:    "PMC1",
+		Abstract: "Short abstract.",
+		Sections: []exportTools.SectionRecord{
+			{Path: "Methods"},
+			{Path: "Methods > Statistics", Paragraphs: []string{"First sentence here. Second one.", "Third sentence is long enough."}},
+		},
+	}
+	text := "Short abstract.\n\nFirst sentence here. Second one.\n\nThird sentence is long enough."
+
+	chunks := exportTools.ChunkArticle(record, 35)
+	want := []struct {
+		path string
+		text string
+	}{
+		{"Abstract", "Short abstract."},
+		{"Methods > Statistics", "First sentence here. Second one."},
+		{"Methods > Statistics", "Third sentence is long enough."},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("expected %d chunks, got %+v", len(want), chunks)
+	}
+	runes := []rune(text)
+	for i, c := range chunks {
+		if c.ChunkIndex != i || c.SectionPath != want[i].path || c.Text != want[i].text {
+			t.Errorf("chunk %d: expected %q in %q, got %+v", i, want[i].text, want[i].path, c)
+		}
+		if got := string(runes[c.Start:c.End]); got != c.Text {
+			t.Errorf("chunk %d: offsets [%d:%d] give %q, expected %q", i, c.Start, c.End, got, c.Text)
+		}
+	}
+
+	// A sentence longer than the bound is kept whole.
+	if chunks := exportTools.ChunkArticle(record, 5); len(chunks) != 4 || chunks[1].Text != "First sentence here." {
+		t.Errorf("expected one chunk per sentence, got %+v", chunks)
+	}
+}
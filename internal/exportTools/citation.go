@@ -0,0 +1,464 @@
+package exportTools
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CitationFormats lists the reference-manager formats accepted by --format and
+// the cite subcommand.
+var CitationFormats = []string{"bibtex", "ris", "csl-json"}
+
+// IsCitationFormat reports whether format is one of CitationFormats.
+func IsCitationFormat(format string) bool {
+	for _, f := range CitationFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+//
+// ------------------------ WriteCitations ------------------------
+//
+
+/*
+WriteCitations flattens a parsed input and writes one citation per article.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle,
+    already normalized.
+  - fileName: Path of the output file.
+  - format: "bibtex", "ris" or "csl-json".
+
+Returns:
+  - An error if flattening or writing fails.
+*/
+func WriteCitations(data interface{}, fileName, format string) error {
+	records, err := FlattenArticles(data)
+	if err != nil {
+		return err
+	}
+	return WriteCitationRecords(records, fileName, format)
+}
+
+// WriteCitationRecords writes already flattened records to a citation file.
+func WriteCitationRecords(records []ArticleRecord, fileName, format string) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("failed to create citation file %q: %w", fileName, err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := EncodeCitations(w, records, format); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write citation file %q: %w", fileName, err)
+	}
+	return f.Close()
+}
+
+/*
+EncodeCitations writes records to w in a citation format.
+
+Parameters:
+  - w: Destination writer.
+  - records: Flattened articles, written in order.
+  - format: "bibtex", "ris" or "csl-json".
+
+Returns:
+  - An error for unknown formats or failed writes.
+*/
+func EncodeCitations(w io.Writer, records []ArticleRecord, format string) error {
+	switch format {
+	case "bibtex":
+		for _, r := range records {
+			if _, err := io.WriteString(w, bibtexEntry(r)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "ris":
+		for _, r := range records {
+			if _, err := io.WriteString(w, risEntry(r)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "csl-json":
+		items := make([]cslItem, 0, len(records))
+		for _, r := range records {
+			items = append(items, cslEntry(r))
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(items)
+
+	default:
+		return fmt.Errorf("unknown citation format: %s (expected %s)", format, strings.Join(CitationFormats, ", "))
+	}
+}
+
+//
+// ------------------------ CitationKey ------------------------
+//
+
+/*
+CitationKey returns a deterministic key for an article, used as the BibTeX key
+and the CSL-JSON id.
+
+Format:
+  - <first author surname><year>_<PMID>, e.g. "smith2020_32387127".
+  - The surname is lower-cased and folded to ASCII letters; collective authors use the
+    first word of the group name and articles without authors use "anon".
+  - The PMCID replaces a missing PMID; without either, the first 8 hex digits of the
+    SHA-1 of the DOI or title are used.
+*/
+func CitationKey(r ArticleRecord) string {
+	name := "anon"
+	if len(r.Authors) > 0 {
+		a := r.Authors[0]
+		source := a.LastName
+		if source == "" {
+			if words := strings.Fields(a.CollectiveName); len(words) > 0 {
+				source = words[0]
+			}
+		}
+		if folded := foldASCII(source); folded != "" {
+			name = folded
+		}
+	}
+
+	id := r.PMID
+	if id == "" {
+		id = strings.ToLower(r.PMCID)
+	}
+	if id == "" {
+		sum := sha1.Sum([]byte(r.DOI + r.Title))
+		id = hex.EncodeToString(sum[:])[:8]
+	}
+	return name + r.Year + "_" + id
+}
+
+// asciiFolds maps common accented Latin letters to ASCII.
+var asciiFolds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'č': "c", 'ć': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'œ': "oe",
+	'ř': "r", 'š': "s", 'ś': "s", 'ß': "ss",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ů': "u",
+	'ý': "y", 'ÿ': "y", 'ž': "z", 'ź': "z", 'ż': "z",
+}
+
+// foldASCII lower-cases s and keeps only ASCII letters, folding accented ones.
+func foldASCII(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z':
+			b.WriteRune(r)
+		case asciiFolds[r] != "":
+			b.WriteString(asciiFolds[r])
+		}
+	}
+	return b.String()
+}
+
+//
+// ------------------------ entry types ------------------------
+//
+
+// citationKind classifies a record as "article", "book", "chapter" or "preprint".
+func citationKind(r ArticleRecord) string {
+	if r.Source == "pubmed-book" {
+		if r.Title != r.Journal {
+			return "chapter"
+		}
+		return "book"
+	}
+	for _, pt := range r.PublicationTypes {
+		if strings.EqualFold(pt, "Preprint") {
+			return "preprint"
+		}
+	}
+	if r.ArticleType == "preprint" {
+		return "preprint"
+	}
+	return "article"
+}
+
+// Entry types per citation kind.
+var (
+	bibtexTypes = map[string]string{"article": "article", "book": "book", "chapter": "incollection", "preprint": "misc"}
+	risTypes    = map[string]string{"article": "JOUR", "book": "BOOK", "chapter": "CHAP", "preprint": "GEN"}
+	cslTypes    = map[string]string{"article": "article-journal", "book": "book", "chapter": "chapter", "preprint": "article"}
+)
+
+// citationTitle drops the trailing period PubMed appends to titles.
+func citationTitle(title string) string {
+	return strings.TrimSuffix(title, ".")
+}
+
+// pageRange splits pages into first and last page, expanding MEDLINE's
+// abbreviated ranges ("100-10" → "100", "110").
+func pageRange(pages string) (string, string) {
+	first, last, found := strings.Cut(pages, "-")
+	first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+	if !found {
+		return first, ""
+	}
+	if _, err := strconv.Atoi(first); err == nil && len(last) < len(first) {
+		if _, err := strconv.Atoi(last); err == nil {
+			last = first[:len(first)-len(last)] + last
+		}
+	}
+	return first, last
+}
+
+//
+// ------------------------ BibTeX ------------------------
+//
+
+// bibtexEscaper escapes characters with a special meaning in BibTeX field values.
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`, "{", `\{`, "}", `\}`, "&", `\&`, "%", `\%`,
+	"$", `\$`, "#", `\#`, "_", `\_`, "~", `\textasciitilde{}`, "^", `\textasciicircum{}`,
+)
+
+// bibtexMonths are the standard BibTeX month macros.
+var bibtexMonths = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+// bibtexEntry renders one record as a BibTeX entry.
+func bibtexEntry(r ArticleRecord) string {
+	kind := citationKind(r)
+	var b strings.Builder
+	fmt.Fprintf(&b, "@%s{%s,\n", bibtexTypes[kind], CitationKey(r))
+
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "  %s = {%s},\n", name, value)
+		}
+	}
+
+	var authors []string
+	for _, a := range r.Authors {
+		switch {
+		case a.CollectiveName != "":
+			authors = append(authors, "{"+bibtexEscaper.Replace(a.CollectiveName)+"}")
+		case a.LastName != "":
+			given := a.ForeName
+			if given == "" {
+				given = a.Initials
+			}
+			name := bibtexEscaper.Replace(a.LastName)
+			if given != "" {
+				name += ", " + bibtexEscaper.Replace(given)
+			}
+			authors = append(authors, name)
+		}
+	}
+	field("author", strings.Join(authors, " and "))
+	if title := citationTitle(r.Title); title != "" {
+		field("title", "{"+bibtexEscaper.Replace(title)+"}")
+	}
+	switch kind {
+	case "chapter":
+		field("booktitle", bibtexEscaper.Replace(r.Journal))
+	case "article", "preprint":
+		field("journal", bibtexEscaper.Replace(r.Journal))
+	}
+	field("publisher", bibtexEscaper.Replace(r.Publisher))
+	field("year", r.Year)
+	if m, err := strconv.Atoi(r.Month); err == nil {
+		fmt.Fprintf(&b, "  month = %s,\n", bibtexMonths[m-1])
+	}
+	field("volume", bibtexEscaper.Replace(r.Volume))
+	field("number", bibtexEscaper.Replace(r.Issue))
+	if first, last := pageRange(r.Pages); last != "" {
+		field("pages", bibtexEscaper.Replace(first)+"--"+bibtexEscaper.Replace(last))
+	} else {
+		field("pages", bibtexEscaper.Replace(first))
+	}
+	field("issn", r.ISSN)
+	field("doi", r.DOI)
+	field("pmid", r.PMID)
+	field("pmcid", r.PMCID)
+	if len(r.Keywords) > 0 {
+		field("keywords", bibtexEscaper.Replace(strings.Join(r.Keywords, ", ")))
+	}
+	b.WriteString("}\n\n")
+	return b.String()
+}
+
+//
+// ------------------------ RIS ------------------------
+//
+
+// risEntry renders one record as an RIS reference.
+func risEntry(r ArticleRecord) string {
+	kind := citationKind(r)
+	var b strings.Builder
+	tag := func(name, value string) {
+		if value = strings.TrimSpace(strings.ReplaceAll(value, "\n", " ")); value != "" {
+			fmt.Fprintf(&b, "%s  - %s\n", name, value)
+		}
+	}
+
+	tag("TY", risTypes[kind])
+	tag("ID", CitationKey(r))
+	for _, a := range r.Authors {
+		switch {
+		case a.CollectiveName != "":
+			tag("AU", a.CollectiveName)
+		case a.LastName != "":
+			given := a.ForeName
+			if given == "" {
+				given = a.Initials
+			}
+			tag("AU", strings.TrimSuffix(a.LastName+", "+given, ", "))
+		}
+	}
+	tag("TI", citationTitle(r.Title))
+	if kind != "book" {
+		tag("T2", r.Journal)
+	}
+	tag("J2", r.JournalAbbrev)
+	tag("PB", r.Publisher)
+	tag("PY", r.Year)
+	if m, err := strconv.Atoi(r.Month); err == nil && r.Year != "" {
+		tag("DA", fmt.Sprintf("%s/%02d//", r.Year, m))
+	}
+	tag("VL", r.Volume)
+	tag("IS", r.Issue)
+	first, last := pageRange(r.Pages)
+	tag("SP", first)
+	tag("EP", last)
+	tag("SN", r.ISSN)
+	tag("DO", r.DOI)
+	tag("AN", r.PMID)
+	tag("C2", r.PMCID)
+	for _, lang := range r.Languages {
+		tag("LA", lang)
+	}
+	for _, pt := range r.PublicationTypes {
+		tag("M3", pt)
+	}
+	for _, kw := range r.Keywords {
+		tag("KW", kw)
+	}
+	tag("AB", r.Abstract)
+	if r.PMID != "" {
+		tag("UR", "https://pubmed.ncbi.nlm.nih.gov/"+r.PMID+"/")
+	}
+	b.WriteString("ER  - \n\n")
+	return b.String()
+}
+
+//
+// ------------------------ CSL-JSON ------------------------
+//
+
+// cslItem is one CSL-JSON item (https://citeproc-js.readthedocs.io/en/latest/csl-json/markup.html).
+type cslItem struct {
+	ID                  string    `json:"id"`
+	Type                string    `json:"type"`
+	Title               string    `json:"title,omitempty"`
+	ContainerTitle      string    `json:"container-title,omitempty"`
+	ContainerTitleShort string    `json:"container-title-short,omitempty"`
+	Author              []cslName `json:"author,omitempty"`
+	Issued              *cslDate  `json:"issued,omitempty"`
+	Volume              string    `json:"volume,omitempty"`
+	Issue               string    `json:"issue,omitempty"`
+	Page                string    `json:"page,omitempty"`
+	Publisher           string    `json:"publisher,omitempty"`
+	DOI                 string    `json:"DOI,omitempty"`
+	PMID                string    `json:"PMID,omitempty"`
+	PMCID               string    `json:"PMCID,omitempty"`
+	ISSN                string    `json:"ISSN,omitempty"`
+	Language            string    `json:"language,omitempty"`
+	Genre               string    `json:"genre,omitempty"`
+	Keyword             string    `json:"keyword,omitempty"`
+	Abstract            string    `json:"abstract,omitempty"`
+}
+
+// cslName is a personal (family/given) or institutional (literal) name.
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+// cslDate holds [[year]] or [[year, month]].
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// cslEntry converts one record into a CSL-JSON item.
+func cslEntry(r ArticleRecord) cslItem {
+	kind := citationKind(r)
+	item := cslItem{
+		ID:                  CitationKey(r),
+		Type:                cslTypes[kind],
+		Title:               citationTitle(r.Title),
+		ContainerTitle:      r.Journal,
+		ContainerTitleShort: r.JournalAbbrev,
+		Volume:              r.Volume,
+		Issue:               r.Issue,
+		Page:                r.Pages,
+		Publisher:           r.Publisher,
+		DOI:                 r.DOI,
+		PMID:                r.PMID,
+		PMCID:               r.PMCID,
+		ISSN:                r.ISSN,
+		Genre:               strings.Join(r.PublicationTypes, "; "),
+		Keyword:             strings.Join(r.Keywords, ", "),
+		Abstract:            r.Abstract,
+	}
+	if kind == "book" {
+		item.ContainerTitle = ""
+	}
+	if len(r.Languages) > 0 {
+		item.Language = r.Languages[0]
+	}
+	if first, last := pageRange(r.Pages); last != "" {
+		item.Page = first + "-" + last
+	}
+
+	for _, a := range r.Authors {
+		switch {
+		case a.CollectiveName != "":
+			item.Author = append(item.Author, cslName{Literal: a.CollectiveName})
+		case a.LastName != "":
+			given := a.ForeName
+			if given == "" {
+				given = a.Initials
+			}
+			item.Author = append(item.Author, cslName{Family: a.LastName, Given: given})
+		}
+	}
+
+	if year, err := strconv.Atoi(r.Year); err == nil {
+		parts := []int{year}
+		if month, err := strconv.Atoi(r.Month); err == nil {
+			parts = append(parts, month)
+		}
+		item.Issued = &cslDate{DateParts: [][]int{parts}}
+	}
+	return item
+}
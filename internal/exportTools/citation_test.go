@@ -0,0 +1,110 @@
+package exportTools_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/exportTools"
+)
+
+// citationRecord is a journal article with a personal and a collective author.
+var citationRecord = exportTools.ArticleRecord{
+	Source:           "pubmed",
+	PMID:             "32387127",
+	DOI:              "10.1000/xyz123",
+	Title:            "Caffeine & sleep: a 100% survey.",
+	Journal:          "Journal of Tests",
+	Volume:           "12",
+	Issue:            "3",
+	Pages:            "100-10",
+	Year:             "2020",
+	Month:            "5",
+	PublicationTypes: []string{"Journal Article"},
+	Authors: []exportTools.AuthorRecord{
+		{LastName: "Müller", ForeName: "Anna"},
+		{CollectiveName: "Test Consortium"},
+	},
+}
+
+//
+// ------------------------ Test: CitationKey ------------------------
+//
+
+// TestCitationKey checks that keys are deterministic and ASCII-only.
+func TestCitationKey(t *testing.T) {
+	tests := []struct {
+		name   string                    // Descriptive name for subtest
+		record exportTools.ArticleRecord // Record to key
+		want   string                    // Expected key
+	}{
+		{"personal author", citationRecord, "muller2020_32387127"},
+		{"collective author", exportTools.ArticleRecord{PMID: "1", Year: "1999",
+			Authors: []exportTools.AuthorRecord{{CollectiveName: "WHO Working Group"}}}, "who1999_1"},
+		{"pmcid fallback", exportTools.ArticleRecord{PMCID: "PMC42"}, "anon_pmc42"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := exportTools.CitationKey(test.record); got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
+
+//
+// ------------------------ Test: EncodeCitations ------------------------
+//
+
+// TestEncodeCitations checks the author, page, DOI and escaping rules of each format.
+func TestEncodeCitations(t *testing.T) {
+	tests := []struct {
+		format string   // Citation format
+		want   []string // Substrings the output must contain
+	}{
+		{"bibtex", []string{
+			"@article{muller2020_32387127,",
+			"author = {Müller, Anna and {Test Consortium}}",
+			`title = {{Caffeine \& sleep: a 100\% survey}}`,
+			"pages = {100--110}",
+			"month = may,",
+			"doi = {10.1000/xyz123}",
+		}},
+		{"ris", []string{
+			"TY  - JOUR\n",
+			"AU  - Müller, Anna\nAU  - Test Consortium\n",
+			"SP  - 100\nEP  - 110\n",
+			"DA  - 2020/05//\n",
+			"M3  - Journal Article\n",
+			"ER  - \n",
+		}},
+		{"csl-json", []string{
+			`"type": "article-journal"`,
+			`"literal": "Test Consortium"`,
+			`"page": "100-110"`,
+			`"DOI": "10.1000/xyz123"`,
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := exportTools.EncodeCitations(&buf, []exportTools.ArticleRecord{citationRecord}, test.format); err != nil {
+				t.Fatalf("EncodeCitations failed: %v", err)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, buf.String())
+				}
+			}
+			if test.format == "csl-json" {
+				var items []map[string]interface{}
+				if err := json.Unmarshal(buf.Bytes(), &items); err != nil || len(items) != 1 {
+					t.Errorf("expected a one-item CSL-JSON array, got %v (%v)", items, err)
+				}
+			}
+		})
+	}
+}
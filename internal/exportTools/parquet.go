@@ -0,0 +1,168 @@
+package exportTools
+
+import (
+	"fmt"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ParquetArticle is the row layout of --format parquet output: one row per article.
+// Column names are stable; new columns are only ever appended.
+type ParquetArticle struct {
+	Source           string               `parquet:"source,dict"`
+	PMID             string               `parquet:"pmid"`
+	PMCID            string               `parquet:"pmcid"`
+	DOI              string               `parquet:"doi"`
+	Title            string               `parquet:"title"`
+	Abstract         string               `parquet:"abstract"`
+	Journal          string               `parquet:"journal,dict"`
+	JournalAbbrev    string               `parquet:"journal_abbrev,dict"`
+	ISSN             string               `parquet:"issn,dict"`
+	Volume           string               `parquet:"volume"`
+	Issue            string               `parquet:"issue"`
+	Pages            string               `parquet:"pages"`
+	Year             *int32               `parquet:"year,optional"`
+	ArticleType      string               `parquet:"article_type,dict"`
+	Languages        []string             `parquet:"languages,list"`
+	PublicationTypes []string             `parquet:"publication_types,list"`
+	Keywords         []string             `parquet:"keywords,list"`
+	Authors          []ParquetAuthor      `parquet:"authors,list"`
+	MeSH             []ParquetMeshHeading `parquet:"mesh,list"`
+	Chemicals        []ParquetChemical    `parquet:"chemicals,list"`
+	Grants           []ParquetGrant       `parquet:"grants,list"`
+	References       []ParquetReference   `parquet:"references,list"`
+	Sections         []ParquetSection     `parquet:"sections,list"`
+}
+
+// ParquetAuthor is one element of the authors column.
+type ParquetAuthor struct {
+	LastName       string   `parquet:"last_name"`
+	ForeName       string   `parquet:"fore_name"`
+	Initials       string   `parquet:"initials"`
+	CollectiveName string   `parquet:"collective_name"`
+	Affiliations   []string `parquet:"affiliations,list"`
+}
+
+// ParquetMeshHeading is one element of the mesh column.
+type ParquetMeshHeading struct {
+	Descriptor string   `parquet:"descriptor"`
+	Qualifiers []string `parquet:"qualifiers,list"`
+}
+
+// ParquetChemical is one element of the chemicals column.
+type ParquetChemical struct {
+	RegistryNumber string `parquet:"registry_number"`
+	Name           string `parquet:"name"`
+}
+
+// ParquetGrant is one element of the grants column.
+type ParquetGrant struct {
+	GrantID string `parquet:"grant_id"`
+	Acronym string `parquet:"acronym"`
+	Agency  string `parquet:"agency"`
+	Country string `parquet:"country"`
+}
+
+// ParquetReference is one element of the references column.
+type ParquetReference struct {
+	ID       string `parquet:"id"`
+	Citation string `parquet:"citation"`
+	PMID     string `parquet:"pmid"`
+	DOI      string `parquet:"doi"`
+	PMCID    string `parquet:"pmcid"`
+}
+
+// ParquetSection is one element of the sections column (PMC body sections).
+type ParquetSection struct {
+	ID         string   `parquet:"id"`
+	SecType    string   `parquet:"sec_type"`
+	Title      string   `parquet:"title"`
+	Path       string   `parquet:"path"`
+	Depth      int32    `parquet:"depth"`
+	Paragraphs []string `parquet:"paragraphs,list"`
+}
+
+//
+// ------------------------ WriteParquet ------------------------
+//
+
+/*
+WriteParquet flattens a parsed input and writes it as a Zstandard-compressed Parquet file.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle,
+    already normalized.
+  - fileName: Path of the .parquet file to create.
+
+Returns:
+  - An error if flattening or writing fails.
+*/
+func WriteParquet(data interface{}, fileName string) error {
+	records, err := FlattenArticles(data)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]ParquetArticle, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, ToParquetRow(r))
+	}
+
+	if err := parquet.WriteFile(fileName, rows, parquet.Compression(&parquet.Zstd)); err != nil {
+		return fmt.Errorf("failed to write parquet file %q: %w", fileName, err)
+	}
+	return nil
+}
+
+// ToParquetRow converts an ArticleRecord into its Parquet row.
+func ToParquetRow(r ArticleRecord) ParquetArticle {
+	row := ParquetArticle{
+		Source:           r.Source,
+		PMID:             r.PMID,
+		PMCID:            r.PMCID,
+		DOI:              r.DOI,
+		Title:            r.Title,
+		Abstract:         r.Abstract,
+		Journal:          r.Journal,
+		JournalAbbrev:    r.JournalAbbrev,
+		ISSN:             r.ISSN,
+		Volume:           r.Volume,
+		Issue:            r.Issue,
+		Pages:            r.Pages,
+		ArticleType:      r.ArticleType,
+		Languages:        r.Languages,
+		PublicationTypes: r.PublicationTypes,
+		Keywords:         r.Keywords,
+	}
+
+	var year int32
+	if _, err := fmt.Sscanf(r.Year, "%d", &year); err == nil {
+		row.Year = &year
+	}
+	for _, a := range r.Authors {
+		row.Authors = append(row.Authors, ParquetAuthor(a))
+	}
+	for _, m := range r.MeSH {
+		row.MeSH = append(row.MeSH, ParquetMeshHeading(m))
+	}
+	for _, c := range r.Chemicals {
+		row.Chemicals = append(row.Chemicals, ParquetChemical(c))
+	}
+	for _, g := range r.Grants {
+		row.Grants = append(row.Grants, ParquetGrant(g))
+	}
+	for _, ref := range r.References {
+		row.References = append(row.References, ParquetReference(ref))
+	}
+	for _, s := range r.Sections {
+		row.Sections = append(row.Sections, ParquetSection{
+			ID:         s.ID,
+			SecType:    s.SecType,
+			Title:      s.Title,
+			Path:       s.Path,
+			Depth:      int32(s.Depth),
+			Paragraphs: s.Paragraphs,
+		})
+	}
+	return row
+}
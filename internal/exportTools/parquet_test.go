@@ -0,0 +1,101 @@
+package exportTools_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/exportTools"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+	"github.com/parquet-go/parquet-go"
+)
+
+// pubmedSample is a two-article PubMed set exercising the flattened columns.
+const pubmedSample = `<PubmedArticleSet>
+<PubmedArticle>
+  <MedlineCitation>
+    <PMID Version="1">111</PMID>
+    <Article>
+      <Journal>
+        <ISSN>1234-5678</ISSN>
+        <JournalIssue CitedMedium="Internet"><Volume>12</Volume><Issue>3</Issue><PubDate><Year>2021</Year><Month>Mar</Month></PubDate></JournalIssue>
+        <Title>Journal of Tests</Title>
+        <ISOAbbreviation>J Tests</ISOAbbreviation>
+      </Journal>
+      <ArticleTitle>First   article.</ArticleTitle>
+      <Pagination><MedlinePgn>10-20</MedlinePgn></Pagination>
+      <ELocationID EIdType="doi" ValidYN="Y">10.1000/first</ELocationID>
+      <Abstract><AbstractText>Some abstract.</AbstractText></Abstract>
+      <AuthorList>
+        <Author><LastName>Doe</LastName><ForeName>Jane</ForeName><Initials>J</Initials>
+          <AffiliationInfo><Affiliation>Test University</Affiliation></AffiliationInfo></Author>
+      </AuthorList>
+      <Language>eng</Language>
+    </Article>
+    <MeshHeadingList>
+      <MeshHeading><DescriptorName UI="D1">Humans</DescriptorName></MeshHeading>
+    </MeshHeadingList>
+  </MedlineCitation>
+</PubmedArticle>
+<PubmedArticle>
+  <MedlineCitation>
+    <PMID Version="1">222</PMID>
+    <Article>
+      <Journal><JournalIssue><PubDate><MedlineDate>1998 Dec-1999 Jan</MedlineDate></PubDate></JournalIssue></Journal>
+      <ArticleTitle>Second article.</ArticleTitle>
+    </Article>
+  </MedlineCitation>
+</PubmedArticle>
+</PubmedArticleSet>`
+
+//
+// ------------------------ Test: WriteParquet ------------------------
+//
+
+// TestWriteParquet_RoundTrip writes a PubMed set to Parquet and reads the rows back.
+func TestWriteParquet_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "set.xml")
+	if err := os.WriteFile(input, []byte(pubmedSample), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	data, err := xmlTools.ParsePubmedXML(input)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	output := filepath.Join(dir, "set.parquet")
+	if err := exportTools.WriteParquet(data, output); err != nil {
+		t.Fatalf("WriteParquet failed: %v", err)
+	}
+
+	rows, err := parquet.ReadFile[exportTools.ParquetArticle](output)
+	if err != nil {
+		t.Fatalf("failed to read parquet file: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+
+	first := rows[0]
+	if first.PMID != "111" || first.Title != "First article." || first.DOI != "10.1000/first" {
+		t.Errorf("unexpected identifiers: %+v", first)
+	}
+	if first.Volume != "12" || first.Issue != "3" || first.Pages != "10-20" || first.JournalAbbrev != "J Tests" {
+		t.Errorf("unexpected journal fields: %+v", first)
+	}
+	if first.Year == nil || *first.Year != 2021 {
+		t.Errorf("expected year 2021, got %v", first.Year)
+	}
+	if len(first.Authors) != 1 || first.Authors[0].LastName != "Doe" || len(first.Authors[0].Affiliations) != 1 {
+		t.Errorf("unexpected authors: %+v", first.Authors)
+	}
+	if len(first.MeSH) != 1 || first.MeSH[0].Descriptor != "Humans" {
+		t.Errorf("unexpected MeSH headings: %+v", first.MeSH)
+	}
+
+	if second := rows[1]; second.Year == nil || *second.Year != 1998 {
+		t.Errorf("expected year 1998 from MedlineDate, got %v", second.Year)
+	}
+}
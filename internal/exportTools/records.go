@@ -0,0 +1,600 @@
+package exportTools
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+// ArticleRecord is a flattened, format-neutral view of one PubMed or PMC article.
+// The tabular and text exporters in this package are all built from it.
+type ArticleRecord struct {
+	Source           string // "pubmed", "pubmed-book" or "pmc"
+	PMID             string
+	PMCID            string
+	DOI              string
+	Title            string
+	Abstract         string
+	Journal          string
+	JournalAbbrev    string
+	ISSN             string
+	Volume           string
+	Issue            string
+	Pages            string
+	Year             string // Four-digit publication year, "" if unknown
+	Month            string // Publication month as "1"–"12", "" if unknown
+	Publisher        string
+	ArticleType      string // PMC article-type attribute
+	Version          int    // Citation version (PubMed VersionID), 1 when absent
+	Revised          string // Date revised as YYYY-MM-DD, "" if unknown
+	Languages        []string
+	PublicationTypes []string
+	Keywords         []string
+	Authors          []AuthorRecord
+	MeSH             []MeshRecord
+	Chemicals        []ChemicalRecord
+	Grants           []GrantRecord
+	References       []ReferenceRecord
+	Sections         []SectionRecord // PMC body sections, depth-first
+}
+
+// AuthorRecord is one author, in list order. CollectiveName is set for group authors.
+type AuthorRecord struct {
+	LastName       string
+	ForeName       string
+	Initials       string
+	CollectiveName string
+	Affiliations   []string
+}
+
+// MeshRecord is one MeSH heading with its qualifiers.
+type MeshRecord struct {
+	Descriptor string
+	Qualifiers []string
+}
+
+// ChemicalRecord is one entry of the PubMed chemical list.
+type ChemicalRecord struct {
+	RegistryNumber string
+	Name           string
+}
+
+// GrantRecord is one funding entry.
+type GrantRecord struct {
+	GrantID string
+	Acronym string
+	Agency  string
+	Country string
+}
+
+// ReferenceRecord is one cited work.
+type ReferenceRecord struct {
+	ID       string // PMC ref id attribute, e.g. "r1"
+	Citation string // Citation text (PubMed) or a rendered element/mixed citation (PMC)
+	PMID     string
+	DOI      string
+	PMCID    string
+}
+
+// SectionRecord is one body section. Path joins the titles of all enclosing
+// sections, e.g. "Methods > Statistical analysis".
+type SectionRecord struct {
+	ID         string
+	SecType    string
+	Title      string
+	Path       string
+	Depth      int
+	Paragraphs []string
+}
+
+// ArticleSink collects the articles of all inputs into shared output, such as the
+// relational TSV tables or a SQLite database. Implementations are safe for
+// concurrent use by the worker pool.
+type ArticleSink interface {
+	WriteArticles(data interface{}) error
+	Close() error
+}
+
+var yearPattern = regexp.MustCompile(`\b(1[89]\d{2}|20\d{2})\b`)
+
+//
+// ------------------------ FlattenArticles ------------------------
+//
+
+/*
+FlattenArticles converts a parsed input into one ArticleRecord per article.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle.
+
+Returns:
+  - The records in input order.
+  - An error for unsupported types.
+*/
+func FlattenArticles(data interface{}) ([]ArticleRecord, error) {
+	switch v := data.(type) {
+	case *xmlTools.PubmedArticleSet:
+		records := make([]ArticleRecord, 0, len(v.PubmedArticles))
+		for i := range v.PubmedArticles {
+			records = append(records, FlattenPubmedArticle(&v.PubmedArticles[i]))
+		}
+		return records, nil
+
+	case *xmlTools.PubmedBookArticleSet:
+		records := make([]ArticleRecord, 0, len(v.PubmedBookArticles))
+		for i := range v.PubmedBookArticles {
+			records = append(records, FlattenPubmedBookArticle(&v.PubmedBookArticles[i]))
+		}
+		return records, nil
+
+	case *xmlTools.PMCArticle:
+		return []ArticleRecord{FlattenPMCArticle(v)}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported data type for export: %T", data)
+	}
+}
+
+// FlattenPubmedArticle converts one PubmedArticle into an ArticleRecord.
+func FlattenPubmedArticle(a *xmlTools.PubmedArticle) ArticleRecord {
+	citation := a.MedlineCitation
+	article := citation.Article
+	issue := article.Journal.JournalIssue
+
+	r := ArticleRecord{
+		Source:        "pubmed",
+		PMID:          strings.TrimSpace(citation.PMID),
+		Title:         cleanText(article.ArticleTitle),
+		Abstract:      abstractText(article.Abstract),
+		Journal:       cleanText(article.Journal.Title),
+		JournalAbbrev: cleanText(article.Journal.ISOAbbreviation),
+		ISSN:          strings.TrimSpace(article.Journal.ISSN),
+		Volume:        strings.TrimSpace(issue.Volume),
+		Issue:         strings.TrimSpace(issue.Issue),
+		Pages:         pubmedPages(article.Pagination),
+		Year:          firstYear(issue.PubDate.Year, issue.PubDate.MedlineDate),
+		Month:         monthNumber(issue.PubDate.Month, issue.PubDate.MedlineDate),
+		Languages:     nonEmpty(article.Language),
+		Keywords:      nonEmpty(citation.KeywordList),
+		Version:       xmlTools.CitationVersion(citation.VersionID),
+		Revised:       isoDate(citation.DateRevised),
+	}
+
+	for _, id := range a.PubmedData.ArticleIdList.ArticleIds {
+		r.setArticleID(id.IdType, id.ID)
+	}
+	for _, loc := range article.ELocationIDs {
+		if loc.EIdType == "doi" && r.DOI == "" {
+			r.DOI = strings.TrimSpace(loc.ID)
+		}
+	}
+	if r.Year == "" {
+		for _, h := range a.PubmedData.History {
+			if h.PubStatus == "pubmed" {
+				r.Year = firstYear(h.Year)
+			}
+		}
+	}
+
+	for _, pt := range article.PublicationTypeList {
+		r.PublicationTypes = append(r.PublicationTypes, cleanText(pt.Text))
+	}
+	for _, au := range article.AuthorList {
+		author := AuthorRecord{
+			LastName:       cleanText(au.LastName),
+			ForeName:       cleanText(au.ForeName),
+			Initials:       cleanText(au.Initials),
+			CollectiveName: cleanText(au.CollectiveName),
+		}
+		for _, aff := range au.AffiliationInfo {
+			if text := cleanText(aff.Affiliation); text != "" {
+				author.Affiliations = append(author.Affiliations, text)
+			}
+		}
+		r.Authors = append(r.Authors, author)
+	}
+	for _, mh := range citation.MeshHeadingList.MeshHeadings {
+		mesh := MeshRecord{Descriptor: cleanText(mh.DescriptorName)}
+		for _, q := range mh.Qualifiers {
+			mesh.Qualifiers = append(mesh.Qualifiers, cleanText(q.Text))
+		}
+		r.MeSH = append(r.MeSH, mesh)
+	}
+	for _, c := range citation.ChemicalList {
+		r.Chemicals = append(r.Chemicals, ChemicalRecord{
+			RegistryNumber: strings.TrimSpace(c.RegistryNumber),
+			Name:           cleanText(c.NameOfSubstance),
+		})
+	}
+	for _, g := range article.GrantList.Grants {
+		r.Grants = append(r.Grants, GrantRecord{GrantID: g.GrantID, Acronym: g.Acronym, Agency: g.Agency, Country: g.Country})
+	}
+	for _, ref := range a.PubmedData.ReferenceList {
+		r.References = append(r.References, pubmedReference(ref))
+	}
+	return r
+}
+
+// FlattenPubmedBookArticle converts one PubmedBookArticle into an ArticleRecord.
+func FlattenPubmedBookArticle(a *xmlTools.PubmedBookArticle) ArticleRecord {
+	doc := a.BookDocument
+
+	r := ArticleRecord{
+		Source:    "pubmed-book",
+		PMID:      strings.TrimSpace(doc.PMID),
+		Title:     cleanText(doc.ArticleTitle),
+		Abstract:  abstractText(doc.Abstract),
+		Journal:   cleanText(doc.Book.BookTitle),
+		Year:      firstYear(doc.Book.PubDate.Year, doc.Book.BeginningDate.Year),
+		Month:     monthNumber(doc.Book.PubDate.Month),
+		Publisher: cleanText(doc.Book.Publisher.PublisherName),
+		Version:   1,
+	}
+	if r.Title == "" {
+		r.Title = r.Journal
+	}
+	for _, id := range doc.ArticleIdList.ArticleIds {
+		r.setArticleID(id.IdType, id.ID)
+	}
+	for _, id := range a.PubmedBookData.ArticleIdList.ArticleIds {
+		r.setArticleID(id.IdType, id.ID)
+	}
+	if pt := cleanText(doc.PublicationType); pt != "" {
+		r.PublicationTypes = []string{pt}
+	}
+	for _, kw := range doc.KeywordList {
+		if text := cleanText(kw.Text); text != "" {
+			r.Keywords = append(r.Keywords, text)
+		}
+	}
+	for _, g := range doc.GrantList.Grants {
+		r.Grants = append(r.Grants, GrantRecord{GrantID: g.GrantID, Acronym: g.Acronym, Agency: g.Agency, Country: g.Country})
+	}
+	for _, ref := range doc.ReferenceList {
+		r.References = append(r.References, pubmedReference(ref))
+	}
+	return r
+}
+
+// FlattenPMCArticle converts one PMCArticle into an ArticleRecord.
+func FlattenPMCArticle(a *xmlTools.PMCArticle) ArticleRecord {
+	meta := a.Front.ArticleMeta
+	journal := a.Front.JournalMeta
+
+	r := ArticleRecord{
+		Source:      "pmc",
+		Title:       cleanText(meta.TitleGroup.ArticleTitle),
+		Journal:     cleanText(journal.JournalTitle),
+		Volume:      strings.TrimSpace(meta.Volume),
+		Issue:       strings.TrimSpace(meta.Issue),
+		Pages:       joinPages(meta.FPage, meta.LPage),
+		ArticleType: a.ArticleType,
+		Publisher:   cleanText(journal.Publisher.PublisherName),
+		Version:     1,
+	}
+
+	for _, id := range meta.ArticleID {
+		r.setArticleID(id.IDType, id.Value)
+	}
+	for _, id := range journal.JournalID {
+		if id.IDType == "iso-abbrev" || (id.IDType == "nlm-ta" && r.JournalAbbrev == "") {
+			r.JournalAbbrev = cleanText(id.Value)
+		}
+	}
+	if len(journal.ISSN) > 0 {
+		r.ISSN = strings.TrimSpace(journal.ISSN[0].Value)
+	}
+	for _, d := range meta.PubDate {
+		if r.Year == "" {
+			r.Year = firstYear(d.Year)
+			r.Month = monthNumber(d.Month)
+		}
+	}
+
+	if meta.Abstract != nil {
+		var parts []string
+		parts = append(parts, meta.Abstract.Paragraphs...)
+		for _, sec := range meta.Abstract.Sec {
+			parts = append(parts, sec.Paragraphs...)
+		}
+		r.Abstract = joinParagraphs(parts)
+	}
+
+	affByID := map[string]string{}
+	for _, aff := range meta.AffList {
+		affByID[aff.ID] = cleanText(aff.Text)
+	}
+	for _, group := range meta.ContribGroup {
+		for _, c := range group.Contrib {
+			if c.ContribType != "" && c.ContribType != "author" {
+				continue
+			}
+			author := AuthorRecord{
+				LastName: cleanText(c.Name.Surname),
+				ForeName: cleanText(c.Name.GivenNames),
+				Initials: initials(c.Name.GivenNames),
+			}
+			if c.Aff != nil {
+				if text := cleanText(c.Aff.Text); text != "" {
+					author.Affiliations = append(author.Affiliations, text)
+				} else if text := affByID[c.Aff.ID]; text != "" {
+					author.Affiliations = append(author.Affiliations, text)
+				}
+			}
+			r.Authors = append(r.Authors, author)
+		}
+	}
+	for _, group := range meta.ArticleCategories {
+		if group.SubjectGroupType == "heading" {
+			r.PublicationTypes = append(r.PublicationTypes, nonEmpty(group.Subjects)...)
+		}
+	}
+
+	if a.Body != nil {
+		r.Sections = flattenSections(a.Body.Sections, "", 0, nil)
+	}
+	if a.Back != nil && a.Back.References != nil {
+		for _, ref := range a.Back.References.References {
+			r.References = append(r.References, pmcReference(ref))
+		}
+	}
+	return r
+}
+
+// flattenSections lists sections depth-first, building the title path as it descends.
+func flattenSections(sections []xmlTools.PMCSection, parentPath string, depth int, out []SectionRecord) []SectionRecord {
+	for _, sec := range sections {
+		title := cleanText(sec.Title)
+		path := title
+		if parentPath != "" {
+			path = parentPath + " > " + title
+		}
+		var paragraphs []string
+		for _, p := range sec.Paragraphs {
+			if text := cleanText(p); text != "" {
+				paragraphs = append(paragraphs, text)
+			}
+		}
+		out = append(out, SectionRecord{
+			ID:         sec.ID,
+			SecType:    sec.SecType,
+			Title:      title,
+			Path:       path,
+			Depth:      depth,
+			Paragraphs: paragraphs,
+		})
+		out = flattenSections(sec.SubSections, path, depth+1, out)
+	}
+	return out
+}
+
+// setArticleID stores a PMID, PMCID or DOI from an identifier list.
+func (r *ArticleRecord) setArticleID(idType, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	switch strings.ToLower(idType) {
+	case "pubmed", "pmid":
+		if r.PMID == "" {
+			r.PMID = value
+		}
+	case "pmc", "pmcid":
+		if r.PMCID == "" {
+			r.PMCID = normalizePMCID(value)
+		}
+	case "doi":
+		if r.DOI == "" {
+			r.DOI = value
+		}
+	}
+}
+
+// pubmedReference converts one PubMed reference list entry.
+func pubmedReference(ref xmlTools.Reference) ReferenceRecord {
+	rec := ReferenceRecord{Citation: cleanText(ref.Citation)}
+	for _, id := range ref.ArticleIdList.ArticleIds {
+		value := strings.TrimSpace(id.ID)
+		switch id.IdType {
+		case "pubmed":
+			rec.PMID = value
+		case "doi":
+			rec.DOI = value
+		case "pmc", "pmcid":
+			rec.PMCID = normalizePMCID(value)
+		}
+	}
+	return rec
+}
+
+// pmcReference renders a PMC ref as "Authors. Title. Source. Year;Volume:Pages."
+func pmcReference(ref xmlTools.PMCReference) ReferenceRecord {
+	rec := ReferenceRecord{ID: ref.ID}
+	var title, source, year, volume, fpage, lpage, pubID string
+	var names []string
+
+	switch {
+	case ref.ElementCitation != nil:
+		c := ref.ElementCitation
+		title, source, year, volume, fpage, lpage, pubID = c.ArticleTitle, c.Source, c.Year, c.Volume, c.FPage, c.LPage, c.PubID
+		for _, n := range c.Name {
+			names = append(names, strings.TrimSpace(cleanText(n.Surname)+" "+initials(n.GivenNames)))
+		}
+	case ref.MixedCitation != nil:
+		c := ref.MixedCitation
+		title, source, year, volume, fpage, lpage, pubID = c.ArticleTitle, c.Source, c.Year, c.Volume, c.FPage, c.LPage, c.PubID
+	}
+
+	var parts []string
+	if len(names) > 0 {
+		parts = append(parts, strings.Join(names, ", "))
+	}
+	for _, p := range []string{cleanText(title), cleanText(source)} {
+		if p != "" {
+			parts = append(parts, strings.TrimSuffix(p, "."))
+		}
+	}
+	locator := strings.TrimSpace(year)
+	if volume = strings.TrimSpace(volume); volume != "" {
+		locator += ";" + volume
+	}
+	if pages := joinPages(fpage, lpage); pages != "" {
+		locator += ":" + pages
+	}
+	if locator != "" {
+		parts = append(parts, locator)
+	}
+	if len(parts) > 0 {
+		rec.Citation = strings.Join(parts, ". ") + "."
+	}
+
+	pubID = strings.TrimSpace(pubID)
+	switch {
+	case strings.HasPrefix(pubID, "10."):
+		rec.DOI = pubID
+	case strings.HasPrefix(strings.ToUpper(pubID), "PMC"):
+		rec.PMCID = normalizePMCID(pubID)
+	case pubID != "" && strings.Trim(pubID, "0123456789") == "":
+		rec.PMID = pubID
+	}
+	return rec
+}
+
+//
+// ------------------------ text helpers ------------------------
+//
+
+// cleanText collapses runs of whitespace and trims the result.
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// joinParagraphs cleans and joins paragraphs with blank lines.
+func joinParagraphs(paragraphs []string) string {
+	var kept []string
+	for _, p := range paragraphs {
+		if text := cleanText(p); text != "" {
+			kept = append(kept, text)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
+
+// abstractText joins the sections of a PubMed abstract with blank lines, prefixing
+// each with its label, e.g. "METHODS: ...".
+func abstractText(a xmlTools.Abstract) string {
+	var paragraphs []string
+	for _, section := range a.AbstractText {
+		text := cleanText(section.Text)
+		if label := cleanText(section.Label); label != "" && text != "" {
+			text = label + ": " + text
+		}
+		paragraphs = append(paragraphs, text)
+	}
+	return joinParagraphs(paragraphs)
+}
+
+// nonEmpty returns the cleaned, non-empty entries of values.
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if text := cleanText(v); text != "" {
+			out = append(out, text)
+		}
+	}
+	return out
+}
+
+// isoDate formats a complete PubMed date as YYYY-MM-DD, or returns "".
+func isoDate(d xmlTools.PubMedPubDate) string {
+	year, month, day := firstYear(d.Year), monthNumber(d.Month), strings.TrimSpace(d.Day)
+	n, err := strconv.Atoi(day)
+	if year == "" || month == "" || err != nil {
+		return ""
+	}
+	m, _ := strconv.Atoi(month)
+	return fmt.Sprintf("%s-%02d-%02d", year, m, n)
+}
+
+// firstYear returns the first four-digit year found in the candidates.
+func firstYear(candidates ...string) string {
+	for _, c := range candidates {
+		if m := yearPattern.FindString(c); m != "" {
+			return m
+		}
+	}
+	return ""
+}
+
+// monthNumber returns the first month found in the candidates as "1"–"12".
+// It accepts numbers ("03") and English names or abbreviations ("Mar", "March").
+func monthNumber(candidates ...string) string {
+	for _, c := range candidates {
+		for _, field := range strings.FieldsFunc(c, func(r rune) bool { return r == ' ' || r == '-' || r == '/' }) {
+			if n, err := strconv.Atoi(field); err == nil {
+				if n >= 1 && n <= 12 && len(field) <= 2 {
+					return strconv.Itoa(n)
+				}
+				continue
+			}
+			if len(field) >= 3 {
+				if i := strings.Index(monthNames, strings.ToLower(field[:3])); i >= 0 && i%3 == 0 {
+					return strconv.Itoa(i/3 + 1)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// monthNames holds the three-letter month abbreviations in calendar order.
+const monthNames = "janfebmaraprmayjunjulaugsepoctnovdec"
+
+// pubmedPages prefers MedlinePgn and falls back to StartPage-EndPage.
+func pubmedPages(p xmlTools.Pagination) string {
+	if pgn := strings.TrimSpace(p.MedlinePgn); pgn != "" {
+		return pgn
+	}
+	return joinPages(p.StartPage, p.EndPage)
+}
+
+// joinPages formats a first/last page pair as "first-last".
+func joinPages(first, last string) string {
+	first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+	switch {
+	case first == "":
+		return last
+	case last == "" || last == first:
+		return first
+	default:
+		return first + "-" + last
+	}
+}
+
+// initials derives initials from given names ("John Adam" → "JA").
+func initials(givenNames string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(givenNames, func(r rune) bool { return r == ' ' || r == '-' || r == '.' }) {
+		for _, r := range part {
+			b.WriteString(strings.ToUpper(string(r)))
+			break
+		}
+	}
+	return b.String()
+}
+
+// normalizePMCID returns a PMCID with its "PMC" prefix ("12345" → "PMC12345").
+func normalizePMCID(id string) string {
+	id = strings.TrimSpace(id)
+	switch {
+	case id == "":
+		return ""
+	case strings.HasPrefix(strings.ToUpper(id), "PMC"):
+		return "PMC" + id[3:]
+	default:
+		return "PMC" + id
+	}
+}
@@ -0,0 +1,201 @@
+package exportTools
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultIndexName is the index named in bulk actions when --index is not given.
+const DefaultIndexName = "pubparse"
+
+// SearchDocument is the search-friendly projection of an article indexed by
+// --format es-bulk.
+type SearchDocument struct {
+	Source           string   `json:"source"`
+	PMID             string   `json:"pmid,omitempty"`
+	PMCID            string   `json:"pmcid,omitempty"`
+	DOI              string   `json:"doi,omitempty"`
+	Title            string   `json:"title,omitempty"`
+	Abstract         string   `json:"abstract,omitempty"`
+	Authors          []string `json:"authors,omitempty"`
+	MeSH             []string `json:"mesh,omitempty"`
+	Keywords         []string `json:"keywords,omitempty"`
+	Journal          string   `json:"journal,omitempty"`
+	JournalAbbrev    string   `json:"journal_abbrev,omitempty"`
+	Year             *int     `json:"year,omitempty"`
+	PublicationTypes []string `json:"publication_types,omitempty"`
+	Languages        []string `json:"languages,omitempty"`
+}
+
+// searchKeyword is a text field with an exact-match "keyword" subfield.
+var searchKeyword = map[string]interface{}{
+	"type":   "text",
+	"fields": map[string]interface{}{"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256}},
+}
+
+// SearchMapping is the index mapping matching SearchDocument, written next to the
+// bulk files as <index>.mapping.json.
+var SearchMapping = map[string]interface{}{
+	"mappings": map[string]interface{}{
+		"dynamic": "strict",
+		"properties": map[string]interface{}{
+			"source":            map[string]interface{}{"type": "keyword"},
+			"pmid":              map[string]interface{}{"type": "keyword"},
+			"pmcid":             map[string]interface{}{"type": "keyword"},
+			"doi":               map[string]interface{}{"type": "keyword"},
+			"title":             map[string]interface{}{"type": "text"},
+			"abstract":          map[string]interface{}{"type": "text"},
+			"authors":           searchKeyword,
+			"mesh":              map[string]interface{}{"type": "keyword"},
+			"keywords":          searchKeyword,
+			"journal":           searchKeyword,
+			"journal_abbrev":    map[string]interface{}{"type": "keyword"},
+			"year":              map[string]interface{}{"type": "integer"},
+			"publication_types": map[string]interface{}{"type": "keyword"},
+			"languages":         map[string]interface{}{"type": "keyword"},
+		},
+	},
+}
+
+//
+// ------------------------ WriteBulk ------------------------
+//
+
+/*
+WriteBulk writes the articles of a parsed input as an Elasticsearch/OpenSearch
+_bulk request body.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle,
+    already normalized.
+  - fileName: Path of the output .ndjson file.
+  - index: Target index, named in every action line.
+
+Returns:
+  - An error if flattening or writing fails.
+*/
+func WriteBulk(data interface{}, fileName, index string) error {
+	records, err := FlattenArticles(data)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("failed to create bulk file %q: %w", fileName, err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := EncodeBulk(w, records, index); err != nil {
+		return fmt.Errorf("failed to write bulk file %q: %w", fileName, err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write bulk file %q: %w", fileName, err)
+	}
+	return f.Close()
+}
+
+/*
+EncodeBulk writes one action line and one document line per record.
+
+Behavior:
+  - Actions are "index" operations, so re-loading a file replaces existing documents.
+  - The document _id is the PMID, or the PMCID when there is no PMID; records with
+    neither get an id assigned by the cluster.
+  - The body ends with a newline, as the _bulk API requires.
+*/
+func EncodeBulk(w io.Writer, records []ArticleRecord, index string) error {
+	type action struct {
+		Index string `json:"_index"`
+		ID    string `json:"_id,omitempty"`
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, r := range records {
+		id := r.PMID
+		if id == "" {
+			id = r.PMCID
+		}
+		if err := enc.Encode(map[string]action{"index": {Index: index, ID: id}}); err != nil {
+			return err
+		}
+		if err := enc.Encode(ToSearchDocument(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToSearchDocument projects a flattened article onto the indexed fields.
+func ToSearchDocument(r ArticleRecord) SearchDocument {
+	doc := SearchDocument{
+		Source:           r.Source,
+		PMID:             r.PMID,
+		PMCID:            r.PMCID,
+		DOI:              r.DOI,
+		Title:            r.Title,
+		Abstract:         r.Abstract,
+		Keywords:         r.Keywords,
+		Journal:          r.Journal,
+		JournalAbbrev:    r.JournalAbbrev,
+		PublicationTypes: r.PublicationTypes,
+		Languages:        r.Languages,
+	}
+	for _, a := range r.Authors {
+		if a.CollectiveName != "" {
+			doc.Authors = append(doc.Authors, a.CollectiveName)
+		} else if name := strings.TrimSpace(a.ForeName + " " + a.LastName); name != "" {
+			doc.Authors = append(doc.Authors, name)
+		}
+	}
+	for _, m := range r.MeSH {
+		doc.MeSH = append(doc.MeSH, m.Descriptor)
+	}
+	if year, err := strconv.Atoi(r.Year); err == nil {
+		doc.Year = &year
+	}
+	return doc
+}
+
+// WriteSearchMapping writes SearchMapping as indented JSON, ready for
+// "PUT /<index>" with curl.
+func WriteSearchMapping(fileName string) error {
+	content, err := json.MarshalIndent(SearchMapping, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(fileName, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write index mapping %q: %w", fileName, err)
+	}
+	return nil
+}
+
+/*
+ValidateIndexName checks an index name against the Elasticsearch naming rules.
+
+Returns:
+  - An error if the name is empty, longer than 255 bytes, not lower-case, starts
+    with "-", "_" or "+", is "." or "..", or contains one of \ / * ? " < > | , # : or a space.
+*/
+func ValidateIndexName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("index name must not be empty")
+	case len(name) > 255:
+		return fmt.Errorf("index name %q is longer than 255 bytes", name)
+	case name != strings.ToLower(name):
+		return fmt.Errorf("index name %q must be lower-case", name)
+	case strings.ContainsAny(name[:1], "-_+"), name == ".", name == "..":
+		return fmt.Errorf("invalid index name %q", name)
+	case strings.ContainsAny(name, `\/*?"<>|,#: `):
+		return fmt.Errorf("index name %q contains a forbidden character", name)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package exportTools_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/exportTools"
+)
+
+//
+// ------------------------ Test: EncodeBulk ------------------------
+//
+
+// TestEncodeBulk checks action/document pairing, ids and the year projection.
+func TestEncodeBulk(t *testing.T) {
+	records := []exportTools.ArticleRecord{citationRecord, {Source: "pmc", PMCID: "PMC42", Year: "n.d."}}
+
+	var buf bytes.Buffer
+	if err := exportTools.EncodeBulk(&buf, records, "papers"); err != nil {
+		t.Fatalf("EncodeBulk failed: %v", err)
+	}
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d", len(lines))
+	}
+
+	wantIDs := []string{"32387127", "PMC42"}
+	for i, id := range wantIDs {
+		action, _ := lines[2*i]["index"].(map[string]interface{})
+		if action["_index"] != "papers" || action["_id"] != id {
+			t.Errorf("record %d: expected index action for %q, got %v", i, id, lines[2*i])
+		}
+	}
+	if lines[1]["year"] != float64(2020) || !reflect.DeepEqual(lines[1]["authors"], []interface{}{"Anna Müller", "Test Consortium"}) {
+		t.Errorf("unexpected document %v", lines[1])
+	}
+	if _, ok := lines[3]["year"]; ok {
+		t.Errorf("expected unparseable year to be omitted, got %v", lines[3])
+	}
+}
+
+//
+// ------------------------ Test: SearchMapping ------------------------
+//
+
+// TestSearchMapping checks that the strict mapping covers every SearchDocument field.
+func TestSearchMapping(t *testing.T) {
+	properties := exportTools.SearchMapping["mappings"].(map[string]interface{})["properties"].(map[string]interface{})
+
+	typ := reflect.TypeOf(exportTools.SearchDocument{})
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if _, ok := properties[name]; !ok {
+			t.Errorf("field %q is missing from the mapping", name)
+		}
+	}
+	if len(properties) != typ.NumField() {
+		t.Errorf("expected %d mapped fields, got %d", typ.NumField(), len(properties))
+	}
+}
+
+//
+// ------------------------ Test: ValidateIndexName ------------------------
+//
+
+// TestValidateIndexName checks the index naming rules.
+func TestValidateIndexName(t *testing.T) {
+	tests := []struct {
+		name    string // Index name
+		wantErr bool   // Whether validation should fail
+	}{
+		{"pubmed-2024", false},
+		{"", true},
+		{"PubMed", true},
+		{"_hidden", true},
+		{"a,b", true},
+		{"..", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := exportTools.ValidateIndexName(test.name); (err != nil) != test.wantErr {
+				t.Errorf("expected error=%v, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
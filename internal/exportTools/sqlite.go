@@ -0,0 +1,421 @@
+package exportTools
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver with FTS5
+)
+
+// sqliteSchema creates the database tables on first use. Every statement is
+// idempotent so an existing database can be updated in place.
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS articles (
+		id             INTEGER PRIMARY KEY,
+		source         TEXT NOT NULL,
+		pmid           TEXT,
+		pmcid          TEXT,
+		doi            TEXT,
+		title          TEXT,
+		abstract       TEXT,
+		journal        TEXT,
+		journal_abbrev TEXT,
+		issn           TEXT,
+		volume         TEXT,
+		issue          TEXT,
+		pages          TEXT,
+		year           INTEGER,
+		article_type   TEXT,
+		version        INTEGER,
+		date_revised   TEXT
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS articles_pmid ON articles (pmid, source)`,
+	`CREATE INDEX IF NOT EXISTS articles_pmcid ON articles (pmcid)`,
+	`CREATE INDEX IF NOT EXISTS articles_doi ON articles (doi)`,
+	`CREATE TABLE IF NOT EXISTS authors (
+		article_id      INTEGER NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+		position        INTEGER NOT NULL,
+		last_name       TEXT,
+		fore_name       TEXT,
+		initials        TEXT,
+		collective_name TEXT,
+		affiliations    TEXT, -- JSON array
+		PRIMARY KEY (article_id, position)
+	)`,
+	`CREATE TABLE IF NOT EXISTS mesh_headings (
+		article_id INTEGER NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+		position   INTEGER NOT NULL,
+		descriptor TEXT,
+		qualifiers TEXT, -- JSON array
+		PRIMARY KEY (article_id, position)
+	)`,
+	`CREATE INDEX IF NOT EXISTS mesh_headings_descriptor ON mesh_headings (descriptor)`,
+	`CREATE TABLE IF NOT EXISTS article_references (
+		article_id INTEGER NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+		position   INTEGER NOT NULL,
+		ref_id     TEXT,
+		citation   TEXT,
+		pmid       TEXT,
+		doi        TEXT,
+		pmcid      TEXT,
+		PRIMARY KEY (article_id, position)
+	)`,
+	`CREATE TABLE IF NOT EXISTS sections (
+		article_id INTEGER NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+		position   INTEGER NOT NULL,
+		section_id TEXT,
+		sec_type   TEXT,
+		title      TEXT,
+		path       TEXT,
+		depth      INTEGER,
+		PRIMARY KEY (article_id, position)
+	)`,
+	`CREATE TABLE IF NOT EXISTS paragraphs (
+		article_id       INTEGER NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+		section_position INTEGER NOT NULL,
+		position         INTEGER NOT NULL,
+		text             TEXT,
+		PRIMARY KEY (article_id, section_position, position)
+	)`,
+	`CREATE VIRTUAL TABLE IF NOT EXISTS articles_fts USING fts5 (title, abstract)`,
+}
+
+// sqliteColumns are the articles columns added after the table was first released;
+// they are added to databases created before.
+var sqliteColumns = []struct{ name, decl string }{
+	{"version", "INTEGER"},
+	{"date_revised", "TEXT"},
+}
+
+// sqliteBatchSize is the number of articles after which the writer commits its batch
+// even when more inputs are waiting.
+const sqliteBatchSize = 1000
+
+// SQLiteWriter stores articles in a single SQLite database. Workers hand their input's
+// articles to one writer goroutine, which stores the inputs waiting for it in a
+// shared transaction, each input under its own savepoint.
+type SQLiteWriter struct {
+	db      *sql.DB
+	inputs  chan sqliteInput // Inputs waiting for the writer
+	stopped chan struct{}    // Closed when the writer has committed its last batch
+}
+
+// sqliteInput is the articles of one input, sent by WriteArticles to the writer.
+type sqliteInput struct {
+	records []ArticleRecord
+	done    chan error // Receives the input's result once its batch is committed
+}
+
+//
+// ------------------------ NewSQLiteWriter ------------------------
+//
+
+/*
+NewSQLiteWriter opens (or creates) a SQLite database.
+
+Parameters:
+  - path: Path to the database file.
+
+Behavior:
+  - Creates the tables, indexes and the articles_fts FTS5 table if they do not exist,
+    and adds the columns a database from an earlier release lacks.
+  - Enables WAL journaling and foreign keys.
+  - Starts the writer goroutine that stores the inputs passed to WriteArticles.
+
+Returns:
+  - The SQLiteWriter, to be closed with Close once all inputs are processed.
+  - An error if the database cannot be opened or initialized.
+*/
+func NewSQLiteWriter(path string) (*SQLiteWriter, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %q: %w", path, err)
+	}
+	db.SetMaxOpenConns(1)
+
+	for _, stmt := range sqliteSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize database %q: %w", path, err)
+		}
+	}
+
+	if err := addColumns(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database %q: %w", path, err)
+	}
+
+	w := &SQLiteWriter{db: db, inputs: make(chan sqliteInput), stopped: make(chan struct{})}
+	go w.run()
+	return w, nil
+}
+
+// addColumns adds the sqliteColumns missing from the articles table.
+func addColumns(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('articles')`)
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+
+	for _, column := range sqliteColumns {
+		if !existing[column.name] {
+			if _, err := db.Exec(`ALTER TABLE articles ADD COLUMN ` + column.name + ` ` + column.decl); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//
+// ------------------------ WriteArticles ------------------------
+//
+
+/*
+WriteArticles flattens a parsed input and stores its articles.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle,
+    already normalized.
+
+Behavior:
+  - Sends the input's articles to the writer goroutine and waits until the batch
+    holding them is committed (see run).
+  - The input is stored under its own savepoint, so a failing article rolls back
+    this input only.
+
+Returns:
+  - An error if flattening fails, an article cannot be stored or the batch cannot be
+    committed; nothing of the input is stored then.
+*/
+func (w *SQLiteWriter) WriteArticles(data interface{}) error {
+	records, err := FlattenArticles(data)
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	w.inputs <- sqliteInput{records: records, done: done}
+	return <-done
+}
+
+// Close waits for the writer to commit the inputs already sent and closes the
+// database. WriteArticles must not be called afterwards.
+func (w *SQLiteWriter) Close() error {
+	close(w.inputs)
+	<-w.stopped
+	return w.db.Close()
+}
+
+//
+// ------------------------ run ------------------------
+//
+
+/*
+run is the writer goroutine of a SQLiteWriter.
+
+Behavior:
+  - Takes the next input, then every input already waiting, until sqliteBatchSize
+    articles are gathered, and stores them in one transaction (see commitBatch).
+  - An input sent alone, as when the inputs are written one after another, is
+    committed on its own.
+  - Returns once Close has closed the inputs channel and the last batch is committed.
+*/
+func (w *SQLiteWriter) run() {
+	defer close(w.stopped)
+
+	for input := range w.inputs {
+		batch := []sqliteInput{input}
+		articles := len(input.records)
+	gather:
+		for articles < sqliteBatchSize {
+			select {
+			case next, ok := <-w.inputs:
+				if !ok {
+					break gather
+				}
+				batch = append(batch, next)
+				articles += len(next.records)
+			default:
+				break gather
+			}
+		}
+		w.commitBatch(batch)
+	}
+}
+
+// commitBatch stores a batch of inputs in one transaction and sends each input its
+// result once the transaction is committed or rolled back.
+func (w *SQLiteWriter) commitBatch(batch []sqliteInput) {
+	errs := make([]error, len(batch))
+	defer func() {
+		for i, input := range batch {
+			input.done <- errs[i]
+		}
+	}()
+	fail := func(err error) {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+	}
+
+	tx, err := w.db.Begin()
+	if err != nil {
+		fail(fmt.Errorf("failed to begin transaction: %w", err))
+		return
+	}
+	for i, input := range batch {
+		var broken error
+		if errs[i], broken = storeInput(tx, input.records); broken != nil {
+			// The savepoint could not be undone: drop the whole batch
+			tx.Rollback()
+			fail(broken)
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		fail(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+}
+
+// storeInput stores one input's articles under a savepoint of tx. It returns the
+// input's error, with the input rolled back, or an error leaving tx unusable.
+func storeInput(tx *sql.Tx, records []ArticleRecord) (failed, broken error) {
+	if _, err := tx.Exec(`SAVEPOINT input`); err != nil {
+		return nil, fmt.Errorf("failed to begin input: %w", err)
+	}
+	for _, r := range records {
+		if err := upsertArticle(tx, r); err != nil {
+			failed = fmt.Errorf("failed to store article %q: %w", r.PMID+r.PMCID, err)
+			if _, err := tx.Exec(`ROLLBACK TO input`); err != nil {
+				return failed, fmt.Errorf("failed to roll back input: %w", err)
+			}
+			break
+		}
+	}
+	if _, err := tx.Exec(`RELEASE input`); err != nil {
+		return failed, fmt.Errorf("failed to release input: %w", err)
+	}
+	return failed, nil
+}
+
+// upsertArticle replaces any stored copy of an article (same source and PMID, or
+// PMCID when there is no PMID) and inserts it with all child rows. A stored copy
+// with a higher version, or the same version revised later, is kept instead, so
+// the result does not depend on the order the inputs are written in.
+func upsertArticle(tx *sql.Tx, r ArticleRecord) error {
+	rows, err := tx.Query(`SELECT id, COALESCE(version, 1), COALESCE(date_revised, '') FROM articles
+		WHERE source = ? AND (pmid = ? OR (pmid IS NULL AND pmcid = ?))`,
+		r.Source, nullString(r.PMID), nullString(r.PMCID))
+	if err != nil {
+		return err
+	}
+	var stale []int64
+	newer := false
+	for rows.Next() {
+		var id int64
+		var version int
+		var revised string
+		if err := rows.Scan(&id, &version, &revised); err != nil {
+			rows.Close()
+			return err
+		}
+		stale = append(stale, id)
+		newer = newer || version > r.Version || version == r.Version && revised > r.Revised
+	}
+	rows.Close()
+	if newer {
+		return nil
+	}
+
+	// Remove the previous version; child rows follow through ON DELETE CASCADE
+	for _, id := range stale {
+		if _, err := tx.Exec(`DELETE FROM articles_fts WHERE rowid = ?`, id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM articles WHERE id = ?`, id); err != nil {
+			return err
+		}
+	}
+
+	var year interface{}
+	if y, err := strconv.Atoi(r.Year); err == nil {
+		year = y
+	}
+	res, err := tx.Exec(`INSERT INTO articles (source, pmid, pmcid, doi, title, abstract, journal, journal_abbrev,
+		issn, volume, issue, pages, year, article_type, version, date_revised) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.Source, nullString(r.PMID), nullString(r.PMCID), nullString(r.DOI), r.Title, r.Abstract, r.Journal,
+		r.JournalAbbrev, r.ISSN, r.Volume, r.Issue, r.Pages, year, r.ArticleType, r.Version, r.Revised)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO articles_fts (rowid, title, abstract) VALUES (?, ?, ?)`, id, r.Title, r.Abstract); err != nil {
+		return err
+	}
+
+	for i, a := range r.Authors {
+		if _, err := tx.Exec(`INSERT INTO authors VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			id, i+1, a.LastName, a.ForeName, a.Initials, a.CollectiveName, jsonList(a.Affiliations)); err != nil {
+			return err
+		}
+	}
+	for i, m := range r.MeSH {
+		if _, err := tx.Exec(`INSERT INTO mesh_headings VALUES (?, ?, ?, ?)`,
+			id, i+1, m.Descriptor, jsonList(m.Qualifiers)); err != nil {
+			return err
+		}
+	}
+	for i, ref := range r.References {
+		if _, err := tx.Exec(`INSERT INTO article_references VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			id, i+1, ref.ID, ref.Citation, nullString(ref.PMID), nullString(ref.DOI), nullString(ref.PMCID)); err != nil {
+			return err
+		}
+	}
+	for i, s := range r.Sections {
+		if _, err := tx.Exec(`INSERT INTO sections VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			id, i+1, s.ID, s.SecType, s.Title, s.Path, s.Depth); err != nil {
+			return err
+		}
+		for j, p := range s.Paragraphs {
+			if _, err := tx.Exec(`INSERT INTO paragraphs VALUES (?, ?, ?, ?)`, id, i+1, j+1, p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// nullString maps "" to SQL NULL so identifier columns stay unique-indexable.
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// jsonList encodes a string list as a JSON array ("[]" when empty).
+func jsonList(values []string) string {
+	if len(values) == 0 {
+		return "[]"
+	}
+	b, _ := json.Marshal(values)
+	return string(b)
+}
@@ -0,0 +1,283 @@
+package exportTools_test
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/exportTools"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Test: SQLiteWriter ------------------------
+//
+
+// TestSQLiteWriter_Upsert loads the same input twice and checks that articles are
+// replaced by PMID rather than duplicated, and that the FTS index follows.
+func TestSQLiteWriter_Upsert(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "set.xml")
+	if err := os.WriteFile(input, []byte(pubmedSample), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	dbPath := filepath.Join(dir, "corpus.db")
+
+	for run := 0; run < 2; run++ {
+		w, err := exportTools.NewSQLiteWriter(dbPath)
+		if err != nil {
+			t.Fatalf("NewSQLiteWriter failed: %v", err)
+		}
+		data, err := xmlTools.ParsePubmedXML(input)
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+		if err := w.WriteArticles(data); err != nil {
+			t.Fatalf("WriteArticles failed: %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	tests := []struct {
+		query string // Counting query
+		want  int    // Expected count after two identical loads
+	}{
+		{`SELECT COUNT(*) FROM articles`, 2},
+		{`SELECT COUNT(*) FROM authors`, 1},
+		{`SELECT COUNT(*) FROM mesh_headings WHERE descriptor = 'Humans'`, 1},
+		{`SELECT COUNT(*) FROM articles WHERE pmid = '111' AND doi = '10.1000/first' AND year = 2021`, 1},
+		{`SELECT COUNT(*) FROM articles_fts WHERE articles_fts MATCH 'abstract'`, 1},
+	}
+	for _, test := range tests {
+		var got int
+		if err := db.QueryRow(test.query).Scan(&got); err != nil {
+			t.Fatalf("%s: %v", test.query, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: expected %d, got %d", test.query, test.want, got)
+		}
+	}
+}
+
+// writeSQLiteInputs stores each PubMed set in order through one SQLiteWriter and
+// returns the errors of the individual WriteArticles calls.
+func writeSQLiteInputs(t *testing.T, dbPath string, sets ...string) []error {
+	t.Helper()
+	w, err := exportTools.NewSQLiteWriter(dbPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteWriter failed: %v", err)
+	}
+	var errs []error
+	for i, set := range sets {
+		input := filepath.Join(t.TempDir(), fmt.Sprintf("set%d.xml", i))
+		if err := os.WriteFile(input, []byte(set), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+		data, err := xmlTools.ParsePubmedXML(input)
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+		errs = append(errs, w.WriteArticles(data))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return errs
+}
+
+// versionedArticle returns a PubmedArticle of the given version and revision date (YYYY-MM-DD).
+func versionedArticle(pmid, version, revised, title string) string {
+	return `<PubmedArticle><MedlineCitation VersionID="` + version + `"><PMID>` + pmid + `</PMID>` +
+		`<DateRevised><Year>` + revised[:4] + `</Year><Month>` + revised[5:7] + `</Month><Day>` + revised[8:] + `</Day></DateRevised>` +
+		`<Article><ArticleTitle>` + title + `</ArticleTitle></Article></MedlineCitation></PubmedArticle>`
+}
+
+// pubmedSet wraps articles in a PubmedArticleSet.
+func pubmedSet(articles ...string) string {
+	return "<PubmedArticleSet>" + strings.Join(articles, "") + "</PubmedArticleSet>"
+}
+
+// TestSQLiteWriter_KeepsNewest verifies that a stored article is only replaced by the
+// same or a newer version, whatever the order the inputs are written in.
+func TestSQLiteWriter_KeepsNewest(t *testing.T) {
+	tests := []struct {
+		name string
+		sets []string // Inputs in the order they are written
+		want string   // Title stored for PMID 5
+	}{
+		{"older version later", []string{
+			pubmedSet(versionedArticle("5", "2", "2024-01-01", "New")),
+			pubmedSet(versionedArticle("5", "1", "2024-06-01", "Old")),
+		}, "New"},
+		{"newer version later", []string{
+			pubmedSet(versionedArticle("5", "1", "2024-06-01", "Old")),
+			pubmedSet(versionedArticle("5", "2", "2024-01-01", "New")),
+		}, "New"},
+		{"earlier revision later", []string{
+			pubmedSet(versionedArticle("5", "1", "2024-06-01", "New")),
+			pubmedSet(versionedArticle("5", "1", "2023-12-31", "Old")),
+		}, "New"},
+		{"same revision later", []string{
+			pubmedSet(versionedArticle("5", "1", "2024-06-01", "Old")),
+			pubmedSet(versionedArticle("5", "1", "2024-06-01", "New")),
+		}, "New"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dbPath := filepath.Join(t.TempDir(), "corpus.db")
+			for _, err := range writeSQLiteInputs(t, dbPath, test.sets...) {
+				if err != nil {
+					t.Fatalf("WriteArticles failed: %v", err)
+				}
+			}
+
+			db, err := sql.Open("sqlite", dbPath)
+			if err != nil {
+				t.Fatalf("failed to open database: %v", err)
+			}
+			defer db.Close()
+			var title string
+			if err := db.QueryRow(`SELECT title FROM articles WHERE pmid = '5'`).Scan(&title); err != nil || title != test.want {
+				t.Errorf("expected title %q, got %q (%v)", test.want, title, err)
+			}
+		})
+	}
+}
+
+// rejectingDatabase creates a database that refuses to store article 666 and returns
+// it opened for checking what was stored.
+func rejectingDatabase(t *testing.T, dbPath string) *sql.DB {
+	t.Helper()
+	w, err := exportTools.NewSQLiteWriter(dbPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteWriter failed: %v", err)
+	}
+	w.Close()
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`CREATE TRIGGER reject BEFORE INSERT ON articles WHEN NEW.pmid = '666'
+		BEGIN SELECT RAISE(ABORT, 'rejected'); END`); err != nil {
+		t.Fatalf("failed to create trigger: %v", err)
+	}
+	return db
+}
+
+// storedPMIDs lists the PMIDs in the articles table, comma-separated in text order.
+func storedPMIDs(t *testing.T, db *sql.DB) string {
+	t.Helper()
+	var pmids []string
+	rows, err := db.Query(`SELECT pmid FROM articles ORDER BY pmid`)
+	if err != nil {
+		t.Fatalf("failed to query articles: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var pmid string
+		rows.Scan(&pmid)
+		pmids = append(pmids, pmid)
+	}
+	return strings.Join(pmids, ",")
+}
+
+// TestSQLiteWriter_FailedInput verifies that an article that cannot be stored fails
+// its own input only, when it is written, and leaves the other inputs stored.
+func TestSQLiteWriter_FailedInput(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "corpus.db")
+	db := rejectingDatabase(t, dbPath)
+
+	errs := writeSQLiteInputs(t, dbPath,
+		pubmedSet(versionedArticle("1", "1", "2024-01-01", "First")),
+		pubmedSet(versionedArticle("2", "1", "2024-01-01", "Second"), versionedArticle("666", "1", "2024-01-01", "Bad")),
+		pubmedSet(versionedArticle("3", "1", "2024-01-01", "Third")),
+	)
+	if errs[0] != nil || errs[2] != nil {
+		t.Errorf("expected the other inputs to be stored, got %v", errs)
+	}
+	if errs[1] == nil || !strings.Contains(errs[1].Error(), `"666"`) {
+		t.Errorf("expected the second input to fail on article 666, got %v", errs[1])
+	}
+
+	if pmids := storedPMIDs(t, db); pmids != "1,3" {
+		t.Errorf("expected articles 1 and 3 only, got %s", pmids)
+	}
+}
+
+// TestSQLiteWriter_ConcurrentInputs writes inputs from many goroutines, so the writer
+// may store several in one transaction, and verifies that a failing input is rolled
+// back alone while the inputs batched with it are stored.
+func TestSQLiteWriter_ConcurrentInputs(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "corpus.db")
+	db := rejectingDatabase(t, dbPath)
+
+	var inputs []interface{}
+	for i := 0; i < 20; i++ {
+		pmid := fmt.Sprintf("%02d", i)
+		articles := []string{versionedArticle(pmid, "1", "2024-01-01", "Title "+pmid)}
+		if i == 7 {
+			articles = append(articles, versionedArticle("666", "1", "2024-01-01", "Bad"))
+		}
+		input := filepath.Join(dir, pmid+".xml")
+		if err := os.WriteFile(input, []byte(pubmedSet(articles...)), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+		data, err := xmlTools.ParsePubmedXML(input)
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+		inputs = append(inputs, data)
+	}
+
+	w, err := exportTools.NewSQLiteWriter(dbPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteWriter failed: %v", err)
+	}
+	errs := make([]error, len(inputs))
+	start := make(chan struct{}) // Released at once, so the inputs queue behind each other
+	var wg sync.WaitGroup
+	for i, data := range inputs {
+		wg.Add(1)
+		go func(i int, data interface{}) {
+			defer wg.Done()
+			<-start
+			errs[i] = w.WriteArticles(data)
+		}(i, data)
+	}
+	close(start)
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var want []string
+	for i, err := range errs {
+		switch {
+		case i == 7 && (err == nil || !strings.Contains(err.Error(), `"666"`)):
+			t.Errorf("expected input 7 to fail on article 666, got %v", err)
+		case i != 7 && err != nil:
+			t.Errorf("input %d: unexpected error: %v", i, err)
+		case i != 7:
+			want = append(want, fmt.Sprintf("%02d", i))
+		}
+	}
+	if pmids := storedPMIDs(t, db); pmids != strings.Join(want, ",") {
+		t.Errorf("expected articles %v, got %s", want, pmids)
+	}
+}
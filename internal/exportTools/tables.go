@@ -0,0 +1,210 @@
+package exportTools
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Table describes one relational TSV table: its file name (without extension)
+// and its columns in output order. Every table is keyed by the pmid column.
+type Table struct {
+	Name    string
+	Columns []string
+}
+
+// Tables lists the relational export tables in the order they are created.
+// Column orders are stable; new columns are only ever appended.
+var Tables = []Table{
+	{"articles", []string{"pmid", "pmcid", "doi", "source", "title", "abstract", "journal", "journal_abbrev", "issn", "volume", "issue", "pages", "year", "article_type"}},
+	{"authors", []string{"pmid", "author_pos", "last_name", "fore_name", "initials", "collective_name"}},
+	{"author_affiliations", []string{"pmid", "author_pos", "affiliation_pos", "affiliation"}},
+	{"mesh_headings", []string{"pmid", "mesh_pos", "descriptor", "qualifiers"}},
+	{"chemicals", []string{"pmid", "chemical_pos", "registry_number", "name"}},
+	{"keywords", []string{"pmid", "keyword_pos", "keyword"}},
+	{"references", []string{"pmid", "reference_pos", "citation", "ref_pmid", "ref_doi", "ref_pmcid"}},
+	{"grants", []string{"pmid", "grant_pos", "grant_id", "acronym", "agency", "country"}},
+	{"publication_types", []string{"pmid", "publication_type_pos", "publication_type"}},
+}
+
+// nullValue marks a missing value in the PostgreSQL COPY text format.
+const nullValue = `\N`
+
+// TableWriter appends article rows to one TSV file per table in a directory.
+// It is safe for concurrent use by the worker pool.
+type TableWriter struct {
+	mu      sync.Mutex
+	files   map[string]*os.File
+	writers map[string]*bufio.Writer
+}
+
+//
+// ------------------------ NewTableWriter ------------------------
+//
+
+/*
+NewTableWriter creates (or truncates) <dir>/<table>.tsv for every entry of Tables
+and writes the header rows.
+
+Parameters:
+  - dir: Output directory; it must already exist.
+
+Returns:
+  - The TableWriter, to be closed with Close once all inputs are processed.
+  - An error if a file cannot be created.
+*/
+func NewTableWriter(dir string) (*TableWriter, error) {
+	w := &TableWriter{
+		files:   make(map[string]*os.File),
+		writers: make(map[string]*bufio.Writer),
+	}
+	for _, table := range Tables {
+		f, err := os.Create(filepath.Join(dir, table.Name+".tsv"))
+		if err != nil {
+			w.Close()
+			return nil, fmt.Errorf("failed to create table %q: %w", table.Name, err)
+		}
+		bw := bufio.NewWriter(f)
+		bw.WriteString(strings.Join(table.Columns, "\t") + "\n")
+		w.files[table.Name] = f
+		w.writers[table.Name] = bw
+	}
+	return w, nil
+}
+
+//
+// ------------------------ WriteArticles ------------------------
+//
+
+/*
+WriteArticles flattens a parsed input and appends its rows to every table.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle,
+    already normalized.
+
+Behavior:
+  - Rows of one input are rendered first and then appended under a single lock,
+    so the rows of an article are never interleaved with another worker's.
+  - Positions (author_pos, mesh_pos, ...) are 1-based and follow document order.
+  - PMC articles without a PMID are keyed by their PMCID.
+
+Returns:
+  - An error if flattening or writing fails.
+*/
+func (w *TableWriter) WriteArticles(data interface{}) error {
+	records, err := FlattenArticles(data)
+	if err != nil {
+		return err
+	}
+
+	rows := make(map[string][][]string)
+	for _, r := range records {
+		appendTableRows(rows, r)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, table := range Tables {
+		bw := w.writers[table.Name]
+		for _, row := range rows[table.Name] {
+			for i, value := range row {
+				if i > 0 {
+					bw.WriteByte('\t')
+				}
+				bw.WriteString(value)
+			}
+			if err := bw.WriteByte('\n'); err != nil {
+				return fmt.Errorf("failed to write table %q: %w", table.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// appendTableRows renders one record into rows for every table, in Tables column order.
+func appendTableRows(rows map[string][][]string, r ArticleRecord) {
+	key := r.PMID
+	if key == "" {
+		key = r.PMCID
+	}
+	add := func(table string, values ...string) {
+		row := make([]string, 0, len(values)+1)
+		row = append(row, escapeTSV(key))
+		for _, v := range values {
+			row = append(row, escapeTSV(v))
+		}
+		rows[table] = append(rows[table], row)
+	}
+	pos := func(i int) string { return strconv.Itoa(i + 1) }
+
+	add("articles", r.PMCID, r.DOI, r.Source, r.Title, r.Abstract, r.Journal, r.JournalAbbrev,
+		r.ISSN, r.Volume, r.Issue, r.Pages, r.Year, r.ArticleType)
+	if r.Year == "" {
+		// A missing year is NULL rather than "" so the column loads as an integer
+		rows["articles"][len(rows["articles"])-1][12] = nullValue
+	}
+
+	for i, a := range r.Authors {
+		add("authors", pos(i), a.LastName, a.ForeName, a.Initials, a.CollectiveName)
+		for j, aff := range a.Affiliations {
+			add("author_affiliations", pos(i), pos(j), aff)
+		}
+	}
+	for i, m := range r.MeSH {
+		add("mesh_headings", pos(i), m.Descriptor, strings.Join(m.Qualifiers, "; "))
+	}
+	for i, c := range r.Chemicals {
+		add("chemicals", pos(i), c.RegistryNumber, c.Name)
+	}
+	for i, kw := range r.Keywords {
+		add("keywords", pos(i), kw)
+	}
+	for i, ref := range r.References {
+		add("references", pos(i), ref.Citation, ref.PMID, ref.DOI, ref.PMCID)
+	}
+	for i, g := range r.Grants {
+		add("grants", pos(i), g.GrantID, g.Acronym, g.Agency, g.Country)
+	}
+	for i, pt := range r.PublicationTypes {
+		add("publication_types", pos(i), pt)
+	}
+}
+
+// escapeTSV escapes a value for the PostgreSQL COPY text format
+// (backslash, tab, newline and carriage return).
+func escapeTSV(s string) string {
+	if !strings.ContainsAny(s, "\\\t\n\r") {
+		return s
+	}
+	return strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
+
+//
+// ------------------------ Close ------------------------
+//
+
+// Close flushes and closes every table file, returning the first error.
+func (w *TableWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var firstErr error
+	for _, table := range Tables {
+		if bw, ok := w.writers[table.Name]; ok {
+			if err := bw.Flush(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		if f, ok := w.files[table.Name]; ok {
+			if err := f.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,76 @@
+package exportTools_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/exportTools"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Test: TableWriter ------------------------
+//
+
+// TestTableWriter_ConcurrentWrites appends the same input from several goroutines
+// and checks that headers are written once and every row is complete.
+func TestTableWriter_ConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "set.xml")
+	if err := os.WriteFile(input, []byte(pubmedSample), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	w, err := exportTools.NewTableWriter(dir)
+	if err != nil {
+		t.Fatalf("NewTableWriter failed: %v", err)
+	}
+
+	const workers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := xmlTools.ParsePubmedXML(input)
+			if err != nil {
+				t.Errorf("unexpected parse error: %v", err)
+				return
+			}
+			if err := w.WriteArticles(data); err != nil {
+				t.Errorf("WriteArticles failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	for _, table := range exportTools.Tables {
+		content, err := os.ReadFile(filepath.Join(dir, table.Name+".tsv"))
+		if err != nil {
+			t.Fatalf("failed to read table %q: %v", table.Name, err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+		if lines[0] != strings.Join(table.Columns, "\t") {
+			t.Errorf("%s: unexpected header %q", table.Name, lines[0])
+		}
+		for _, line := range lines[1:] {
+			if n := strings.Count(line, "\t") + 1; n != len(table.Columns) {
+				t.Errorf("%s: expected %d columns, got %d in %q", table.Name, len(table.Columns), n, line)
+			}
+		}
+		if table.Name == "articles" && len(lines)-1 != 2*workers {
+			t.Errorf("expected %d article rows, got %d", 2*workers, len(lines)-1)
+		}
+	}
+
+	articles, _ := os.ReadFile(filepath.Join(dir, "articles.tsv"))
+	if !strings.Contains(string(articles), "222\t\t\tpubmed\tSecond article.") {
+		t.Errorf("expected row for PMID 222, got:\n%s", articles)
+	}
+}
@@ -0,0 +1,421 @@
+package exportTools
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+// TextFormats lists the full-text formats accepted by --format.
+var TextFormats = []string{"text", "markdown"}
+
+// IsTextFormat reports whether format is one of TextFormats.
+func IsTextFormat(format string) bool {
+	for _, f := range TextFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// TextOptions controls how articles are rendered as plain text or Markdown.
+type TextOptions struct {
+	Markdown       bool // Markdown headings and emphasis instead of plain text
+	DropReferences bool // Omit the reference list
+	StripCitations bool // Remove the in-text citations marked by TextDecodeOptions, with their brackets
+}
+
+//
+// ------------------------ WriteText ------------------------
+//
+
+/*
+WriteText renders a parsed input as a plain-text or Markdown document.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle,
+    already normalized.
+  - fileName: Path of the output file.
+  - opts: Rendering options.
+
+Returns:
+  - An error if rendering or writing fails.
+*/
+func WriteText(data interface{}, fileName string, opts TextOptions) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("failed to create text file %q: %w", fileName, err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := EncodeText(w, data, opts); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write text file %q: %w", fileName, err)
+	}
+	return f.Close()
+}
+
+/*
+EncodeText writes data to w as plain text or Markdown.
+
+Layout:
+  - PMC articles: title, authors, journal line, abstract, body sections with their
+    headings nested by depth, figure and table captions where they appear, floating
+    figures and tables, acknowledgments and the numbered reference list.
+  - PubMed articles and books: title and abstract per article; in Markdown, articles
+    are separated by a "---" rule.
+  - Plain-text headings are underlined ("=" for the title, "-" for top-level sections)
+    and body sections are numbered ("2.1 Statistical analysis").
+
+Returns:
+  - An error for unsupported types or failed writes.
+*/
+func EncodeText(w io.Writer, data interface{}, opts TextOptions) error {
+	t := &textRenderer{opts: opts}
+
+	switch v := data.(type) {
+	case *xmlTools.PMCArticle:
+		t.pmcArticle(v)
+
+	case *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet:
+		records, err := FlattenArticles(v)
+		if err != nil {
+			return err
+		}
+		for i, r := range records {
+			if i > 0 && opts.Markdown {
+				t.block("---")
+			}
+			t.heading(1, "", r.Title)
+			t.paragraphs(strings.Split(r.Abstract, "\n\n"))
+		}
+
+	default:
+		return fmt.Errorf("unsupported data type for export: %T", data)
+	}
+
+	_, err := io.WriteString(w, strings.Join(t.blocks, "\n\n")+"\n")
+	return err
+}
+
+//
+// ------------------------ textRenderer ------------------------
+//
+
+// textRenderer collects the blocks of a document; blocks are joined by blank lines.
+type textRenderer struct {
+	opts   TextOptions
+	blocks []string
+}
+
+func (t *textRenderer) block(s string) {
+	if s != "" {
+		t.blocks = append(t.blocks, s)
+	}
+}
+
+// heading adds a heading at level 1 (document title) or deeper. number is the
+// section number shown in plain text, e.g. "2.1"; Markdown shows depth with "#".
+func (t *textRenderer) heading(level int, number, title string) {
+	title = t.prose(title)
+	if title == "" {
+		return
+	}
+	if t.opts.Markdown {
+		t.block(strings.Repeat("#", min(level, 6)) + " " + title)
+		return
+	}
+
+	if number != "" {
+		title = number + " " + title
+	}
+	switch level {
+	case 1:
+		t.block(title + "\n" + strings.Repeat("=", utf8.RuneCountInString(title)))
+	case 2:
+		t.block(title + "\n" + strings.Repeat("-", utf8.RuneCountInString(title)))
+	default:
+		t.block(title)
+	}
+}
+
+func (t *textRenderer) paragraphs(paragraphs []string) {
+	for _, p := range paragraphs {
+		t.block(t.prose(p))
+	}
+}
+
+// caption adds a figure or table caption, e.g. "Figure 1. Study design.".
+func (t *textRenderer) caption(kind, label string, c xmlTools.PMCCaption) {
+	text := t.prose(strings.Join(c.Paragraphs, " "))
+	label = cleanText(label)
+	if label == "" {
+		label = kind
+	}
+	if !strings.HasSuffix(label, ".") && !strings.HasSuffix(label, ":") {
+		label += "."
+	}
+	if t.opts.Markdown {
+		label = "**" + label + "**"
+	}
+	t.block(strings.TrimSpace(label + " " + text))
+}
+
+// prose cleans a paragraph or title and, if requested, removes the citations marked
+// while decoding together with their brackets.
+func (t *textRenderer) prose(s string) string {
+	if t.opts.StripCitations {
+		s = citationGroup.ReplaceAllString(s, "")
+	}
+	return cleanText(strings.ReplaceAll(s, citationMark, ""))
+}
+
+func (t *textRenderer) pmcArticle(a *xmlTools.PMCArticle) {
+	r := FlattenPMCArticle(a)
+	meta := a.Front.ArticleMeta
+
+	t.heading(1, "", r.Title)
+
+	var names []string
+	for _, author := range r.Authors {
+		if author.CollectiveName != "" {
+			names = append(names, author.CollectiveName)
+		} else if name := strings.TrimSpace(author.ForeName + " " + author.LastName); name != "" {
+			names = append(names, name)
+		}
+	}
+	t.block(strings.Join(names, ", "))
+
+	var source []string
+	if r.Journal != "" {
+		journal := r.Journal
+		if t.opts.Markdown {
+			journal = "*" + journal + "*"
+		}
+		source = append(source, journal)
+	}
+	if r.Year != "" {
+		source = append(source, r.Year)
+	}
+	if r.DOI != "" {
+		source = append(source, "doi:"+r.DOI)
+	}
+	t.block(strings.Join(source, ". "))
+
+	if meta.Abstract != nil {
+		t.heading(2, "", "Abstract")
+		t.paragraphs(meta.Abstract.Paragraphs)
+		for _, sec := range meta.Abstract.Sec {
+			t.heading(3, "", sec.Title)
+			t.paragraphs(sec.Paragraphs)
+		}
+	}
+
+	if a.Body != nil {
+		t.sections(a.Body.Sections, 2, "")
+	}
+
+	if a.FloatsGroup != nil {
+		for _, fig := range a.FloatsGroup.Figures {
+			t.caption("Figure", fig.Label, fig.Caption)
+		}
+		for _, tab := range a.FloatsGroup.Tables {
+			t.caption("Table", tab.Label, tab.Caption)
+		}
+	}
+
+	if a.Back == nil {
+		return
+	}
+	if ack := a.Back.Acknowledgments; ack != nil && len(ack.Paragraphs) > 0 {
+		t.heading(2, "", "Acknowledgments")
+		t.paragraphs(ack.Paragraphs)
+	}
+	if refs := a.Back.References; refs != nil && !t.opts.DropReferences {
+		var lines []string
+		for _, ref := range refs.References {
+			if citation := pmcReference(ref).Citation; citation != "" {
+				lines = append(lines, strconv.Itoa(len(lines)+1)+". "+citation)
+			}
+		}
+		// No heading for a reference list in which nothing renders
+		if len(lines) > 0 {
+			t.heading(2, "", "References")
+			t.block(strings.Join(lines, "\n"))
+		}
+	}
+}
+
+// sections renders body sections recursively; untitled sections keep their
+// content but add no heading and no number.
+func (t *textRenderer) sections(sections []xmlTools.PMCSection, level int, prefix string) {
+	n := 0
+	for _, sec := range sections {
+		number := ""
+		if cleanText(sec.Title) != "" {
+			n++
+			number = prefix + strconv.Itoa(n)
+		}
+		t.heading(level, number, sec.Title)
+		t.paragraphs(sec.Paragraphs)
+		for _, fig := range sec.Figures {
+			t.caption("Figure", fig.Label, fig.Caption)
+		}
+		for _, tab := range sec.Tables {
+			t.caption("Table", tab.Label, tab.Caption)
+		}
+
+		childPrefix := prefix
+		if number != "" {
+			childPrefix = number + "."
+		}
+		t.sections(sec.SubSections, level+1, childPrefix)
+	}
+}
+
+//
+// ------------------------ Inline markup ------------------------
+//
+
+// inlineElements are the presentational and linking elements of JATS and PubMed
+// whose text belongs to the paragraph or title that contains them.
+var inlineElements = map[string]bool{
+	// JATS
+	"italic": true, "bold": true, "sup": true, "sub": true, "sc": true,
+	"underline": true, "overline": true, "strike": true, "monospace": true,
+	"roman": true, "sans-serif": true, "xref": true, "ext-link": true,
+	"uri": true, "email": true, "named-content": true, "styled-content": true,
+	"abbrev": true, "inline-formula": true, "break": true,
+	// PubMed
+	"i": true, "b": true, "u": true,
+}
+
+// textElements are the elements whose inline children are folded into their text.
+// Struct fields for these elements are plain strings, so without folding the text
+// of <italic>, <xref>, ... children would be dropped.
+var textElements = map[string]bool{
+	// JATS
+	"p": true, "title": true, "article-title": true, "trans-title": true,
+	"subtitle": true, "alt-title": true, "source": true, "label": true,
+	// PubMed
+	"ArticleTitle": true, "AbstractText": true, "VernacularTitle": true,
+	"BookTitle": true, "Affiliation": true, "Keyword": true,
+}
+
+// citationMark stands in for a bibliographic <xref> dropped while decoding, so that
+// the brackets and separators around it can be removed with it when rendering.
+const citationMark = "\uE000"
+
+// citationGroup matches a run of citation marks with the separators between them
+// and the brackets around them, e.g. " [<mark>, <mark>]" or "<mark>–<mark>".
+var citationGroup = regexp.MustCompile(`\s*[\[(]?\x{E000}(?:[\s,;–-]*\x{E000})*[\])]?`)
+
+// FoldsInline reports whether an output format renders prose and therefore decodes
+// its input with TextDecodeOptions. The other formats keep the decoded structs as
+// they are, so their JSON and tables never contain glued citation markers.
+func FoldsInline(format string) bool {
+	return IsTextFormat(format) || IsBioCFormat(format) || format == "chunks" || format == "es-bulk"
+}
+
+/*
+TextDecodeOptions returns the decoding options for formats that render prose.
+
+Parameters:
+  - lenient: Retry malformed XML with a non-strict decoder.
+  - stripCitations: Drop the <xref ref-type="bibr"> elements of paragraphs and titles,
+    leaving a mark that the text renderer removes with its brackets (--strip-citations).
+
+Behavior:
+  - Inline markup in paragraphs and titles is folded into their text, e.g.
+    "<p>see <italic>E. coli</italic></p>" decodes as "see E. coli".
+*/
+func TextDecodeOptions(lenient, stripCitations bool) xmlTools.DecodeOptions {
+	return xmlTools.DecodeOptions{
+		Lenient: lenient,
+		Tokens: func(r xml.TokenReader) xml.TokenReader {
+			return &inlineReader{r: r, stripCitations: stripCitations}
+		},
+	}
+}
+
+// inlineReader is an xml.TokenReader that drops the start and end tags of inline
+// elements nested in text elements, so their character data joins the parent's.
+type inlineReader struct {
+	r              xml.TokenReader
+	stripCitations bool
+
+	folded  []bool   // per open element: whether its tags were dropped
+	kept    []string // names of the open elements that were not dropped
+	dropped int      // depth inside a bibliographic <xref> being dropped
+}
+
+// Token returns the next token, skipping the tags of folded inline elements and,
+// with stripCitations, the whole of each citation.
+func (r *inlineReader) Token() (xml.Token, error) {
+	for {
+		tok, err := r.r.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if r.dropped > 0 {
+				r.dropped++
+				continue
+			}
+			if inlineElements[t.Name.Local] && len(r.kept) > 0 && textElements[r.kept[len(r.kept)-1]] {
+				if r.stripCitations && t.Name.Local == "xref" && refType(t) == "bibr" {
+					r.dropped = 1
+					return xml.CharData(citationMark), nil
+				}
+				r.folded = append(r.folded, true)
+				continue
+			}
+			r.folded = append(r.folded, false)
+			r.kept = append(r.kept, t.Name.Local)
+
+		case xml.EndElement:
+			if r.dropped > 0 {
+				r.dropped--
+				continue
+			}
+			if n := len(r.folded); n > 0 {
+				folded := r.folded[n-1]
+				r.folded = r.folded[:n-1]
+				if folded {
+					continue
+				}
+				r.kept = r.kept[:len(r.kept)-1]
+			}
+
+		default:
+			if r.dropped > 0 {
+				continue
+			}
+		}
+		return xml.CopyToken(tok), nil
+	}
+}
+
+// refType returns the ref-type attribute of an <xref>.
+func refType(t xml.StartElement) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == "ref-type" {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+package exportTools_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/exportTools"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+// textArticle is a PMC article with a nested section, a figure and a reference.
+var textArticle = &xmlTools.PMCArticle{
+	Front: xmlTools.PMCFront{ArticleMeta: xmlTools.PMCArticleMeta{
+		TitleGroup: xmlTools.PMCTitleGroup{ArticleTitle: "Testing software"},
+		Abstract:   &xmlTools.PMCAbstract{Paragraphs: []string{"We tested things [1]."}},
+	}},
+	Body: &xmlTools.PMCBody{Sections: []xmlTools.PMCSection{{
+		Title:      "Methods",
+		Paragraphs: []string{"As before (Smith et al., 2019; Lee 2020a), we did stuff [2, 3]."},
+		Figures: []xmlTools.PMCFigure{{
+			Label:   "Figure 1",
+			Caption: xmlTools.PMCCaption{Paragraphs: []string{"Study design."}},
+		}},
+		SubSections: []xmlTools.PMCSection{{Title: "Statistics", Paragraphs: []string{"We used R."}}},
+	}}},
+	Back: &xmlTools.PMCBack{References: &xmlTools.PMCReferences{References: []xmlTools.PMCReference{{
+		ID:              "r1",
+		ElementCitation: &xmlTools.PMCElementCitation{ArticleTitle: "Prior work", Source: "J Tests", Year: "2019"},
+	}}}},
+}
+
+//
+// ------------------------ Test: EncodeText ------------------------
+//
+
+// TestEncodeText checks headings, captions and the reference and citation options.
+func TestEncodeText(t *testing.T) {
+	tests := []struct {
+		name    string                  // Descriptive name for subtest
+		opts    exportTools.TextOptions // Rendering options
+		want    []string                // Substrings the output must contain
+		notWant []string                // Substrings the output must not contain
+	}{
+		{"plain text", exportTools.TextOptions{}, []string{
+			"Testing software\n================\n",
+			"1 Methods\n---------\n",
+			"\n1.1 Statistics\n",
+			"Figure 1. Study design.",
+			"References\n----------\n\n1. Prior work. J Tests. 2019.",
+			"we did stuff [2, 3].",
+		}, []string{"#"}},
+		{"markdown", exportTools.TextOptions{Markdown: true}, []string{
+			"# Testing software\n",
+			"## Methods\n",
+			"### Statistics\n",
+			"**Figure 1.** Study design.",
+		}, []string{"1.1"}},
+		{"without references", exportTools.TextOptions{DropReferences: true}, []string{
+			"We tested things [1].",
+		}, []string{"References", "Prior work"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := exportTools.EncodeText(&buf, textArticle, test.opts); err != nil {
+				t.Fatalf("EncodeText failed: %v", err)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, buf.String())
+				}
+			}
+			for _, notWant := range test.notWant {
+				if strings.Contains(buf.String(), notWant) {
+					t.Errorf("expected output not to contain %q, got:\n%s", notWant, buf.String())
+				}
+			}
+		})
+	}
+}
+
+// TestEncodeText_EmptyReferences verifies that a reference list in which no entry
+// renders a citation gets no heading.
+func TestEncodeText_EmptyReferences(t *testing.T) {
+	article := &xmlTools.PMCArticle{
+		Front: xmlTools.PMCFront{ArticleMeta: xmlTools.PMCArticleMeta{
+			TitleGroup: xmlTools.PMCTitleGroup{ArticleTitle: "Testing software"},
+		}},
+		Back: &xmlTools.PMCBack{References: &xmlTools.PMCReferences{References: []xmlTools.PMCReference{{ID: "r1"}}}},
+	}
+
+	var buf bytes.Buffer
+	if err := exportTools.EncodeText(&buf, article, exportTools.TextOptions{}); err != nil {
+		t.Fatalf("EncodeText failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "References") {
+		t.Errorf("expected no References heading, got:\n%s", buf.String())
+	}
+}
+
+//
+// ------------------------ Test: TextDecodeOptions ------------------------
+//
+
+// TestTextDecodeOptions verifies that inline markup is folded into the text and that
+// --strip-citations removes bibliographic <xref> elements with their brackets, while
+// other cross-references and literal brackets stay.
+func TestTextDecodeOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "article.xml")
+	doc := `<article><front><article-meta>
+<title-group><article-title>Effects of <italic>E. coli</italic> on H<sub>2</sub>O</article-title></title-group>
+</article-meta></front><body><sec><title>Intro</title>
+<p>Testing matters [<xref ref-type="bibr" rid="r1">1</xref>, <xref ref-type="bibr" rid="r2">2</xref>].</p>
+<p>As before (<xref ref-type="bibr" rid="r3">Smith et al., 2019</xref>), results<sup><xref ref-type="bibr" rid="r4">4</xref>–<xref ref-type="bibr" rid="r5">6</xref></sup> hold (see <xref ref-type="fig" rid="f1">Figure 1</xref>) at [3] mM.</p>
+</sec></body></article>`
+	if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+
+	tests := []struct {
+		name  string
+		strip bool     // --strip-citations
+		want  []string // Substrings the output must contain
+	}{
+		{"folded", false, []string{
+			"Effects of E. coli on H2O\n",
+			"Testing matters [1, 2].",
+			"As before (Smith et al., 2019), results4–6 hold",
+		}},
+		{"stripped", true, []string{
+			"Testing matters.",
+			"As before, results hold (see Figure 1) at [3] mM.",
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, _, err := xmlTools.ParsePubmedXMLWithOptions(path, exportTools.TextDecodeOptions(false, test.strip))
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := exportTools.EncodeText(&buf, data, exportTools.TextOptions{StripCitations: test.strip}); err != nil {
+				t.Fatalf("EncodeText failed: %v", err)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+// TestEncodeText_StructuredAbstract verifies that every section of a structured PubMed
+// abstract is decoded with its label and rendered as its own paragraph.
+func TestEncodeText_StructuredAbstract(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pubmed.xml")
+	doc := `<PubmedArticleSet><PubmedArticle><MedlineCitation><PMID>1</PMID><Article>
+<ArticleTitle>Title</ArticleTitle>
+<Abstract>
+<AbstractText Label="BACKGROUND" NlmCategory="BACKGROUND">Why <i>E. coli</i>.</AbstractText>
+<AbstractText Label="METHODS" NlmCategory="METHODS">How.</AbstractText>
+<AbstractText>Unlabeled.</AbstractText>
+</Abstract>
+</Article></MedlineCitation></PubmedArticle></PubmedArticleSet>`
+	if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+
+	data, _, err := xmlTools.ParsePubmedXMLWithOptions(path, exportTools.TextDecodeOptions(false, false))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	sections := data.(*xmlTools.PubmedArticleSet).PubmedArticles[0].MedlineCitation.Article.Abstract.AbstractText
+	if len(sections) != 3 || sections[1].Label != "METHODS" || sections[1].NlmCategory != "METHODS" {
+		t.Fatalf("expected 3 sections with labels, got %+v", sections)
+	}
+
+	var buf bytes.Buffer
+	if err := exportTools.EncodeText(&buf, data, exportTools.TextOptions{}); err != nil {
+		t.Fatalf("EncodeText failed: %v", err)
+	}
+	want := "BACKGROUND: Why E. coli.\n\nMETHODS: How.\n\nUnlabeled.\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected output to contain %q, got:\n%s", want, buf.String())
+	}
+}
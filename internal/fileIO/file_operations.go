@@ -35,12 +35,18 @@ func MakeFile(path string) error {
 //
 // It converts each input file’s base name to a .json extension and appends it to outputDir.
 func GenerateJSONFilePaths(inputFiles []string, outputDir string) ([]string, error) {
+	return GenerateOutputFilePaths(inputFiles, outputDir, "json")
+}
+
+// GenerateOutputFilePaths is GenerateJSONFilePaths for an arbitrary output extension
+// (e.g. "jsonl"), as returned by OutputExtension.
+func GenerateOutputFilePaths(inputFiles []string, outputDir, ext string) ([]string, error) {
 	var outputPaths []string
 
 	for _, inputFile := range inputFiles {
 		// Extract just the filename (e.g., "article.xml" → "article.json")
 		base := filepath.Base(inputFile)
-		jsonFile := ChangeExtension(base, "json")
+		jsonFile := ChangeExtension(base, ext)
 
 		// Create full path in the output directory
 		outputPath := filepath.Join(outputDir, jsonFile)
@@ -50,6 +56,54 @@ func GenerateJSONFilePaths(inputFiles []string, outputDir string) ([]string, err
 	return outputPaths, nil
 }
 
+// OutputExtension returns the file extension used for an output format.
+//
+// An empty format means the default JSON output.
+func OutputExtension(format string) string {
+	switch format {
+	case "", "json":
+		return "json"
+	case "bibtex":
+		return "bib"
+	case "csl-json":
+		return "csl.json"
+	case "text":
+		return "txt"
+	case "markdown":
+		return "md"
+	case "chunks":
+		return "chunks.jsonl"
+	case "bioc-xml":
+		return "bioc.xml"
+	case "bioc-json":
+		return "bioc.json"
+	case "es-bulk":
+		return "bulk.ndjson"
+	default:
+		return format
+	}
+}
+
+// AggregateFormat reports whether an output format collects all inputs into shared
+// files in the output directory (e.g. the relational TSV tables) rather than writing
+// one output file per input.
+func AggregateFormat(format string) bool {
+	return format == "tsv" || format == "sqlite"
+}
+
+// DefaultDatabaseName is the SQLite file created when -o names a directory.
+const DefaultDatabaseName = "pubparse.db"
+
+// IsDatabasePath reports whether an output path names a SQLite database file
+// (.db, .sqlite or .sqlite3) rather than an output directory.
+func IsDatabasePath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".db", ".sqlite", ".sqlite3":
+		return true
+	}
+	return false
+}
+
 // ChangeExtension replaces the file extension of a given path with a new one.
 //
 // If the path has no extension, the new extension is simply appended.
@@ -104,3 +104,37 @@ func TestChangeExtension(t *testing.T) {
 		})
 	}
 }
+
+//
+// ------------------------ Test: GenerateOutputFilePaths ------------------------
+//
+
+// TestGenerateOutputFilePaths verifies that output paths use the extension
+// of the selected output format.
+func TestGenerateOutputFilePaths(t *testing.T) {
+	inputs := []string{"/data/a.xml", "/data/b.xml"}
+
+	tests := []struct {
+		format   string // Output format passed to OutputExtension
+		expected string // Expected path of the first output
+	}{
+		{"", filepath.Join("/out", "a.json")},
+		{"json", filepath.Join("/out", "a.json")},
+		{"jsonl", filepath.Join("/out", "a.jsonl")},
+	}
+
+	for _, test := range tests {
+		t.Run("format "+test.format, func(t *testing.T) {
+			paths, err := fileIO.GenerateOutputFilePaths(inputs, "/out", fileIO.OutputExtension(test.format))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(paths) != len(inputs) {
+				t.Fatalf("expected %d paths, got %d", len(inputs), len(paths))
+			}
+			if paths[0] != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, paths[0])
+			}
+		})
+	}
+}
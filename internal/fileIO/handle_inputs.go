@@ -3,13 +3,15 @@ package fileIO
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 )
 
 // HandleInputs is the main entry point for processing input file arguments.
 //
 // It performs the following:
 //  1. Resolves and validates the user-provided input path.
-//  2. Loads all valid `.xml` files from the input path (either a directory or single file).
+//  2. Loads all valid `.xml` files (or args.InputExts files) from the input path
+//     (either a directory or single file).
 //
 // On success, it populates the InputPath field in `args` with:
 //   - Absolute path
@@ -58,16 +60,28 @@ func validateInputPath(args *Arguments) error {
 	return nil
 }
 
-// populateInputFiles loads the list of `.xml` files from the provided input path.
+// populateInputFiles loads the list of `.xml` files (or args.InputExts files) from the provided input path.
+//
+//   - If the input is a `.txt` file, it loads the files listed in it (see LoadFileList),
+//     e.g. the failures.txt of a --continue-on-error run.
+//   - If the input is another single file, it wraps it in a list.
+//   - If the input is a directory, it filters and loads all `.xml` files.
 //
-// - If the input is a single file, it wraps it in a list.
-// - If the input is a directory, it filters and loads all `.xml` files.
 // On success, it updates args.InputPath.Files.
 func populateInputFiles(args *Arguments) error {
 	var err error
 
-	// Load valid XML files (or the single file itself)
-	args.InputPath, err = LoadFilesInDir(args.InputPath, "xml")
+	exts := args.InputExts
+	if len(exts) == 0 {
+		exts = []string{"xml"}
+	}
+
+	// Load the listed files, valid XML files in the directory, or the single file itself
+	if !args.InputPath.Info.IsDir() && strings.EqualFold(filepath.Ext(args.InputPath.Path), ".txt") && exts[0] != "txt" {
+		args.InputPath, err = LoadFileList(args.InputPath, exts...)
+	} else {
+		args.InputPath, err = LoadFilesInDir(args.InputPath, exts...)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to load input files from %q: %w", args.InputPath.Path, err)
 	}
@@ -11,9 +11,16 @@ import (
 //
 // It performs the following steps:
 //  1. Determines the appropriate output directory (user-defined or auto-generated).
+//     For --format sqlite, a -o ending in .db/.sqlite/.sqlite3 names the database file
+//     and its directory becomes the output directory.
 //  2. Ensures the output directory exists (or creates it).
-//  3. Generates one-to-one output .json file paths corresponding to the input files.
-//  4. Verifies write access by attempting to create each file.
+//  3. Generates one-to-one output file paths corresponding to the input files,
+//     with the extension of the selected output format (.json, .jsonl).
+//  4. Rejects inputs that map to the same output file, e.g. a/x.xml and b/x.xml
+//     named by a .txt list, then verifies write access by attempting to create each file
+//     (skipped in split mode, where output names depend on the PMIDs inside each input,
+//     and for aggregate formats, which write shared files instead). With
+//     args.Incremental, existing outputs are kept (see CheckWriteAccess).
 //  5. Captures metadata about the output directory.
 //
 // The resulting output paths are stored in args.OutputPath.
@@ -33,20 +40,39 @@ func HandleOutputs(args *Arguments) error {
 		}
 	}
 
+	// Step 1b: SQLite writes one database; -o may name the file itself
+	if args.Format == "sqlite" {
+		if IsDatabasePath(args.OutputPath.Path) {
+			args.OutputFile = args.OutputPath.Path
+			args.OutputPath.Path = filepath.Dir(args.OutputPath.Path)
+		} else {
+			args.OutputFile = filepath.Join(args.OutputPath.Path, DefaultDatabaseName)
+		}
+	}
+
 	// Step 2: Ensure that the output directory exists
 	if err := EnsureDir(args.OutputPath.Path); err != nil {
 		return err
 	}
 
-	// Step 3: Create full paths for each output .json file
-	outputFiles, err := GenerateJSONFilePaths(args.InputPath.Files, args.OutputPath.Path)
+	// Step 3: Create full paths for each output file
+	outputFiles, err := GenerateOutputFilePaths(args.InputPath.Files, args.OutputPath.Path, OutputExtension(args.Format))
 	if err != nil {
 		return err
 	}
 
-	// Step 4: Ensure we can create/write each output file
-	if err := VerifyWriteAccess(outputFiles); err != nil {
-		return err
+	// Step 4: Ensure each input has its own output file and we can create/write it
+	if !args.Split && !AggregateFormat(args.Format) {
+		if err := checkDistinctOutputs(args.InputPath.Files, outputFiles); err != nil {
+			return err
+		}
+		check := VerifyWriteAccess
+		if args.Incremental {
+			check = CheckWriteAccess
+		}
+		if err := check(outputFiles); err != nil {
+			return err
+		}
 	}
 
 	// Step 5: Get info about the output directory itself
@@ -117,3 +143,38 @@ func VerifyWriteAccess(paths []string) error {
 	}
 	return nil
 }
+
+// checkDistinctOutputs returns an error naming both inputs when two inputs would be
+// written to the same output file, which happens when inputs from different
+// directories share a base name; the second would silently overwrite the first.
+func checkDistinctOutputs(inputs, outputs []string) error {
+	owner := map[string]string{}
+	for i, out := range outputs {
+		if first, ok := owner[out]; ok {
+			return fmt.Errorf("inputs %q and %q would both be written to %q; rename one or convert them separately", first, inputs[i], out)
+		}
+		owner[out] = inputs[i]
+	}
+	return nil
+}
+
+// CheckWriteAccess verifies write access like VerifyWriteAccess, but without
+// truncating files that already exist, so that outputs still current survive an
+// incremental run. Missing files are created empty.
+func CheckWriteAccess(paths []string) error {
+	for _, p := range paths {
+		// Ensure parent directory exists
+		dir := filepath.Dir(p)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("cannot create subdirectory for %q: %w", p, err)
+		}
+
+		// Open for writing, keeping any content
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE, 0644)
+		if err != nil {
+			return fmt.Errorf("cannot create output file %q: %w", p, err)
+		}
+		f.Close()
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package fileIO_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -110,6 +111,27 @@ func TestVerifyWriteAccess_CreatesFiles(t *testing.T) {
 	}
 }
 
+// TestCheckWriteAccess_KeepsContent verifies that CheckWriteAccess creates missing
+// files but leaves existing outputs intact.
+func TestCheckWriteAccess_KeepsContent(t *testing.T) {
+	tmp := t.TempDir()
+	existing := filepath.Join(tmp, "out1.json")
+	missing := filepath.Join(tmp, "sub", "out2.json")
+	if err := os.WriteFile(existing, []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", existing, err)
+	}
+
+	if err := fileIO.CheckWriteAccess([]string{existing, missing}); err != nil {
+		t.Fatalf("CheckWriteAccess failed: %v", err)
+	}
+	if content, _ := os.ReadFile(existing); string(content) != "{}" {
+		t.Errorf("expected existing content to be kept, got %q", content)
+	}
+	if _, err := os.Stat(missing); err != nil {
+		t.Errorf("expected file not created: %s", missing)
+	}
+}
+
 //
 // ---------------------- HandleOutputs ----------------------
 //
@@ -158,6 +180,36 @@ func TestHandleOutputs_Success(t *testing.T) {
 	}
 }
 
+// TestHandleOutputs_DuplicateOutputs verifies that inputs from different directories
+// with the same base name, as a .txt list can name them, are rejected before anything
+// is written, except in split mode where outputs are named by PMID.
+func TestHandleOutputs_DuplicateOutputs(t *testing.T) {
+	tmpDir := t.TempDir()
+	first := filepath.Join(tmpDir, "a", "x.xml")
+	second := filepath.Join(tmpDir, "b", "x.xml")
+
+	for _, split := range []bool{false, true} {
+		outDir := filepath.Join(tmpDir, fmt.Sprintf("out_%t", split))
+		args := &fileIO.Arguments{
+			InputPath:  fileIO.PathInfo{Path: tmpDir, Files: []string{first, second}},
+			OutputPath: fileIO.PathInfo{Path: outDir},
+			Split:      split,
+		}
+
+		err := fileIO.HandleOutputs(args)
+		switch {
+		case split && err != nil:
+			t.Errorf("split: unexpected error: %v", err)
+		case !split && (err == nil || !strings.Contains(err.Error(), first) || !strings.Contains(err.Error(), second)):
+			t.Errorf("expected an error naming %s and %s, got %v", first, second, err)
+		case !split:
+			if _, err := os.Stat(filepath.Join(outDir, "x.json")); !os.IsNotExist(err) {
+				t.Errorf("expected no output file to be created, got %v", err)
+			}
+		}
+	}
+}
+
 // TestHandleOutputs_InvalidOutputPath simulates a failure scenario where the
 // input path is invalid or inaccessible. It should return an error.
 func TestHandleOutputs_InvalidOutputPath(t *testing.T) {
@@ -5,12 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/ashahide/pubparse/internal/customErrors"
 )
 
 // LoadFilesInDir inspects a given PathInfo and populates the .Files field
-// with valid files that match the specified extension (e.g., "xml").
+// with valid files that match one of the specified extensions (e.g., "xml").
 //
 // Behavior:
 //   - If the path is a single file, it wraps that file into the result.
@@ -21,12 +22,12 @@ import (
 //
 // Arguments:
 //   - dirInfo: PathInfo containing the path and metadata (os.FileInfo).
-//   - desiredTypeExt: Extension to match, e.g., "xml".
+//   - exts: Extensions to match, e.g., "xml" or "json", "jsonl".
 //
 // Returns:
 //   - Updated PathInfo with .Files populated with full file paths.
 //   - Error if path is invalid, unreadable, or no matching files are found.
-func LoadFilesInDir(dirInfo PathInfo, desiredTypeExt string) (PathInfo, error) {
+func LoadFilesInDir(dirInfo PathInfo, exts ...string) (PathInfo, error) {
 	// If the input is a single file, treat it as a one-element list
 	if !dirInfo.Info.IsDir() {
 		_, err := os.Stat(dirInfo.Path)
@@ -53,9 +54,8 @@ func LoadFilesInDir(dirInfo PathInfo, desiredTypeExt string) (PathInfo, error) {
 		// Construct full path to each entry
 		fullPath := filepath.Join(dirInfo.Path, entry.Name())
 
-		// Check if the path has the desired extension (e.g., ".xml")
-		_, err := VerifyPath(fullPath, desiredTypeExt)
-		if err != nil {
+		// Check if the path has a desired extension (e.g., ".xml")
+		if err := verifyAnyExt(fullPath, exts); err != nil {
 			// Skip files with wrong extensions without failing
 			var extErr *customErrors.WrongExtensionError
 			if errors.As(err, &extErr) {
@@ -71,3 +71,118 @@ func LoadFilesInDir(dirInfo PathInfo, desiredTypeExt string) (PathInfo, error) {
 
 	return dirInfo, nil
 }
+
+//
+// ------------------------ LoadFilesInTree ------------------------
+//
+
+// LoadFilesInTree is LoadFilesInDir for a whole directory tree and several extensions,
+// used to find existing outputs in nested archive directories.
+//
+// Behavior:
+//   - A single file is returned as is, whatever its extension.
+//   - Directories are walked recursively in lexical order; files with other
+//     extensions are skipped.
+//
+// Arguments:
+//   - dirInfo: PathInfo containing the path and metadata (os.FileInfo).
+//   - exts: Extensions to match, e.g. "json", "jsonl".
+//
+// Returns:
+//   - Updated PathInfo with .Files populated with full file paths.
+//   - Error if the tree cannot be read or no matching files are found.
+func LoadFilesInTree(dirInfo PathInfo, exts ...string) (PathInfo, error) {
+	if !dirInfo.Info.IsDir() {
+		dirInfo.Files = append(dirInfo.Files, dirInfo.Path)
+		return dirInfo, nil
+	}
+
+	err := filepath.WalkDir(dirInfo.Path, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		for _, want := range exts {
+			if ext == strings.TrimPrefix(strings.ToLower(want), ".") {
+				dirInfo.Files = append(dirInfo.Files, path)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return dirInfo, fmt.Errorf("could not read directory %q: %w", dirInfo.Path, err)
+	}
+
+	if len(dirInfo.Files) == 0 {
+		return dirInfo, fmt.Errorf("no .%s files found in directory: %s", strings.Join(exts, " or ."), dirInfo.Path)
+	}
+	return dirInfo, nil
+}
+
+//
+// ------------------------ LoadFileList ------------------------
+//
+
+// LoadFileList populates the .Files field from a text file listing one input per line,
+// such as the failures.txt written by a --continue-on-error run.
+//
+// Behavior:
+//   - Blank lines and lines starting with "#" are ignored.
+//   - Relative paths are resolved against the directory of the list.
+//   - Every listed file must exist and have one of the extensions; unlike
+//     LoadFilesInDir, a mismatch is an error, since the file was named explicitly.
+//
+// Arguments:
+//   - listInfo: PathInfo of the list file.
+//   - exts: Extensions to match, e.g., "xml".
+//
+// Returns:
+//   - Updated PathInfo with .Files populated with absolute file paths, in list order.
+//   - Error if the list cannot be read, a listed file is invalid or the list is empty.
+func LoadFileList(listInfo PathInfo, exts ...string) (PathInfo, error) {
+	content, err := os.ReadFile(listInfo.Path)
+	if err != nil {
+		return listInfo, fmt.Errorf("could not read file list %q: %w", listInfo.Path, err)
+	}
+
+	for n, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		path := line
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(listInfo.Path), path)
+		}
+		if err := verifyAnyExt(path, exts); err != nil {
+			return listInfo, fmt.Errorf("%s line %d: %w", listInfo.Path, n+1, err)
+		}
+		listInfo.Files = append(listInfo.Files, path)
+	}
+
+	if len(listInfo.Files) == 0 {
+		return listInfo, fmt.Errorf("no files listed in %s", listInfo.Path)
+	}
+	return listInfo, nil
+}
+
+// verifyAnyExt is VerifyPath for a file that may have any of exts. A file matching
+// none of them gets the WrongExtensionError of the last one.
+func verifyAnyExt(path string, exts []string) error {
+	if len(exts) == 0 {
+		_, err := VerifyPath(path, "")
+		return err
+	}
+	var err error
+	for _, ext := range exts {
+		var extErr *customErrors.WrongExtensionError
+		if _, err = VerifyPath(path, ext); !errors.As(err, &extErr) {
+			return err
+		}
+	}
+	return err
+}
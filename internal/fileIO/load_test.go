@@ -3,6 +3,7 @@ package fileIO_test
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/ashahide/pubparse/internal/fileIO"
@@ -91,6 +92,31 @@ func TestLoadFilesInDir_MixedExtensions(t *testing.T) {
 	}
 }
 
+// TestLoadFilesInDir_SeveralExtensions ensures that LoadFilesInDir loads the
+// files matching any of several extensions, in directory order.
+func TestLoadFilesInDir_SeveralExtensions(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	os.WriteFile(filepath.Join(tmpDir, "a.json"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "b.jsonl"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "c.xml"), []byte("<ok/>"), 0644)
+
+	info, _ := os.Stat(tmpDir)
+	input := fileIO.PathInfo{
+		Path: tmpDir,
+		Info: info,
+	}
+
+	result, err := fileIO.LoadFilesInDir(input, "json", "jsonl")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	expected := []string{filepath.Join(tmpDir, "a.json"), filepath.Join(tmpDir, "b.jsonl")}
+	if !reflect.DeepEqual(result.Files, expected) {
+		t.Errorf("expected %v, got %v", expected, result.Files)
+	}
+}
+
 // TestLoadFilesInDir_EmptyDir confirms that LoadFilesInDir returns an error
 // when provided a directory that contains no files.
 func TestLoadFilesInDir_EmptyDir(t *testing.T) {
@@ -123,3 +149,87 @@ func TestLoadFilesInDir_InvalidPath(t *testing.T) {
 		t.Error("expected error on invalid path, got nil")
 	}
 }
+
+//
+// ------------------------ LoadFilesInTree Tests ------------------------
+//
+
+// TestLoadFilesInTree_Nested verifies that LoadFilesInTree walks subdirectories and
+// keeps only files with one of the requested extensions.
+func TestLoadFilesInTree_Nested(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]bool{ // Relative path -> expected in the result
+		"a.json":             true,
+		"2024/b.jsonl":       true,
+		"2024/01/c.JSON":     true,
+		"2024/report.tsv":    false,
+		"2024/01/input.xml":  false,
+		"quarantine/d.jsonl": true,
+	}
+	for name := range files {
+		path := filepath.Join(tmpDir, name)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		_ = os.WriteFile(path, []byte("{}"), 0644)
+	}
+
+	info, _ := os.Stat(tmpDir)
+	result, err := fileIO.LoadFilesInTree(fileIO.PathInfo{Path: tmpDir, Info: info}, "json", "jsonl")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, path := range result.Files {
+		rel, _ := filepath.Rel(tmpDir, path)
+		got[filepath.ToSlash(rel)] = true
+	}
+	for name, want := range files {
+		if got[name] != want {
+			t.Errorf("%s: expected loaded=%v", name, want)
+		}
+	}
+}
+
+// TestLoadFilesInTree_NoMatches verifies that a tree without matching files is an error.
+func TestLoadFilesInTree_NoMatches(t *testing.T) {
+	tmpDir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(tmpDir, "input.xml"), []byte("<xml/>"), 0644)
+
+	info, _ := os.Stat(tmpDir)
+	if _, err := fileIO.LoadFilesInTree(fileIO.PathInfo{Path: tmpDir, Info: info}, "json", "jsonl"); err == nil {
+		t.Error("expected an error for a tree without .json/.jsonl files")
+	}
+}
+
+//
+// ------------------------ LoadFileList Tests ------------------------
+//
+
+// TestLoadFileList verifies that a .txt list loads its files in order, resolving
+// relative paths against the list and ignoring blank and comment lines.
+func TestLoadFileList(t *testing.T) {
+	tmpDir := t.TempDir()
+	abs := filepath.Join(tmpDir, "a.xml")
+	_ = os.MkdirAll(filepath.Join(tmpDir, "sub"), 0755)
+	_ = os.WriteFile(abs, []byte("<xml/>"), 0644)
+	_ = os.WriteFile(filepath.Join(tmpDir, "sub", "b.xml"), []byte("<xml/>"), 0644)
+
+	list := filepath.Join(tmpDir, "failures.txt")
+	_ = os.WriteFile(list, []byte("# failed inputs\nsub/b.xml\n\n"+abs+"\n"), 0644)
+	info, _ := os.Stat(list)
+
+	result, err := fileIO.LoadFileList(fileIO.PathInfo{Path: list, Info: info}, "xml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{filepath.Join(tmpDir, "sub", "b.xml"), abs}
+	if len(result.Files) != 2 || result.Files[0] != want[0] || result.Files[1] != want[1] {
+		t.Errorf("expected %v, got %v", want, result.Files)
+	}
+
+	// A listed file that is missing is an error
+	_ = os.WriteFile(list, []byte("missing.xml\n"), 0644)
+	if _, err := fileIO.LoadFileList(fileIO.PathInfo{Path: list, Info: info}, "xml"); err == nil {
+		t.Error("expected an error for a missing listed file")
+	}
+}
@@ -1,10 +1,52 @@
 package fileIO
 
-import "os"
+import (
+	"os"
+
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
 
 type Arguments struct {
 	InputPath  PathInfo
+	InputExts  []string // Extensions of the input files to load; "xml" when empty
 	OutputPath PathInfo
+	Format     string // Output format: "json" (default), "jsonl", "parquet", "tsv", "sqlite", "bibtex", "ris", "csl-json", "text", "markdown", "chunks", "bioc-xml", "bioc-json" or "es-bulk"
+	OutputFile string // Single output file of aggregate formats (the SQLite database)
+	Lenient    bool   // Retry malformed XML with a non-strict decoder
+
+	// Split writes each PubMed article to <pmid>.json instead of one file per input;
+	// SplitVersions names them <pmid>.v<version>.json.
+	Split         bool
+	SplitVersions bool
+
+	// DropReferences omits the reference list and StripCitations removes in-text
+	// citation markers from --format text and markdown output.
+	DropReferences bool
+	StripCitations bool
+
+	// ChunkSize bounds --format chunks output, in ChunkUnit ("chars" or "tokens").
+	ChunkSize int
+	ChunkUnit string
+
+	IndexName string // Index named in --format es-bulk actions and its mapping file
+
+	Projection *xmlTools.Projection // Fields selected by --fields / --exclude; nil keeps all
+	LegacyKeys bool                 // Write pre-2.0 JSON keys (Go field names) instead of snake_case
+	OnInvalid  string               // Policy for documents failing schema validation: "fail", "warn" or "quarantine"
+
+	// Profile names a bundled validation profile and SchemaPath a user schema file or
+	// directory; both add to (or replace) the bundled schemas.
+	Profile    string
+	SchemaPath string
+
+	DisabledRules []string // Semantic rules skipped by --disable-rules; "all" skips every rule
+
+	ContinueOnError bool // Process every input, recording per-input status and failures.txt
+
+	// Incremental skips inputs whose outputs are current according to the content
+	// manifest (--incremental / --resume), and keeps existing outputs instead of
+	// truncating them up front.
+	Incremental bool
 }
 
 type PathInfo struct {
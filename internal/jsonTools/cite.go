@@ -0,0 +1,197 @@
+package jsonTools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/ashahide/pubparse/internal/exportTools"
+	"github.com/ashahide/pubparse/internal/fileIO"
+	"github.com/ashahide/pubparse/internal/makeReports"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ LoadArticlesJSON ------------------------
+//
+
+/*
+LoadArticlesJSON reads pubparse JSON or JSON Lines output back into xmlTools structures.
+
+Parameters:
+  - path: A .json document (--format json or --split output) or a .jsonl file.
+
+Returns:
+  - One value per JSON document in the file: *xmlTools.PubmedArticleSet,
+    *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle. Single PubMed articles
+    (split and JSON Lines output) are wrapped in a one-article set.
+  - An error if the file cannot be read or a document is not recognized. It wraps
+    errUnrecognizedDocument only when the first document is not recognized, i.e.
+    when the file is not pubparse output at all.
+*/
+func LoadArticlesJSON(path string) ([]interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	var docs []interface{}
+	dec := json.NewDecoder(bytes.NewReader(content))
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid JSON in %q: %w", path, err)
+		}
+
+		doc, err := decodeArticleJSON(raw)
+		if err != nil && len(docs) > 0 && errors.Is(err, errUnrecognizedDocument) {
+			return nil, fmt.Errorf("%q: document %d: %v", path, len(docs)+1, err)
+		} else if err != nil {
+			return nil, fmt.Errorf("%q: %w", path, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// decodeArticleJSON recognizes a document by its top-level keys and decodes it,
+// wrapping single PubMed (book) articles in a one-article set.
+func decodeArticleJSON(raw json.RawMessage) (interface{}, error) {
+	doc, _, _, err := decodeDocumentJSON(raw)
+	switch article := doc.(type) {
+	case *xmlTools.PubmedArticle:
+		return &xmlTools.PubmedArticleSet{PubmedArticles: []xmlTools.PubmedArticle{*article}}, err
+	case *xmlTools.PubmedBookArticle:
+		return &xmlTools.PubmedBookArticleSet{PubmedBookArticles: []xmlTools.PubmedBookArticle{*article}}, err
+	}
+	return doc, err
+}
+
+// errUnrecognizedDocument marks JSON that is not a pubparse document, e.g. CSL-JSON or BioC output.
+var errUnrecognizedDocument = errors.New("unrecognized pubparse JSON document")
+
+/*
+decodeDocumentJSON recognizes a document by its top-level keys and decodes it as written.
+
+Returns:
+  - doc: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet, *xmlTools.PubmedArticle,
+    *xmlTools.PubmedBookArticle or *xmlTools.PMCArticle.
+  - schemaRef: The bundled schema reference the document was validated against when written.
+  - legacy: Whether the document uses the pre-2.0 keys (--legacy-keys); both key styles are accepted.
+  - err: errUnrecognizedDocument for well-formed JSON that is not a pubparse document,
+    or a syntax or decoding error.
+*/
+func decodeDocumentJSON(raw json.RawMessage) (doc interface{}, schemaRef string, legacy bool, err error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &keys); err != nil {
+		// Well-formed JSON of another shape (e.g. a CSL-JSON array) is someone else's
+		// output; malformed or truncated JSON is a broken document
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, "", false, fmt.Errorf("%w: expected a JSON object, got %s", errUnrecognizedDocument, typeErr.Value)
+		}
+		return nil, "", false, fmt.Errorf("invalid JSON: %w", err)
+	}
+	for _, key := range []string{"PubmedArticles", "PubmedBookArticles", "MedlineCitation", "BookDocument", "Front"} {
+		legacy = legacy || keys[key] != nil
+	}
+	has := func(snake, legacyKey string) bool {
+		return keys[snake] != nil || keys[legacyKey] != nil
+	}
+	decode := func(v interface{}) error {
+		content := []byte(raw)
+		if legacy {
+			var err error
+			if content, err = xmlTools.FromLegacyKeys(raw, v); err != nil {
+				return err
+			}
+		}
+		return json.Unmarshal(content, v)
+	}
+
+	switch {
+	case has("pubmed_articles", "PubmedArticles"):
+		doc, schemaRef = &xmlTools.PubmedArticleSet{}, PubmedSchema
+	case has("pubmed_book_articles", "PubmedBookArticles"):
+		doc, schemaRef = &xmlTools.PubmedBookArticleSet{}, PubmedSchema
+	case has("medline_citation", "MedlineCitation"):
+		doc, schemaRef = &xmlTools.PubmedArticle{}, PubmedSchema+"#/definitions/PubmedArticle"
+	case has("book_document", "BookDocument"):
+		doc, schemaRef = &xmlTools.PubmedBookArticle{}, PubmedSchema+"#/definitions/PubmedBookArticle"
+	case has("front", "Front"):
+		doc, schemaRef = &xmlTools.PMCArticle{}, PMCSchema
+	default:
+		return nil, "", false, errUnrecognizedDocument
+	}
+	return doc, schemaRef, legacy, decode(doc)
+}
+
+//
+// ------------------------ CiteAllFiles ------------------------
+//
+
+/*
+CiteAllFiles converts existing pubparse JSON output into citation files.
+
+Parameters:
+  - args: Input JSON files, matching output paths and the citation format.
+  - report: Open report file handle for logging.
+
+Behavior:
+  - Each input file becomes one citation file holding all of its articles.
+  - JSON that is not pubparse output (CSL-JSON, BioC, es-bulk mappings) is skipped
+    and reported, as by validate.
+  - Files are processed sequentially; citation rendering is cheap compared to XML parsing.
+
+Returns:
+  - The first error encountered, or nil.
+*/
+func CiteAllFiles(args fileIO.Arguments, report *os.File) error {
+	var mu sync.Mutex
+
+	for i, fin := range args.InputPath.Files {
+		fout := args.OutputPath.Files[i]
+
+		docs, err := LoadArticlesJSON(fin)
+		if err != nil {
+			// Leave no empty pre-created output behind for a skipped or failed input
+			os.Remove(fout)
+		}
+		if errors.Is(err, errUnrecognizedDocument) {
+			if report != nil {
+				if err := makeReports.WriteNotArticlesToReport(report, &mu, fin); err != nil {
+					return fmt.Errorf("failed to write to report: %w", err)
+				}
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		var records []exportTools.ArticleRecord
+		for _, doc := range docs {
+			flat, err := exportTools.FlattenArticles(doc)
+			if err != nil {
+				return fmt.Errorf("failed to read articles from %q: %w", fin, err)
+			}
+			records = append(records, flat...)
+		}
+
+		if err := exportTools.WriteCitationRecords(records, fout, args.Format); err != nil {
+			return fmt.Errorf("failed to write citations for %q: %w", fin, err)
+		}
+
+		if report != nil {
+			if err := makeReports.WriteToReport(report, &mu, fin, fout); err != nil {
+				return fmt.Errorf("failed to write to report: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package jsonTools_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/fileIO"
+	"github.com/ashahide/pubparse/internal/jsonTools"
+)
+
+//
+// ------------------------ Test: CiteAllFiles ------------------------
+//
+
+// citeArticle is a single PubMed article as written to JSON Lines.
+func citeArticle(pmid string) string {
+	return `{"schema_version":"3.0","medline_citation":{"pmid":"` + pmid + `","article":{"article_title":"Title ` + pmid + `"}}}`
+}
+
+// TestCiteAllFiles verifies that a directory input is searched for .json and .jsonl
+// output, that other JSON in it is skipped and reported, and that a file with a foreign
+// document after its articles still fails.
+func TestCiteAllFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string // Input file name -> content
+		outputs map[string]int    // Output file name -> expected number of RIS records
+		skipped int               // Expected ">>> Not pubparse output:" lines
+		err     bool              // Expect CiteAllFiles to fail
+	}{
+		{
+			name: "json, jsonl and foreign json",
+			files: map[string]string{
+				"set.json":        `{"schema_version":"3.0","pubmed_articles":[{"medline_citation":{"pmid":"1","article":{"article_title":"Title 1"}}}]}`,
+				"lines.jsonl":     citeArticle("2") + "\n" + citeArticle("3") + "\n",
+				"es.mapping.json": `{"mappings":{"properties":{"pmid":{"type":"keyword"}}}}`,
+				"refs.csl.json":   `[{"id":"pmid:1","type":"article-journal"}]`,
+				"set.bioc.json":   `{"source":"PubMed","documents":[]}`,
+				"notes.txt":       "ignored",
+			},
+			outputs: map[string]int{"set.ris": 1, "lines.ris": 2},
+			skipped: 3,
+		},
+		{
+			name:  "foreign document after articles",
+			files: map[string]string{"mixed.jsonl": citeArticle("1") + "\n" + `{"chunk_id":"x"}` + "\n"},
+			err:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inDir, outDir := t.TempDir(), t.TempDir()
+			for name, content := range test.files {
+				if err := os.WriteFile(filepath.Join(inDir, name), []byte(content), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", name, err)
+				}
+			}
+
+			args := fileIO.Arguments{Format: "ris", InputExts: []string{"json", "jsonl"}}
+			args.InputPath.Path = inDir
+			args.OutputPath.Path = outDir
+			if err := fileIO.HandleInputs(&args); err != nil {
+				t.Fatalf("HandleInputs failed: %v", err)
+			}
+			if err := fileIO.HandleOutputs(&args); err != nil {
+				t.Fatalf("HandleOutputs failed: %v", err)
+			}
+
+			report, err := os.Create(filepath.Join(outDir, "report.tsv"))
+			if err != nil {
+				t.Fatalf("failed to create report: %v", err)
+			}
+			defer report.Close()
+
+			err = jsonTools.CiteAllFiles(args, report)
+			if (err != nil) != test.err {
+				t.Fatalf("expected error: %t, got %v", test.err, err)
+			}
+
+			entries, _ := os.ReadDir(outDir)
+			var names []string
+			for _, entry := range entries {
+				if strings.HasSuffix(entry.Name(), ".ris") {
+					names = append(names, entry.Name())
+				}
+			}
+			if len(names) != len(test.outputs) {
+				t.Errorf("expected outputs %v, got %v", test.outputs, names)
+			}
+			for name, records := range test.outputs {
+				content, err := os.ReadFile(filepath.Join(outDir, name))
+				if err != nil {
+					t.Errorf("expected %s to be written: %v", name, err)
+					continue
+				}
+				if got := strings.Count(string(content), "ER  - "); got != records {
+					t.Errorf("expected %d records in %s, got %d:\n%s", records, name, got, content)
+				}
+			}
+
+			content, _ := os.ReadFile(report.Name())
+			if got := strings.Count(string(content), ">>> Not pubparse output: "); got != test.skipped {
+				t.Errorf("expected %d skipped files in the report, got %d:\n%s", test.skipped, got, content)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package jsonTools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+//
+// ------------------------ Invalid documents ------------------------
+//
+
+// InvalidPolicies are the --on-invalid choices for documents that fail schema validation:
+//   - "fail": write nothing and fail the input (default).
+//   - "warn": write the document where it belongs and record the violations.
+//   - "quarantine": write the document to QuarantineDir next to its output and record the violations.
+var InvalidPolicies = []string{"fail", "warn", "quarantine"}
+
+// QuarantineDir is the subdirectory of the output directory receiving invalid
+// documents under --on-invalid quarantine.
+const QuarantineDir = "quarantine"
+
+// IsInvalidPolicy reports whether policy is one of InvalidPolicies.
+func IsInvalidPolicy(policy string) bool {
+	for _, p := range InvalidPolicies {
+		if policy == p {
+			return true
+		}
+	}
+	return false
+}
+
+// keepsInvalid reports whether policy writes invalid documents instead of failing the input.
+func keepsInvalid(policy string) bool {
+	return policy == "warn" || policy == "quarantine"
+}
+
+/*
+invalidTarget applies an --on-invalid policy to a document that failed validation.
+
+Parameters:
+  - fileName: Output path of the document.
+  - policy: One of InvalidPolicies; "" means "fail".
+
+Returns:
+  - The path to write the document to, or "" to write nothing.
+  - An error if the quarantine directory cannot be created.
+*/
+func invalidTarget(fileName, policy string) (string, error) {
+	switch policy {
+	case "warn":
+		return fileName, nil
+	case "quarantine":
+		dir := filepath.Join(filepath.Dir(fileName), QuarantineDir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create quarantine directory: %w", err)
+		}
+		return filepath.Join(dir, filepath.Base(fileName)), nil
+	default:
+		return "", nil
+	}
+}
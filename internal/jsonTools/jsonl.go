@@ -0,0 +1,82 @@
+package jsonTools
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ashahide/pubparse/internal/customErrors"
+)
+
+//
+// ------------------------ ConvertToJSONL ------------------------
+//
+
+/*
+ConvertToJSONL writes articles as JSON Lines: one compact JSON object per line.
+
+Parameters:
+  - docs: Articles to write, as returned by splitArticles.
+  - fileName: Path to save the output .jsonl file.
+  - opts: Projection and key naming, applied to every line.
+
+Behavior:
+  - Encodes every article and validates its snake_case form on its own against the
+    article's schema reference and opts.Validation (skipped for projected articles), stamping each line
+    with "schema_version".
+  - Writes the file only after every line is valid, so a rejected input leaves no lines behind.
+    With invalid lines, opts.OnInvalid decides as in ConvertToJSON for the whole file.
+  - Lines end in "\n", so outputs from several inputs can be concatenated into shards.
+
+Returns:
+  - A *customErrors.ValidationError holding the violations of every invalid line.
+  - An error if marshaling or writing fails; otherwise nil.
+*/
+func ConvertToJSONL(docs []articleDoc, fileName string, opts EncodeOptions) error {
+	var buf bytes.Buffer
+	var invalid *customErrors.ValidationError
+	for i, doc := range docs {
+		canonical, line, err := encodeDocument(doc.Value, opts)
+		if err != nil {
+			return fmt.Errorf("failed to marshal line %d to JSON: %w", i+1, err)
+		}
+		if opts.Projection == nil {
+			if err := validateDocument(doc.Value, canonical, doc.Schema, opts.Validation); err != nil {
+				var lineErr *customErrors.ValidationError
+				if !errors.As(err, &lineErr) {
+					return fmt.Errorf("line %d: %w", i+1, err)
+				}
+				if invalid == nil {
+					invalid = &customErrors.ValidationError{File: fileName, Schema: lineErr.Schema}
+				}
+				for _, v := range lineErr.Violations {
+					v.Line = i + 1
+					invalid.Violations = append(invalid.Violations, v)
+				}
+			}
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	target := fileName
+	if invalid != nil {
+		var err error
+		if target, err = invalidTarget(fileName, opts.OnInvalid); err != nil {
+			return err
+		}
+	}
+	if target != "" {
+		if err := os.WriteFile(target, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write JSONL file: %w", err)
+		}
+	}
+	if invalid != nil {
+		if target != "" {
+			invalid.File = target
+		}
+		return invalid
+	}
+	return nil
+}
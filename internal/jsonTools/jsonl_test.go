@@ -0,0 +1,97 @@
+package jsonTools_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/customErrors"
+	"github.com/ashahide/pubparse/internal/fileIO"
+	"github.com/ashahide/pubparse/internal/jsonTools"
+)
+
+//
+// ------------------------ Test: ProcessAllFiles with --format jsonl ------------------------
+//
+
+// abstractArticle returns a PubmedArticle element with the title and abstract the
+// text-mining profile requires.
+func abstractArticle(pmid string) string {
+	return `<PubmedArticle><MedlineCitation><PMID>` + pmid + `</PMID><Article><ArticleTitle>Title ` + pmid +
+		`</ArticleTitle><Abstract><AbstractText>Text.</AbstractText></Abstract></Article></MedlineCitation></PubmedArticle>`
+}
+
+// TestProcessAllFiles_JSONL verifies that each article is written on its own line with
+// its schema version, that nothing is written when a line is rejected, and that the
+// report names the line of each violation.
+func TestProcessAllFiles_JSONL(t *testing.T) {
+	tests := []struct {
+		name      string
+		articles  string // Articles of the input set
+		policy    string // --on-invalid
+		lines     int    // Expected lines in the output; 0 when nothing is written
+		violation string // Expected start of a report violation, "" when all lines are valid
+	}{
+		{"valid", abstractArticle("1") + abstractArticle("2") + abstractArticle("3"), "fail", 3, ""},
+		{"rejected", abstractArticle("1") + splitArticle("2", "1", "No abstract"), "fail", 0, "line 2 /medline_citation/article/abstract/abstract_text"},
+		{"kept", abstractArticle("1") + abstractArticle("2") + splitArticle("3", "1", "No abstract"), "warn", 3, "line 3 /medline_citation/article/abstract/abstract_text"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inDir, outDir := t.TempDir(), t.TempDir()
+			fin := filepath.Join(inDir, "set.xml")
+			fout := filepath.Join(outDir, "set.jsonl")
+			if err := os.WriteFile(fin, []byte("<PubmedArticleSet>"+test.articles+"</PubmedArticleSet>"), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", fin, err)
+			}
+
+			args := fileIO.Arguments{Format: "jsonl", OnInvalid: test.policy, Profile: "text-mining"}
+			args.OutputPath.Path = outDir
+			args.InputPath.Files = []string{fin}
+			args.OutputPath.Files = []string{fout}
+
+			report, err := os.Create(filepath.Join(outDir, "report.tsv"))
+			if err != nil {
+				t.Fatalf("failed to create report: %v", err)
+			}
+			defer report.Close()
+
+			err = jsonTools.ProcessAllFiles(context.Background(), args, "pubmed", report, 1)
+			var invalid *customErrors.ValidationError
+			if rejected := test.lines == 0; rejected != errors.As(err, &invalid) || !rejected && err != nil {
+				t.Fatalf("expected rejection: %t, got %v", rejected, err)
+			}
+
+			content, err := os.ReadFile(fout)
+			switch {
+			case test.lines == 0 && err == nil:
+				t.Errorf("expected %s not to be written, got %s", fout, content)
+			case test.lines > 0 && err != nil:
+				t.Errorf("expected %s to be written: %v", fout, err)
+			case test.lines > 0:
+				lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+				if len(lines) != test.lines {
+					t.Fatalf("expected %d lines, got %d:\n%s", test.lines, len(lines), content)
+				}
+				for i, line := range lines {
+					if !strings.HasPrefix(line, `{"schema_version":"3.0","medline_citation":{`) || !strings.Contains(line, `"article_title":`) {
+						t.Errorf("line %d: expected one stamped article, got %s", i+1, line)
+					}
+				}
+			}
+
+			reported, _ := os.ReadFile(report.Name())
+			hasViolation := strings.Contains(string(reported), ">>> Violation: "+fin+"\t ")
+			switch {
+			case test.violation == "" && hasViolation:
+				t.Errorf("unexpected violations in the report:\n%s", reported)
+			case test.violation != "" && !strings.Contains(string(reported), ">>> Violation: "+fin+"\t "+test.violation):
+				t.Errorf("expected the violation %q in the report, got:\n%s", test.violation, reported)
+			}
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package jsonTools
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ashahide/pubparse/internal/fileIO"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Content manifest ------------------------
+//
+
+// ContentManifest is the JSON Lines file in the output directory recording, per
+// input, the content and settings its output was written from.
+const ContentManifest = "manifest.jsonl"
+
+// Version is the pubparse release recorded in the content manifest. Builds can set
+// it with -ldflags "-X github.com/ashahide/pubparse/internal/jsonTools.Version=...";
+// otherwise the VCS revision stamped by go build is used.
+var Version = ""
+
+// ManifestEntry is one line of the content manifest.
+type ManifestEntry struct {
+	Input         string    `json:"input"`  // Absolute input path
+	Size          int64     `json:"size"`   // Input size in bytes
+	ModTime       time.Time `json:"mtime"`  // Input modification time
+	SHA256        string    `json:"sha256"` // Input content hash, hex
+	Output        string    `json:"output"` // Absolute output path
+	OutputSHA256  string    `json:"output_sha256"`
+	Version       string    `json:"pubparse_version"`
+	SchemaVersion string    `json:"schema_version"`
+	Settings      string    `json:"settings"` // Options that change the output, see outputSettings
+}
+
+// Manifest holds the entries read from an output directory and those recorded by
+// the current run.
+type Manifest struct {
+	Path     string
+	settings string
+	previous map[string]ManifestEntry // Keyed by input path
+	recorded map[string]ManifestEntry
+	mu       sync.Mutex
+}
+
+/*
+LoadManifest reads the content manifest of an output directory.
+
+Parameters:
+  - dir: The output directory.
+  - settings: The current run's output settings (see outputSettings).
+
+Returns:
+  - The manifest; without previous entries if the file does not exist.
+  - An error if the file cannot be read or a line is not a manifest entry; the
+    returned manifest is then empty but usable.
+*/
+func LoadManifest(dir, settings string) (*Manifest, error) {
+	m := &Manifest{
+		Path:     filepath.Join(dir, ContentManifest),
+		settings: settings,
+		previous: map[string]ManifestEntry{},
+		recorded: map[string]ManifestEntry{},
+	}
+
+	content, err := os.ReadFile(m.Path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return m, fmt.Errorf("failed to read %s: %w", m.Path, err)
+	}
+
+	entries := map[string]ManifestEntry{}
+	for i, line := range bytes.Split(content, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry ManifestEntry
+		if err := json.Unmarshal(line, &entry); err != nil || entry.Input == "" {
+			return m, fmt.Errorf("invalid entry on line %d of %s; remove the file to rebuild it", i+1, m.Path)
+		}
+		entries[entry.Input] = entry
+	}
+	m.previous = entries
+	return m, nil
+}
+
+/*
+Inspect describes an input as it is now.
+
+Parameters:
+  - fin: The input file.
+  - fout: Its output file, relative to the working directory or absolute.
+
+Behavior:
+  - Reuses the recorded SHA-256 when the size and mtime match the previous entry,
+    so unchanged inputs are not read.
+
+Returns:
+  - The entry for the input, without the output hash.
+  - An error if the input cannot be read.
+*/
+func (m *Manifest) Inspect(fin, fout string) (ManifestEntry, error) {
+	entry := ManifestEntry{
+		Input:         fin,
+		Version:       pubparseVersion(),
+		SchemaVersion: xmlTools.SchemaVersion,
+		Settings:      m.settings,
+	}
+
+	var err error
+	if entry.Output, err = filepath.Abs(fout); err != nil {
+		return entry, err
+	}
+	info, err := os.Stat(fin)
+	if err != nil {
+		return entry, err
+	}
+	entry.Size = info.Size()
+	entry.ModTime = info.ModTime().UTC()
+
+	if prev, ok := m.previous[fin]; ok && prev.Size == entry.Size && prev.ModTime.Equal(entry.ModTime) {
+		entry.SHA256 = prev.SHA256
+		return entry, nil
+	}
+	entry.SHA256, err = hashFile(fin)
+	return entry, err
+}
+
+/*
+Current reports whether the output recorded for an input can be kept.
+
+Parameters:
+  - entry: The input as returned by Inspect; its output hash is set when current.
+
+Returns:
+  - true when the previous entry has the same input content, output path, pubparse
+    version, schema version and settings, and the output file still has the hash
+    it was written with.
+*/
+func (m *Manifest) Current(entry *ManifestEntry) bool {
+	prev, ok := m.previous[entry.Input]
+	if !ok || prev.SHA256 != entry.SHA256 || prev.Output != entry.Output ||
+		prev.Version != entry.Version || prev.SchemaVersion != entry.SchemaVersion || prev.Settings != entry.Settings {
+		return false
+	}
+	hash, err := hashFile(entry.Output)
+	if err != nil || hash != prev.OutputSHA256 {
+		return false
+	}
+	entry.OutputSHA256 = hash
+	return true
+}
+
+// Record stores the entry of an input whose output was written or kept, hashing
+// the output if needed. Inputs without an output file (e.g. quarantined documents)
+// are not recorded, so the next run processes them again; neither are outputs kept
+// with schema violations, which the caller does not pass.
+func (m *Manifest) Record(entry ManifestEntry) {
+	if entry.OutputSHA256 == "" {
+		hash, err := hashFile(entry.Output)
+		if err != nil {
+			return
+		}
+		entry.OutputSHA256 = hash
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recorded[entry.Input] = entry
+}
+
+/*
+Write replaces the manifest file.
+
+Parameters:
+  - inputs: The inputs of the current run; their previous entries are dropped unless
+    recorded again, so failed and pending inputs are processed by the next run.
+
+Behavior:
+  - Keeps the entries of inputs outside the run, e.g. when resuming from pending.txt.
+  - Writes sorted by input path, through a temporary file renamed over the manifest.
+
+Returns:
+  - An error if the file cannot be written; otherwise nil.
+*/
+func (m *Manifest) Write(inputs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := map[string]ManifestEntry{}
+	for input, entry := range m.previous {
+		entries[input] = entry
+	}
+	for _, input := range inputs {
+		delete(entries, input)
+	}
+	for input, entry := range m.recorded {
+		entries[input] = entry
+	}
+
+	keys := make([]string, 0, len(entries))
+	for input := range entries {
+		keys = append(keys, input)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	for _, input := range keys {
+		if err := enc.Encode(entries[input]); err != nil {
+			return err
+		}
+	}
+
+	tmp := m.Path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", m.Path, err)
+	}
+	if err := os.Rename(tmp, m.Path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", m.Path, err)
+	}
+	return nil
+}
+
+// hashFile returns the hex SHA-256 of a file's content.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, bufio.NewReader(f)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// pubparseVersion returns Version, or the VCS revision of the build ("devel" if none).
+func pubparseVersion() string {
+	if Version != "" {
+		return Version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	revision, modified := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	switch {
+	case revision == "":
+		return "devel"
+	case modified:
+		return revision + "-dirty"
+	default:
+		return revision
+	}
+}
+
+// outputSettings summarizes the options that change an output, so that a change of
+// any of them makes --incremental regenerate the outputs. User schemas count with
+// their content, so editing a --schema file in place regenerates the outputs too.
+func outputSettings(mode string, args fileIO.Arguments) string {
+	validation, _ := LoadValidation(args.Profile, args.SchemaPath)
+	return fmt.Sprintf(
+		"mode=%s format=%s lenient=%t legacy-keys=%t on-invalid=%s profile=%s schema=%s schema-sha256=%s disable-rules=%v chunk=%d%s drop-references=%t strip-citations=%t index=%s %s",
+		mode, args.Format, args.Lenient, args.LegacyKeys, args.OnInvalid, args.Profile, args.SchemaPath, validation.userSchemaHash(), args.DisabledRules,
+		args.ChunkSize, args.ChunkUnit, args.DropReferences, args.StripCitations, args.IndexName, args.Projection,
+	)
+}
+
+// userSchemaHash returns the hex SHA-256 of the user schema files of a Validation
+// (names and content, in name order), or "" when it has none. The bundled schemas
+// are part of the pubparse version.
+func (v *Validation) userSchemaHash() string {
+	if v == nil {
+		return ""
+	}
+
+	var files []string
+	for _, file := range v.Documents {
+		files = append(files, file)
+	}
+	for _, refs := range v.Articles {
+		for _, ref := range refs {
+			if _, err := embeddedSchemas.Open(ref); err != nil {
+				files = append(files, ref)
+			}
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for i, file := range files {
+		if i > 0 && file == files[i-1] {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			content = []byte("unreadable: " + err.Error())
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", file, len(content))
+		h.Write(content)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
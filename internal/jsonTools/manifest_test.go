@@ -0,0 +1,164 @@
+package jsonTools_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/fileIO"
+	"github.com/ashahide/pubparse/internal/jsonTools"
+)
+
+//
+// ------------------------ Test: Content manifest ------------------------
+//
+
+// TestProcessAllFiles_Incremental verifies that an incremental run keeps the outputs
+// recorded in the manifest and processes the inputs or outputs that changed.
+func TestProcessAllFiles_Incremental(t *testing.T) {
+	inDir, outDir := t.TempDir(), t.TempDir()
+	article := func(pmid string) string {
+		return `<PubmedArticleSet><PubmedArticle><MedlineCitation><PMID>` + pmid + `</PMID><Article><ArticleTitle>T</ArticleTitle></Article></MedlineCitation></PubmedArticle></PubmedArticleSet>`
+	}
+
+	args := fileIO.Arguments{Format: "json", OnInvalid: "fail", Incremental: true}
+	args.OutputPath.Path = outDir
+	for _, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(inDir, name+".xml")
+		if err := os.WriteFile(path, []byte(article("1")), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+		args.InputPath.Files = append(args.InputPath.Files, path)
+		args.OutputPath.Files = append(args.OutputPath.Files, filepath.Join(outDir, name+".json"))
+	}
+
+	// run processes the inputs with a fresh report and returns its content
+	run := func(args fileIO.Arguments) string {
+		t.Helper()
+		reportPath := filepath.Join(t.TempDir(), "report.tsv")
+		report, err := os.Create(reportPath)
+		if err != nil {
+			t.Fatalf("failed to create report: %v", err)
+		}
+		defer report.Close()
+		if err := fileIO.CheckWriteAccess(args.OutputPath.Files); err != nil {
+			t.Fatalf("CheckWriteAccess failed: %v", err)
+		}
+		if err := jsonTools.ProcessAllFiles(context.Background(), args, "pubmed", report, 2); err != nil {
+			t.Fatalf("ProcessAllFiles failed: %v", err)
+		}
+		content, _ := os.ReadFile(reportPath)
+		return string(content)
+	}
+
+	if report := run(args); strings.Contains(report, ">>> Unchanged:") {
+		t.Fatalf("expected the first run to process every input, got:\n%s", report)
+	}
+	manifest, _ := os.ReadFile(filepath.Join(outDir, jsonTools.ContentManifest))
+	if lines := strings.Count(string(manifest), "\n"); lines != 3 {
+		t.Fatalf("expected 3 manifest entries, got %d:\n%s", lines, manifest)
+	}
+
+	// Change one input and tamper with another output
+	if err := os.WriteFile(args.InputPath.Files[0], []byte(article("2")), 0644); err != nil {
+		t.Fatalf("failed to rewrite input: %v", err)
+	}
+	if err := os.WriteFile(args.OutputPath.Files[1], []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to tamper with output: %v", err)
+	}
+
+	report := run(args)
+	for i, unchanged := range []bool{false, false, true} {
+		line := ">>> Unchanged: " + args.InputPath.Files[i] + "\t "
+		if strings.Contains(report, line) != unchanged {
+			t.Errorf("input %d: expected unchanged=%v, got:\n%s", i, unchanged, report)
+		}
+	}
+	if content, _ := os.ReadFile(args.OutputPath.Files[0]); !strings.Contains(string(content), `"pmid":"2"`) {
+		t.Errorf("expected the changed input to be converted again, got %s", content)
+	}
+
+	// A setting that changes the output regenerates everything
+	args.LegacyKeys = true
+	if report := run(args); strings.Contains(report, ">>> Unchanged:") {
+		t.Errorf("expected --legacy-keys to regenerate every output, got:\n%s", report)
+	}
+
+	// So does editing a --schema file in place
+	args.SchemaPath = filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(args.SchemaPath, []byte(`{"type": "object"}`), 0644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	run(args)
+	if report := run(args); strings.Count(report, ">>> Unchanged:") != 3 {
+		t.Fatalf("expected an unchanged schema to keep every output, got:\n%s", report)
+	}
+	if err := os.WriteFile(args.SchemaPath, []byte(`{"type": "object", "required": ["medline_citation"]}`), 0644); err != nil {
+		t.Fatalf("failed to rewrite schema: %v", err)
+	}
+	if report := run(args); strings.Contains(report, ">>> Unchanged:") {
+		t.Errorf("expected an edited --schema file to regenerate every output, got:\n%s", report)
+	}
+}
+
+// TestProcessAllFiles_IncrementalInvalid verifies that an output kept with schema
+// violations under --on-invalid warn is not recorded as current, so the next
+// incremental run converts it and reports its violations again.
+func TestProcessAllFiles_IncrementalInvalid(t *testing.T) {
+	inDir, outDir := t.TempDir(), t.TempDir()
+
+	args := fileIO.Arguments{Format: "json", OnInvalid: "warn", Profile: "text-mining", Incremental: true}
+	args.OutputPath.Path = outDir
+	// The text-mining profile requires the abstract the second input lacks
+	for name, article := range map[string]string{"valid": abstractArticle("1"), "invalid": splitArticle("2", "1", "No abstract")} {
+		path := filepath.Join(inDir, name+".xml")
+		if err := os.WriteFile(path, []byte("<PubmedArticleSet>"+article+"</PubmedArticleSet>"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+		args.InputPath.Files = append(args.InputPath.Files, path)
+		args.OutputPath.Files = append(args.OutputPath.Files, filepath.Join(outDir, name+".json"))
+	}
+	valid, invalid := filepath.Join(inDir, "valid.xml"), filepath.Join(inDir, "invalid.xml")
+
+	for run := 1; run <= 2; run++ {
+		reportPath := filepath.Join(t.TempDir(), "report.tsv")
+		report, err := os.Create(reportPath)
+		if err != nil {
+			t.Fatalf("failed to create report: %v", err)
+		}
+		if err := fileIO.CheckWriteAccess(args.OutputPath.Files); err != nil {
+			t.Fatalf("CheckWriteAccess failed: %v", err)
+		}
+		if err := jsonTools.ProcessAllFiles(context.Background(), args, "pubmed", report, 2); err != nil {
+			t.Fatalf("run %d: ProcessAllFiles failed: %v", run, err)
+		}
+		report.Close()
+		content, _ := os.ReadFile(reportPath)
+
+		if got := strings.Contains(string(content), ">>> Unchanged: "+valid+"\t "); got != (run == 2) {
+			t.Errorf("run %d: expected the valid input unchanged=%t, got:\n%s", run, run == 2, content)
+		}
+		if strings.Contains(string(content), ">>> Unchanged: "+invalid+"\t ") || !strings.Contains(string(content), ">>> Violation: "+invalid+"\t ") {
+			t.Errorf("run %d: expected the invalid input to be converted and reported, got:\n%s", run, content)
+		}
+	}
+
+	manifest, _ := os.ReadFile(filepath.Join(outDir, jsonTools.ContentManifest))
+	if strings.Count(string(manifest), "\n") != 1 || strings.Contains(string(manifest), invalid) {
+		t.Errorf("expected only the valid input in the manifest, got:\n%s", manifest)
+	}
+}
+
+// TestLoadManifest_Invalid verifies that a damaged manifest is reported rather than
+// silently trusted.
+func TestLoadManifest_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, jsonTools.ContentManifest), []byte("not json\n"), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+	if _, err := jsonTools.LoadManifest(dir, ""); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("expected an error on line 1, got %v", err)
+	}
+}
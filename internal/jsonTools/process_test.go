@@ -0,0 +1,296 @@
+package jsonTools_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ashahide/pubparse/internal/customErrors"
+	"github.com/ashahide/pubparse/internal/fileIO"
+	"github.com/ashahide/pubparse/internal/jsonTools"
+
+	_ "modernc.org/sqlite"
+)
+
+//
+// ------------------------ Test: ProcessAllFiles ------------------------
+//
+
+// TestProcessAllFiles_ContinueOnError verifies that a failed input does not stop the
+// others, that each input gets a status line and that failures.txt lists the failures.
+// The failed input is named articles.xml so that, for the aggregate formats, its
+// output path is the shared file holding the other inputs' rows.
+func TestProcessAllFiles_ContinueOnError(t *testing.T) {
+	tests := []struct {
+		format string
+		shared string // Shared output that must keep the good input's row; "" for per-input formats
+	}{
+		{format: "json"},
+		{format: "tsv", shared: "articles.tsv"},
+		{format: "sqlite", shared: "articles.sqlite"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			inDir, outDir := t.TempDir(), t.TempDir()
+			inputs := map[string]string{
+				"good.xml":     `<PubmedArticleSet><PubmedArticle><MedlineCitation><PMID>1</PMID><Article><ArticleTitle>T</ArticleTitle></Article></MedlineCitation></PubmedArticle></PubmedArticleSet>`,
+				"articles.xml": `<PubmedArticleSet><PubmedArticle>`,
+			}
+
+			args := fileIO.Arguments{Format: tt.format, OnInvalid: "fail", ContinueOnError: true}
+			args.OutputPath.Path = outDir
+			if tt.format == "sqlite" {
+				args.OutputFile = filepath.Join(outDir, tt.shared)
+			}
+			for _, name := range []string{"articles.xml", "good.xml"} {
+				path := filepath.Join(inDir, name)
+				if err := os.WriteFile(path, []byte(inputs[name]), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", path, err)
+				}
+				args.InputPath.Files = append(args.InputPath.Files, path)
+				args.OutputPath.Files = append(args.OutputPath.Files, filepath.Join(outDir, strings.TrimSuffix(name, ".xml")+"."+fileIO.OutputExtension(tt.format)))
+			}
+
+			report, err := os.Create(filepath.Join(outDir, "report.tsv"))
+			if err != nil {
+				t.Fatalf("failed to create report: %v", err)
+			}
+			defer report.Close()
+
+			err = jsonTools.ProcessAllFiles(context.Background(), args, "pubmed", report, 2)
+			var partial *customErrors.PartialFailureError
+			if !errors.As(err, &partial) || partial.Failed != 1 || partial.Total != 2 {
+				t.Fatalf("expected a partial failure of 1 in 2, got %v", err)
+			}
+
+			switch tt.format {
+			case "json":
+				if _, err := os.Stat(args.OutputPath.Files[1]); err != nil {
+					t.Errorf("expected the good input to be written: %v", err)
+				}
+				if _, err := os.Stat(args.OutputPath.Files[0]); !os.IsNotExist(err) {
+					t.Errorf("expected no output for the broken input, got %v", err)
+				}
+			case "tsv":
+				content, err := os.ReadFile(filepath.Join(outDir, tt.shared))
+				if err != nil || !strings.Contains(string(content), "\n1\t") {
+					t.Errorf("expected %s to keep the good input's row, got %q (%v)", tt.shared, content, err)
+				}
+			case "sqlite":
+				db, err := sql.Open("sqlite", args.OutputFile)
+				if err != nil {
+					t.Fatalf("failed to open %s: %v", args.OutputFile, err)
+				}
+				defer db.Close()
+				var count int
+				if err := db.QueryRow(`SELECT COUNT(*) FROM articles WHERE pmid = '1'`).Scan(&count); err != nil || count != 1 {
+					t.Errorf("expected %s to keep the good input's row, got %d (%v)", tt.shared, count, err)
+				}
+			}
+
+			manifest, _ := os.ReadFile(partial.Manifest)
+			if string(manifest) != args.InputPath.Files[0]+"\n" {
+				t.Errorf("unexpected failures.txt: %q", manifest)
+			}
+
+			content, _ := os.ReadFile(report.Name())
+			for _, want := range []string{
+				fmt.Sprintf(">>> Status: %s\t %s\t ", args.InputPath.Files[0], jsonTools.StatusParseError),
+				fmt.Sprintf(">>> Status: %s\t %s\n", args.InputPath.Files[1], jsonTools.StatusOK),
+			} {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("expected report to contain %q, got:\n%s", want, content)
+				}
+			}
+		})
+	}
+}
+
+// TestProcessAllFiles_Interrupted verifies that a cancelled run processes nothing
+// more, removes the pre-created empty outputs and lists every input in pending.txt.
+func TestProcessAllFiles_Interrupted(t *testing.T) {
+	inDir, outDir := t.TempDir(), t.TempDir()
+	article := `<PubmedArticleSet><PubmedArticle><MedlineCitation><PMID>1</PMID><Article><ArticleTitle>T</ArticleTitle></Article></MedlineCitation></PubmedArticle></PubmedArticleSet>`
+
+	args := fileIO.Arguments{Format: "json", OnInvalid: "fail"}
+	args.OutputPath.Path = outDir
+	for _, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(inDir, name+".xml")
+		if err := os.WriteFile(path, []byte(article), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+		args.InputPath.Files = append(args.InputPath.Files, path)
+		args.OutputPath.Files = append(args.OutputPath.Files, filepath.Join(outDir, name+".json"))
+	}
+	if err := fileIO.VerifyWriteAccess(args.OutputPath.Files); err != nil {
+		t.Fatalf("failed to pre-create outputs: %v", err)
+	}
+
+	report, err := os.Create(filepath.Join(outDir, "report.tsv"))
+	if err != nil {
+		t.Fatalf("failed to create report: %v", err)
+	}
+	defer report.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = jsonTools.ProcessAllFiles(ctx, args, "pubmed", report, 2)
+	var interrupted *customErrors.InterruptedError
+	if !errors.As(err, &interrupted) || interrupted.Pending != 3 || interrupted.Completed != 0 {
+		t.Fatalf("expected 3 pending inputs, got %v", err)
+	}
+
+	for _, fout := range args.OutputPath.Files {
+		if _, err := os.Stat(fout); !os.IsNotExist(err) {
+			t.Errorf("expected the empty output %s to be removed, got %v", fout, err)
+		}
+	}
+
+	manifest, _ := os.ReadFile(filepath.Join(outDir, jsonTools.PendingManifest))
+	if string(manifest) != strings.Join(args.InputPath.Files, "\n")+"\n" {
+		t.Errorf("unexpected pending.txt: %q", manifest)
+	}
+	if content, _ := os.ReadFile(report.Name()); !strings.Contains(string(content), ">>> Pending Inputs: 3\t ") {
+		t.Errorf("expected the report to count the pending inputs, got:\n%s", content)
+	}
+
+	// A run that is not interrupted removes the list
+	if err := jsonTools.ProcessAllFiles(context.Background(), args, "pubmed", report, 2); err != nil {
+		t.Fatalf("resumed run failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, jsonTools.PendingManifest)); !os.IsNotExist(err) {
+		t.Errorf("expected pending.txt to be removed, got %v", err)
+	}
+}
+
+// TestFailureStatus verifies the classification of per-input errors.
+func TestFailureStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("wrapped: %w", &customErrors.ParseError{File: "a.xml", Err: errors.New("EOF")}), jsonTools.StatusParseError},
+		{fmt.Errorf("failed to convert: %w", &customErrors.ValidationError{}), jsonTools.StatusValidationError},
+		{os.ErrPermission, jsonTools.StatusIOError},
+	}
+	for _, tt := range tests {
+		if got := jsonTools.FailureStatus(tt.err); got != tt.want {
+			t.Errorf("FailureStatus(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestProcessAllFiles_OnInvalid verifies each --on-invalid policy on a document that
+// fails the text-mining profile: the run's error, the report line naming where the
+// document went and the files written to the output and quarantine directories.
+func TestProcessAllFiles_OnInvalid(t *testing.T) {
+	tests := []struct {
+		policy      string
+		fails       bool // The run returns a *customErrors.ValidationError
+		output      bool // The document is written to the output directory
+		quarantined bool // The document is written to the quarantine directory
+	}{
+		{policy: "fail", fails: true},
+		{policy: "warn", output: true},
+		{policy: "quarantine", quarantined: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			inDir, outDir := t.TempDir(), t.TempDir()
+			fin := filepath.Join(inDir, "set.xml")
+			fout := filepath.Join(outDir, "set.json")
+			quarantined := filepath.Join(outDir, jsonTools.QuarantineDir, "set.json")
+			// No abstract, which the text-mining profile requires
+			article := `<PubmedArticleSet><PubmedArticle><MedlineCitation><PMID>1</PMID><Article><ArticleTitle>T</ArticleTitle></Article></MedlineCitation></PubmedArticle></PubmedArticleSet>`
+			if err := os.WriteFile(fin, []byte(article), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", fin, err)
+			}
+
+			args := fileIO.Arguments{Format: "json", OnInvalid: tt.policy, Profile: "text-mining"}
+			args.OutputPath.Path = outDir
+			args.InputPath.Files = []string{fin}
+			args.OutputPath.Files = []string{fout}
+
+			report, err := os.Create(filepath.Join(outDir, "report.tsv"))
+			if err != nil {
+				t.Fatalf("failed to create report: %v", err)
+			}
+			defer report.Close()
+
+			err = jsonTools.ProcessAllFiles(context.Background(), args, "pubmed", report, 1)
+			var invalid *customErrors.ValidationError
+			if tt.fails != errors.As(err, &invalid) {
+				t.Fatalf("expected a validation error: %t, got %v", tt.fails, err)
+			}
+			if !tt.fails && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			target := fout
+			if tt.quarantined {
+				target = quarantined
+			}
+			content, _ := os.ReadFile(report.Name())
+			if want := fmt.Sprintf(">>> Invalid: %s\t %s\t %s against ", fin, target, tt.policy); !strings.Contains(string(content), want) {
+				t.Errorf("expected report to contain %q, got:\n%s", want, content)
+			}
+			if !strings.Contains(string(content), ">>> Violation: "+fin+"\t ") {
+				t.Errorf("expected the violations in the report, got:\n%s", content)
+			}
+
+			for path, want := range map[string]bool{fout: tt.output, quarantined: tt.quarantined} {
+				if _, err := os.Stat(path); (err == nil) != want {
+					t.Errorf("expected %s to exist: %t, got %v", path, want, err)
+				}
+			}
+		})
+	}
+}
+
+// TestProcessAllFiles_BioCDate verifies that a BioC collection is dated by the
+// modification time of its input, so converting the same input twice gives the same file.
+func TestProcessAllFiles_BioCDate(t *testing.T) {
+	inDir, outDir := t.TempDir(), t.TempDir()
+	fin := filepath.Join(inDir, "set.xml")
+	fout := filepath.Join(outDir, "set.bioc.json")
+	article := `<PubmedArticleSet><PubmedArticle><MedlineCitation><PMID>1</PMID><Article><ArticleTitle>T</ArticleTitle></Article></MedlineCitation></PubmedArticle></PubmedArticleSet>`
+	if err := os.WriteFile(fin, []byte(article), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", fin, err)
+	}
+	modified := time.Date(2021, 3, 4, 23, 30, 0, 0, time.UTC)
+	if err := os.Chtimes(fin, modified, modified); err != nil {
+		t.Fatalf("failed to set the modification time of %s: %v", fin, err)
+	}
+
+	args := fileIO.Arguments{Format: "bioc-json", OnInvalid: "fail"}
+	args.OutputPath.Path = outDir
+	args.InputPath.Files = []string{fin}
+	args.OutputPath.Files = []string{fout}
+
+	var outputs []string
+	for run := 0; run < 2; run++ {
+		if err := jsonTools.ProcessAllFiles(context.Background(), args, "pubmed", nil, 1); err != nil {
+			t.Fatalf("ProcessAllFiles failed: %v", err)
+		}
+		content, err := os.ReadFile(fout)
+		if err != nil {
+			t.Fatalf("expected %s to be written: %v", fout, err)
+		}
+		outputs = append(outputs, string(content))
+	}
+
+	if !strings.Contains(outputs[0], `"date":"20210304"`) {
+		t.Errorf("expected the collection to be dated 20210304, got %s", outputs[0])
+	}
+	if outputs[0] != outputs[1] {
+		t.Errorf("expected identical outputs, got:\n%s\n%s", outputs[0], outputs[1])
+	}
+}
@@ -0,0 +1,327 @@
+package jsonTools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ashahide/pubparse/internal/customErrors"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Validation profiles ------------------------
+//
+
+// ArticleTypes are the article kinds that per-article schemas apply to, named
+// after their structs. A profile or --schema directory holds one <type>.json each.
+var ArticleTypes = []string{"PubmedArticle", "PubmedBookArticle", "PMCArticle"}
+
+// Profiles are the validation profiles bundled with pubparse:
+//   - "default": the bundled document schemas only.
+//   - "citation": every article also has a title, authors, a journal or book, a year and a DOI.
+//   - "text-mining": every article also has a title and an abstract (and body sections for PMC).
+var Profiles = []string{"default", "citation", "text-mining"}
+
+// Validation lists the schemas a run validates against besides, or instead of, the
+// bundled ones. A nil *Validation validates against the bundled schemas only.
+type Validation struct {
+	Documents map[string]string   // Bundled schema name -> replacement schema file (--schema overrides)
+	Articles  map[string][]string // Article type -> extra schemas every article of that type must satisfy
+}
+
+// validations caches one Validation per profile and --schema path, so that every
+// worker shares the schemas checked by LoadValidation.
+var validations = struct {
+	sync.Mutex
+	loaded map[string]*Validation
+}{loaded: map[string]*Validation{}}
+
+/*
+LoadValidation resolves a validation profile and a user --schema path.
+
+Parameters:
+  - profile: One of Profiles; "" means "default".
+  - schemaPath: "" for none; a schema file that every article must satisfy; or a
+    directory holding <type>.json per-article schemas (see ArticleTypes) and/or
+    pubmed_json_schema.json / pmc_json_schema.json replacing the bundled document schemas.
+
+Behavior:
+  - Compiles every schema up front, so that a broken schema fails the run before any input is read.
+  - Results are cached, so later calls with the same arguments are free.
+
+Returns:
+  - nil for the default profile without --schema.
+  - An error for an unknown profile, an unrecognized .json file in the directory or
+    a schema that does not compile.
+*/
+func LoadValidation(profile, schemaPath string) (*Validation, error) {
+	validations.Lock()
+	defer validations.Unlock()
+
+	key := profile + "\x00" + schemaPath
+	if v, ok := validations.loaded[key]; ok {
+		return v, nil
+	}
+
+	v := &Validation{Documents: map[string]string{}, Articles: map[string][]string{}}
+	if err := v.addProfile(profile); err != nil {
+		return nil, err
+	}
+	if err := v.addSchemaPath(schemaPath); err != nil {
+		return nil, err
+	}
+
+	// Fail on broken schemas now rather than in the first worker
+	var refs []string
+	for _, ref := range v.Documents {
+		refs = append(refs, ref)
+	}
+	for _, typeRefs := range v.Articles {
+		refs = append(refs, typeRefs...)
+	}
+	for _, ref := range refs {
+		if _, err := compiledSchema(ref); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(v.Documents) == 0 && len(v.Articles) == 0 {
+		v = nil
+	}
+	validations.loaded[key] = v
+	return v, nil
+}
+
+// addProfile adds the embedded profiles/<profile>/<type>.json schemas.
+func (v *Validation) addProfile(profile string) error {
+	if profile == "" || profile == "default" {
+		return nil
+	}
+	if !isProfile(profile) {
+		return fmt.Errorf("unknown validation profile %q (expected %s)", profile, strings.Join(Profiles, ", "))
+	}
+
+	for _, typ := range ArticleTypes {
+		ref := path.Join("profiles", profile, typ+".json")
+		if _, err := fs.Stat(embeddedSchemas, ref); err == nil {
+			v.Articles[typ] = append(v.Articles[typ], ref)
+		}
+	}
+	return nil
+}
+
+// addSchemaPath adds a user schema file, or the schemas of a user directory.
+func (v *Validation) addSchemaPath(schemaPath string) error {
+	if schemaPath == "" {
+		return nil
+	}
+	info, err := os.Stat(schemaPath)
+	if err != nil {
+		return fmt.Errorf("invalid --schema path: %w", err)
+	}
+
+	if !info.IsDir() {
+		for _, typ := range ArticleTypes {
+			v.Articles[typ] = append(v.Articles[typ], schemaPath)
+		}
+		return nil
+	}
+
+	entries, err := os.ReadDir(schemaPath)
+	if err != nil {
+		return fmt.Errorf("invalid --schema path: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		file := filepath.Join(schemaPath, name)
+		switch typ := strings.TrimSuffix(name, ".json"); {
+		case name == PubmedSchema || name == PMCSchema:
+			v.Documents[name] = file
+		case isArticleType(typ):
+			v.Articles[typ] = append(v.Articles[typ], file)
+		default:
+			return fmt.Errorf("unrecognized schema %q in %s (expected %s.json, %s or %s)",
+				name, schemaPath, strings.Join(ArticleTypes, ".json, "), PubmedSchema, PMCSchema)
+		}
+	}
+	return nil
+}
+
+func isProfile(profile string) bool {
+	for _, p := range Profiles {
+		if profile == p {
+			return true
+		}
+	}
+	return false
+}
+
+func isArticleType(typ string) bool {
+	for _, t := range ArticleTypes {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
+
+//
+// ------------------------ validateDocument ------------------------
+//
+
+/*
+validateDocument validates one output document against its schema and the
+per-article schemas of a Validation.
+
+Parameters:
+  - doc: The marshaled value (a set, a single article or a PMC article).
+  - canonical: Its snake_case JSON, stamped with the schema version.
+  - schemaRef: The bundled schema reference; replaced when the Validation overrides it.
+  - v: Extra schemas; nil validates against schemaRef only.
+
+Returns:
+  - A *customErrors.ValidationError merging the violations of every schema, with
+    per-article pointers relative to the document (e.g. "/pubmed_articles/2/...").
+  - Any other validation error; otherwise nil.
+*/
+func validateDocument(doc interface{}, canonical []byte, schemaRef string, v *Validation) error {
+	var merged *customErrors.ValidationError
+	collect := func(err error, schema, prefix string) error {
+		var invalid *customErrors.ValidationError
+		if !errors.As(err, &invalid) {
+			return err
+		}
+		if merged == nil {
+			merged = &customErrors.ValidationError{Schema: schema}
+		} else if !strings.Contains(merged.Schema, schema) {
+			merged.Schema += ", " + schema
+		}
+		for _, violation := range invalid.Violations {
+			violation.Pointer = prefix + violation.Pointer
+			merged.Violations = append(merged.Violations, violation)
+		}
+		return nil
+	}
+
+	ref := v.document(schemaRef)
+	if strings.Contains(ref, "#") {
+		// Article definitions do not describe "schema_version"; check it here
+		document, version := withoutSchemaVersion(canonical)
+		if version != nil {
+			collect(&customErrors.ValidationError{Violations: []customErrors.Violation{*version}}, ref, "")
+		}
+		canonical = document
+	}
+	if err := ValidateJsonBytesAgainstSchema(canonical, ref); err != nil {
+		if err := collect(err, ref, ""); err != nil {
+			return err
+		}
+	}
+
+	if v != nil && len(v.Articles) > 0 {
+		for _, article := range articlesOf(doc) {
+			refs := v.Articles[article.typ]
+			if len(refs) == 0 {
+				continue
+			}
+			raw, err := json.Marshal(article.value)
+			if err != nil {
+				return err
+			}
+			for _, ref := range refs {
+				if err := ValidateJsonBytesAgainstSchema(raw, ref); err != nil {
+					if err := collect(err, ref, article.pointer); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	if merged != nil {
+		return merged
+	}
+	return nil
+}
+
+// withoutSchemaVersion removes "schema_version" from a single-article document and
+// returns a violation when it is missing or not xmlTools.SchemaVersion. Documents that
+// are not JSON objects are returned unchanged for the schema to report.
+func withoutSchemaVersion(canonical []byte) ([]byte, *customErrors.Violation) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(canonical, &keys); err != nil {
+		return canonical, nil
+	}
+	raw, found := keys["schema_version"]
+	if !found {
+		return canonical, &customErrors.Violation{Description: "schema_version is required"}
+	}
+	delete(keys, "schema_version")
+	document, err := json.Marshal(keys)
+	if err != nil {
+		return canonical, nil
+	}
+
+	var version string
+	if err := json.Unmarshal(raw, &version); err != nil || version != xmlTools.SchemaVersion {
+		return document, &customErrors.Violation{
+			Pointer:     "/schema_version",
+			Description: fmt.Sprintf("schema_version must be %q", xmlTools.SchemaVersion),
+		}
+	}
+	return document, nil
+}
+
+// document returns the schema reference replacing a bundled one, keeping its fragment.
+func (v *Validation) document(schemaRef string) string {
+	if v == nil {
+		return schemaRef
+	}
+	name, fragment, found := strings.Cut(schemaRef, "#")
+	if file, ok := v.Documents[name]; ok {
+		if found {
+			return file + "#" + fragment
+		}
+		return file
+	}
+	return schemaRef
+}
+
+// typedArticle is one article of a document with its type and JSON pointer.
+type typedArticle struct {
+	typ     string
+	value   interface{}
+	pointer string
+}
+
+// articlesOf lists the articles of a marshaled document in order.
+func articlesOf(doc interface{}) []typedArticle {
+	var articles []typedArticle
+	switch d := doc.(type) {
+	case *xmlTools.PubmedArticleSet:
+		for i := range d.PubmedArticles {
+			articles = append(articles, typedArticle{"PubmedArticle", &d.PubmedArticles[i], fmt.Sprintf("/pubmed_articles/%d", i)})
+		}
+	case *xmlTools.PubmedBookArticleSet:
+		for i := range d.PubmedBookArticles {
+			articles = append(articles, typedArticle{"PubmedBookArticle", &d.PubmedBookArticles[i], fmt.Sprintf("/pubmed_book_articles/%d", i)})
+		}
+	case *xmlTools.PubmedArticle:
+		articles = append(articles, typedArticle{"PubmedArticle", d, ""})
+	case *xmlTools.PubmedBookArticle:
+		articles = append(articles, typedArticle{"PubmedBookArticle", d, ""})
+	case *xmlTools.PMCArticle:
+		articles = append(articles, typedArticle{"PMCArticle", d, ""})
+	}
+	return articles
+}
@@ -0,0 +1,116 @@
+package jsonTools_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/customErrors"
+	"github.com/ashahide/pubparse/internal/jsonTools"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Test: LoadValidation ------------------------
+//
+
+// doiSchema requires every article to have a DOI in its article ID list.
+const doiSchema = `{
+	"type": "object",
+	"required": ["pubmed_data"],
+	"properties": {"pubmed_data": {"type": "object", "required": ["article_id_list"],
+		"properties": {"article_id_list": {"type": "object", "required": ["article_ids"],
+			"properties": {"article_ids": {"type": "array", "contains": {
+				"type": "object", "properties": {"id_type": {"const": "doi"}}, "required": ["id_type"]}}}}}}}
+}`
+
+// writeSchemas writes name -> content files into a new directory and returns it.
+func writeSchemas(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+// TestLoadValidation checks the bundled profiles and user --schema files and
+// directories: an article without a DOI or abstract violates them, and broken or
+// unrecognized schemas are reported before any input is read.
+func TestLoadValidation(t *testing.T) {
+	set := &xmlTools.PubmedArticleSet{
+		PubmedArticles: []xmlTools.PubmedArticle{{}, {}},
+		SourceFormat:   &xmlTools.SourceFormat{RootElement: "PubmedArticleSet", Family: "pubmed"},
+	}
+	set.PubmedArticles[0].MedlineCitation.PMID = "1"
+	set.PubmedArticles[1].MedlineCitation.PMID = "2"
+
+	schemaDir := writeSchemas(t, map[string]string{"PubmedArticle.json": doiSchema, "notes.txt": "ignored"})
+	schemaFile := filepath.Join(schemaDir, "PubmedArticle.json")
+
+	tests := []struct {
+		name    string // Descriptive name for subtest
+		profile string // Validation profile
+		schema  string // --schema path
+		loadErr string // Expected substring of the LoadValidation error, "" when it loads
+		pointer string // Expected pointer of the first violation, "" when the set is valid
+	}{
+		{name: "default profile", profile: "default"},
+		{name: "unknown profile", profile: "strict", loadErr: `unknown validation profile "strict"`},
+		{name: "text-mining profile", profile: "text-mining", pointer: "/pubmed_articles/0/medline_citation/article"},
+		{name: "schema file", schema: schemaFile, pointer: "/pubmed_articles/0/pubmed_data/article_id_list/article_ids"},
+		{name: "schema directory", schema: schemaDir, pointer: "/pubmed_articles/0/pubmed_data/article_id_list/article_ids"},
+		{name: "missing schema path", schema: filepath.Join(schemaDir, "missing"), loadErr: "invalid --schema path"},
+		{name: "unrecognized schema", schema: writeSchemas(t, map[string]string{"Article.json": doiSchema}), loadErr: `unrecognized schema "Article.json"`},
+		{name: "broken schema", schema: writeSchemas(t, map[string]string{"PubmedArticle.json": `{"type": "object"`}), loadErr: "failed to compile schema"},
+		{name: "invalid schema", schema: writeSchemas(t, map[string]string{"PubmedArticle.json": `{"type": 5}`}), loadErr: "failed to compile schema"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validation, err := jsonTools.LoadValidation(test.profile, test.schema)
+			if test.loadErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.loadErr) {
+					t.Fatalf("expected error containing %q, got %v", test.loadErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadValidation failed: %v", err)
+			}
+
+			out := filepath.Join(t.TempDir(), "set.json")
+			err = jsonTools.ConvertToJSON(set, out, jsonTools.PubmedSchema, jsonTools.EncodeOptions{OnInvalid: "fail", Validation: validation})
+			var invalid *customErrors.ValidationError
+			switch {
+			case test.pointer == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case test.pointer != "" && !errors.As(err, &invalid):
+				t.Errorf("expected a ValidationError, got %v", err)
+			case test.pointer != "" && !strings.HasPrefix(invalid.Violations[0].Pointer, test.pointer):
+				t.Errorf("expected a violation at %s, got %v", test.pointer, invalid.Violations)
+			}
+		})
+	}
+}
+
+// TestLoadValidation_DocumentOverride verifies that a --schema directory holding
+// pubmed_json_schema.json replaces the bundled document schema.
+func TestLoadValidation_DocumentOverride(t *testing.T) {
+	dir := writeSchemas(t, map[string]string{jsonTools.PubmedSchema: `{"type": "object", "required": ["pubmed_articles", "extra"]}`})
+	validation, err := jsonTools.LoadValidation("", dir)
+	if err != nil {
+		t.Fatalf("LoadValidation failed: %v", err)
+	}
+
+	set := &xmlTools.PubmedArticleSet{PubmedArticles: []xmlTools.PubmedArticle{}}
+	err = jsonTools.ConvertToJSON(set, filepath.Join(t.TempDir(), "set.json"), jsonTools.PubmedSchema, jsonTools.EncodeOptions{OnInvalid: "fail", Validation: validation})
+	var invalid *customErrors.ValidationError
+	if !errors.As(err, &invalid) || !strings.Contains(invalid.Schema, dir) {
+		t.Fatalf("expected a violation of the replacement schema, got %v", err)
+	}
+}
@@ -0,0 +1,451 @@
+package jsonTools
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Rules ------------------------
+//
+
+// Severity grades a rule finding.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The record contradicts itself or its identifiers are unusable
+	SeverityWarning Severity = "warning" // The value is suspicious but may be a source quirk
+)
+
+// Finding is one rule violation in an article.
+type Finding struct {
+	Rule     string   // Rule name, e.g. "pmid-numeric"
+	Severity Severity // Severity of the rule
+	Pointer  string   // JSON pointer to the value in Document
+	Document string   // Output file holding the value; "" for the input's own output
+	Message  string   // What is wrong, quoting the value
+}
+
+// String formats the finding as "<severity> <rule> <pointer>: <message>", with the
+// pointer as a fragment of the document ("<document>#<pointer>") when it is set.
+func (f Finding) String() string {
+	pointer := f.Pointer
+	if f.Document != "" {
+		pointer = f.Document + "#" + pointer
+	}
+	return fmt.Sprintf("%s %s %s: %s", f.Severity, f.Rule, pointer, f.Message)
+}
+
+// Rule is one semantic check that JSON Schema cannot express.
+type Rule struct {
+	Name        string
+	Severity    Severity
+	Description string
+	check       func(c *ruleChecker)
+}
+
+// Rules are the semantic checks run on every parsed input, in report order.
+var Rules = []Rule{
+	{"pmid-numeric", SeverityError, "PMIDs are decimal numbers", checkPMIDs},
+	{"pmid-consistent", SeverityError, `an ArticleId of type "pubmed" equals the citation's PMID`, checkPMIDConsistency},
+	{"doi-format", SeverityWarning, `DOIs look like 10.<registrant>/<suffix>`, checkDOIs},
+	{"pmcid-format", SeverityWarning, "PMCIDs look like PMC<digits>", checkPMCIDs},
+	{"calendar-date", SeverityError, "dates are real calendar dates", checkDates},
+	{"xref-target", SeverityWarning, "reference xref rids resolve to existing ref IDs", checkXRefs},
+}
+
+/*
+CheckRuleNames validates the names given to --disable-rules.
+
+Returns:
+  - The set of disabled rule names; "all" disables every rule.
+  - An error naming the first unknown rule.
+*/
+func CheckRuleNames(names []string) (map[string]bool, error) {
+	disabled := map[string]bool{}
+	for _, name := range names {
+		if name == "all" {
+			for _, rule := range Rules {
+				disabled[rule.Name] = true
+			}
+			continue
+		}
+		known := false
+		for _, rule := range Rules {
+			known = known || rule.Name == name
+		}
+		if !known {
+			var all []string
+			for _, rule := range Rules {
+				all = append(all, rule.Name)
+			}
+			return nil, fmt.Errorf("unknown rule %q (expected all or one of: %s)", name, strings.Join(all, ", "))
+		}
+		disabled[name] = true
+	}
+	return disabled, nil
+}
+
+/*
+CheckRules runs the enabled semantic rules on a parsed input.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle.
+  - disabled: Rule names to skip, as returned by CheckRuleNames.
+
+Returns:
+  - The findings, ordered by rule (see Rules) and then by position in the document.
+    Pointers use the snake_case keys of the document before --fields projection;
+    documentFindings points them at the documents as written.
+*/
+func CheckRules(data interface{}, disabled map[string]bool) []Finding {
+	var findings []Finding
+	for _, rule := range Rules {
+		if disabled[rule.Name] {
+			continue
+		}
+		c := &ruleChecker{rule: rule, data: data}
+		rule.check(c)
+		findings = append(findings, c.findings...)
+	}
+	return findings
+}
+
+/*
+documentFindings points findings at the documents an input was written to.
+
+Parameters:
+  - findings: As returned by CheckRules for data.
+  - data: The parsed input.
+  - legacy: Whether the documents were written with --legacy-keys.
+  - splits: The per-article files of a --split input; nil otherwise.
+
+Returns:
+  - The findings, with the legacy keys in their pointers when legacy is set. For a
+    split input, each pointer is rebased onto its article's file, which becomes the
+    finding's Document; findings of articles that were not written (e.g. displaced
+    by a duplicate PMID) keep their pointers into the input's set.
+*/
+func documentFindings(findings []Finding, data interface{}, legacy bool, splits []SplitOutput) []Finding {
+	files := map[int]string{}
+	for _, split := range splits {
+		files[split.Index] = split.Path
+	}
+
+	for i := range findings {
+		f := &findings[i]
+		if legacy {
+			f.Pointer = xmlTools.LegacyPointer(f.Pointer, data)
+		}
+		// "/pubmed_articles/<index>/<pointer in the article>"
+		parts := strings.SplitN(f.Pointer, "/", 4)
+		if len(splits) == 0 || len(parts) != 4 {
+			continue
+		}
+		if index, err := strconv.Atoi(parts[2]); err == nil {
+			if path, ok := files[index]; ok {
+				f.Document, f.Pointer = path, "/"+parts[3]
+			}
+		}
+	}
+	return findings
+}
+
+// ruleChecker collects the findings of one rule.
+type ruleChecker struct {
+	rule     Rule
+	data     interface{}
+	findings []Finding
+}
+
+func (c *ruleChecker) report(pointer, format string, args ...interface{}) {
+	c.findings = append(c.findings, Finding{
+		Rule:     c.rule.Name,
+		Severity: c.rule.Severity,
+		Pointer:  pointer,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// pubmedArticles calls fn for every PubMed article with its pointer.
+func (c *ruleChecker) pubmedArticles(fn func(a *xmlTools.PubmedArticle, pointer string)) {
+	if set, ok := c.data.(*xmlTools.PubmedArticleSet); ok {
+		for i := range set.PubmedArticles {
+			fn(&set.PubmedArticles[i], fmt.Sprintf("/pubmed_articles/%d", i))
+		}
+	}
+}
+
+// bookArticles calls fn for every PubMed book article with its pointer.
+func (c *ruleChecker) bookArticles(fn func(a *xmlTools.PubmedBookArticle, pointer string)) {
+	if set, ok := c.data.(*xmlTools.PubmedBookArticleSet); ok {
+		for i := range set.PubmedBookArticles {
+			fn(&set.PubmedBookArticles[i], fmt.Sprintf("/pubmed_book_articles/%d", i))
+		}
+	}
+}
+
+// pmcArticle calls fn for a PMC article.
+func (c *ruleChecker) pmcArticle(fn func(a *xmlTools.PMCArticle)) {
+	if article, ok := c.data.(*xmlTools.PMCArticle); ok {
+		fn(article)
+	}
+}
+
+// articleIDs calls fn for every PubMed ArticleId with the given types.
+func articleIDs(list xmlTools.ArticleIdList, pointer string, fn func(value, pointer string), types ...string) {
+	for i, id := range list.ArticleIds {
+		for _, t := range types {
+			if strings.EqualFold(id.IdType, t) {
+				fn(strings.TrimSpace(id.ID), fmt.Sprintf("%s/article_ids/%d/id", pointer, i))
+			}
+		}
+	}
+}
+
+// pmcArticleIDs calls fn for every JATS article-id with the given types.
+func pmcArticleIDs(a *xmlTools.PMCArticle, fn func(value, pointer string), types ...string) {
+	for i, id := range a.Front.ArticleMeta.ArticleID {
+		for _, t := range types {
+			if strings.EqualFold(id.IDType, t) {
+				fn(strings.TrimSpace(id.Value), fmt.Sprintf("/front/article_meta/article_id/%d/value", i))
+			}
+		}
+	}
+}
+
+//
+// ------------------------ Identifier rules ------------------------
+//
+
+var (
+	pmidPattern  = regexp.MustCompile(`^[0-9]+$`)
+	doiPattern   = regexp.MustCompile(`^10\.[0-9]+(\.[0-9]+)*/\S+$`)
+	pmcidPattern = regexp.MustCompile(`^PMC[0-9]+$`)
+)
+
+func checkPMIDs(c *ruleChecker) {
+	check := func(value, pointer string) {
+		if value != "" && !pmidPattern.MatchString(value) {
+			c.report(pointer, "PMID %q is not numeric", value)
+		}
+	}
+	c.pubmedArticles(func(a *xmlTools.PubmedArticle, pointer string) {
+		check(strings.TrimSpace(a.MedlineCitation.PMID), pointer+"/medline_citation/pmid")
+		articleIDs(a.PubmedData.ArticleIdList, pointer+"/pubmed_data/article_id_list", check, "pubmed")
+	})
+	c.bookArticles(func(a *xmlTools.PubmedBookArticle, pointer string) {
+		check(strings.TrimSpace(a.BookDocument.PMID), pointer+"/book_document/pmid")
+		articleIDs(a.PubmedBookData.ArticleIdList, pointer+"/pubmed_book_data/article_id_list", check, "pubmed")
+	})
+	c.pmcArticle(func(a *xmlTools.PMCArticle) {
+		pmcArticleIDs(a, check, "pmid")
+	})
+}
+
+func checkPMIDConsistency(c *ruleChecker) {
+	check := func(pmid string) func(value, pointer string) {
+		return func(value, pointer string) {
+			if pmid != "" && value != "" && value != pmid {
+				c.report(pointer, "ArticleId %q does not match PMID %q", value, pmid)
+			}
+		}
+	}
+	c.pubmedArticles(func(a *xmlTools.PubmedArticle, pointer string) {
+		pmid := strings.TrimSpace(a.MedlineCitation.PMID)
+		articleIDs(a.PubmedData.ArticleIdList, pointer+"/pubmed_data/article_id_list", check(pmid), "pubmed")
+	})
+	c.bookArticles(func(a *xmlTools.PubmedBookArticle, pointer string) {
+		pmid := strings.TrimSpace(a.BookDocument.PMID)
+		articleIDs(a.PubmedBookData.ArticleIdList, pointer+"/pubmed_book_data/article_id_list", check(pmid), "pubmed")
+	})
+}
+
+func checkDOIs(c *ruleChecker) {
+	check := func(value, pointer string) {
+		if value != "" && !doiPattern.MatchString(value) {
+			c.report(pointer, "DOI %q does not look like 10.<registrant>/<suffix>", value)
+		}
+	}
+	c.pubmedArticles(func(a *xmlTools.PubmedArticle, pointer string) {
+		for i, id := range a.MedlineCitation.Article.ELocationIDs {
+			if strings.EqualFold(id.EIdType, "doi") {
+				check(strings.TrimSpace(id.ID), fmt.Sprintf("%s/medline_citation/article/elocation_ids/%d/id", pointer, i))
+			}
+		}
+		articleIDs(a.PubmedData.ArticleIdList, pointer+"/pubmed_data/article_id_list", check, "doi")
+	})
+	c.bookArticles(func(a *xmlTools.PubmedBookArticle, pointer string) {
+		articleIDs(a.PubmedBookData.ArticleIdList, pointer+"/pubmed_book_data/article_id_list", check, "doi")
+	})
+	c.pmcArticle(func(a *xmlTools.PMCArticle) {
+		pmcArticleIDs(a, check, "doi")
+	})
+}
+
+func checkPMCIDs(c *ruleChecker) {
+	check := func(value, pointer string) {
+		if value != "" && !pmcidPattern.MatchString(value) {
+			c.report(pointer, "PMCID %q does not look like PMC<digits>", value)
+		}
+	}
+	c.pubmedArticles(func(a *xmlTools.PubmedArticle, pointer string) {
+		articleIDs(a.PubmedData.ArticleIdList, pointer+"/pubmed_data/article_id_list", check, "pmc", "pmcid")
+	})
+	c.bookArticles(func(a *xmlTools.PubmedBookArticle, pointer string) {
+		articleIDs(a.PubmedBookData.ArticleIdList, pointer+"/pubmed_book_data/article_id_list", check, "pmc", "pmcid")
+	})
+	c.pmcArticle(func(a *xmlTools.PMCArticle) {
+		// Older JATS files give the bare number as pub-id-type "pmc"
+		pmcArticleIDs(a, func(value, pointer string) {
+			if !pmidPattern.MatchString(value) {
+				check(value, pointer)
+			}
+		}, "pmc")
+		pmcArticleIDs(a, check, "pmcid")
+	})
+}
+
+//
+// ------------------------ Date rule ------------------------
+//
+
+// monthNames maps the month abbreviations used by PubMed to month numbers.
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+/*
+calendarDateProblem checks a partial date.
+
+Parameters:
+  - year, month, day: Date parts as written in the XML; empty parts are not checked.
+    Months may be numbers or English month names ("May", "September").
+
+Returns:
+  - "" for a valid date; otherwise what is wrong with it.
+*/
+func calendarDateProblem(year, month, day string) string {
+	year, month, day = strings.TrimSpace(year), strings.TrimSpace(month), strings.TrimSpace(day)
+	if year == "" && month == "" && day == "" {
+		return ""
+	}
+
+	y, err := strconv.Atoi(year)
+	if year != "" && (err != nil || len(year) != 4) {
+		return fmt.Sprintf("year %q is not a four-digit year", year)
+	}
+
+	m := 0
+	if month != "" {
+		if n, err := strconv.Atoi(month); err == nil {
+			m = n
+		} else if len(month) >= 3 {
+			m = monthNames[strings.ToLower(month[:3])]
+		}
+		if m < 1 || m > 12 {
+			return fmt.Sprintf("month %q is not a month", month)
+		}
+	}
+
+	if day != "" {
+		d, err := strconv.Atoi(day)
+		if err != nil || d < 1 {
+			return fmt.Sprintf("day %q is not a day of the month", day)
+		}
+		if m == 0 {
+			return fmt.Sprintf("day %q is given without a month", day)
+		}
+		if year == "" {
+			y = 2000 // A leap year, so that February 29 passes without a year
+		}
+		if last := time.Date(y, time.Month(m)+1, 0, 0, 0, 0, 0, time.UTC).Day(); d > last {
+			return fmt.Sprintf("%s-%s-%s does not exist (the month has %d days)", year, month, day, last)
+		}
+	}
+	return ""
+}
+
+func checkDates(c *ruleChecker) {
+	check := func(pointer, year, month, day string) {
+		if problem := calendarDateProblem(year, month, day); problem != "" {
+			c.report(pointer, "%s", problem)
+		}
+	}
+	history := func(dates []xmlTools.PubmedPubDate, pointer string) {
+		for i, d := range dates {
+			check(fmt.Sprintf("%s/%d", pointer, i), d.Year, d.Month, d.Day)
+		}
+	}
+
+	c.pubmedArticles(func(a *xmlTools.PubmedArticle, pointer string) {
+		mc := &a.MedlineCitation
+		check(pointer+"/medline_citation/date_completed", mc.DateCompleted.Year, mc.DateCompleted.Month, mc.DateCompleted.Day)
+		check(pointer+"/medline_citation/date_revised", mc.DateRevised.Year, mc.DateRevised.Month, mc.DateRevised.Day)
+		pub := mc.Article.Journal.JournalIssue.PubDate
+		check(pointer+"/medline_citation/article/journal/journal_issue/pub_date", pub.Year, pub.Month, pub.Day)
+		history(a.PubmedData.History, pointer+"/pubmed_data/history")
+	})
+	c.bookArticles(func(a *xmlTools.PubmedBookArticle, pointer string) {
+		pub := a.BookDocument.Book.PubDate
+		check(pointer+"/book_document/book/pub_date", pub.Year, pub.Month, "")
+		history(a.PubmedBookData.History, pointer+"/pubmed_book_data/history")
+	})
+	c.pmcArticle(func(a *xmlTools.PMCArticle) {
+		for i, d := range a.Front.ArticleMeta.PubDate {
+			check(fmt.Sprintf("/front/article_meta/pub_date/%d", i), d.Year, d.Month, d.Day)
+		}
+		for i, d := range a.Front.ArticleMeta.History {
+			check(fmt.Sprintf("/front/article_meta/history/%d", i), d.Year, d.Month, d.Day)
+		}
+	})
+}
+
+//
+// ------------------------ Cross-reference rule ------------------------
+//
+
+func checkXRefs(c *ruleChecker) {
+	c.pmcArticle(func(a *xmlTools.PMCArticle) {
+		refs := map[string]bool{}
+		if a.Back != nil && a.Back.References != nil {
+			for _, ref := range a.Back.References.References {
+				refs[ref.ID] = true
+			}
+		}
+		if a.Body == nil {
+			return
+		}
+
+		var walk func(sections []xmlTools.PMCSection, pointer string)
+		walk = func(sections []xmlTools.PMCSection, pointer string) {
+			for i, sec := range sections {
+				secPointer := fmt.Sprintf("%s/%d", pointer, i)
+				for j, xref := range sec.XRefs {
+					if xref.RefType != "bibr" {
+						continue
+					}
+					var missing []string
+					for _, rid := range strings.Fields(xref.RID) {
+						if !refs[rid] {
+							missing = append(missing, rid)
+						}
+					}
+					if len(missing) > 0 {
+						sort.Strings(missing)
+						c.report(fmt.Sprintf("%s/xrefs/%d/rid", secPointer, j), "no reference with id %s", strings.Join(missing, ", "))
+					}
+				}
+				walk(sec.SubSections, secPointer+"/subsections")
+			}
+		}
+		walk(a.Body.Sections, "/body/sections")
+	})
+}
@@ -0,0 +1,216 @@
+package jsonTools_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/fileIO"
+	"github.com/ashahide/pubparse/internal/jsonTools"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Test: CheckRules ------------------------
+//
+
+// pubmedSet builds a one-article set whose identifiers and dates all pass the rules.
+func pubmedSet() *xmlTools.PubmedArticleSet {
+	set := &xmlTools.PubmedArticleSet{PubmedArticles: []xmlTools.PubmedArticle{{}}}
+	a := &set.PubmedArticles[0]
+	a.MedlineCitation.PMID = "32387127"
+	a.MedlineCitation.Article.ELocationIDs = []xmlTools.ELocationID{{ID: "10.1000/xyz.123", EIdType: "doi"}}
+	a.MedlineCitation.Article.Journal.JournalIssue.PubDate.Year = "2020"
+	a.MedlineCitation.Article.Journal.JournalIssue.PubDate.Month = "Feb"
+	a.PubmedData.ArticleIdList.ArticleIds = []xmlTools.ArticleId{
+		{ID: "32387127", IdType: "pubmed"},
+		{ID: "PMC7654321", IdType: "pmc"},
+	}
+	a.PubmedData.History = []xmlTools.PubmedPubDate{{PubStatus: "received", Year: "2020", Month: "2", Day: "29"}}
+	return set
+}
+
+// pmcArticle builds a PMC article with one section citing the given rids.
+func pmcArticle(rids ...string) *xmlTools.PMCArticle {
+	article := &xmlTools.PMCArticle{}
+	article.Front.ArticleMeta.ArticleID = []xmlTools.PMCArticleID{
+		{IDType: "pmid", Value: "32387127"},
+		{IDType: "pmc", Value: "7654321"},
+		{IDType: "doi", Value: "10.1000/xyz"},
+	}
+	article.Back = &xmlTools.PMCBack{References: &xmlTools.PMCReferences{References: []xmlTools.PMCReference{{ID: "B1"}, {ID: "B2"}}}}
+	section := xmlTools.PMCSection{}
+	for _, rid := range rids {
+		section.XRefs = append(section.XRefs, xmlTools.PMCXRef{RefType: "bibr", RID: rid})
+	}
+	article.Body = &xmlTools.PMCBody{Sections: []xmlTools.PMCSection{{SubSections: []xmlTools.PMCSection{section}}}}
+	return article
+}
+
+// TestCheckRules verifies each rule on a minimal record and that disabled rules are skipped.
+func TestCheckRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     func() interface{}
+		disabled []string
+		want     []string // Expected findings, formatted
+	}{
+		{
+			name: "valid pubmed article",
+			data: func() interface{} { return pubmedSet() },
+		},
+		{
+			name: "valid pmc article",
+			data: func() interface{} { return pmcArticle("B1", "B1 B2") },
+		},
+		{
+			name: "non-numeric pmid contradicts the pubmed article id",
+			data: func() interface{} {
+				set := pubmedSet()
+				set.PubmedArticles[0].MedlineCitation.PMID = "3238712x"
+				return set
+			},
+			want: []string{
+				`error pmid-numeric /pubmed_articles/0/medline_citation/pmid: PMID "3238712x" is not numeric`,
+				`error pmid-consistent /pubmed_articles/0/pubmed_data/article_id_list/article_ids/0/id: ArticleId "32387127" does not match PMID "3238712x"`,
+			},
+		},
+		{
+			name: "disabled rules are skipped",
+			data: func() interface{} {
+				set := pubmedSet()
+				set.PubmedArticles[0].MedlineCitation.PMID = "3238712x"
+				return set
+			},
+			disabled: []string{"pmid-numeric"},
+			want: []string{
+				`error pmid-consistent /pubmed_articles/0/pubmed_data/article_id_list/article_ids/0/id: ArticleId "32387127" does not match PMID "3238712x"`,
+			},
+		},
+		{
+			name: "all disables every rule",
+			data: func() interface{} {
+				set := pubmedSet()
+				set.PubmedArticles[0].MedlineCitation.PMID = "3238712x"
+				return set
+			},
+			disabled: []string{"all"},
+		},
+		{
+			name: "malformed doi and pmcid",
+			data: func() interface{} {
+				set := pubmedSet()
+				set.PubmedArticles[0].MedlineCitation.Article.ELocationIDs[0].ID = "doi:10.1000/xyz"
+				set.PubmedArticles[0].PubmedData.ArticleIdList.ArticleIds[1].ID = "PMC-7654321"
+				return set
+			},
+			want: []string{
+				`warning doi-format /pubmed_articles/0/medline_citation/article/elocation_ids/0/id: DOI "doi:10.1000/xyz" does not look like 10.<registrant>/<suffix>`,
+				`warning pmcid-format /pubmed_articles/0/pubmed_data/article_id_list/article_ids/1/id: PMCID "PMC-7654321" does not look like PMC<digits>`,
+			},
+		},
+		{
+			name: "impossible dates",
+			data: func() interface{} {
+				set := pubmedSet()
+				set.PubmedArticles[0].MedlineCitation.Article.Journal.JournalIssue.PubDate.Month = "Smarch"
+				set.PubmedArticles[0].PubmedData.History[0].Year = "2021"
+				return set
+			},
+			want: []string{
+				`error calendar-date /pubmed_articles/0/medline_citation/article/journal/journal_issue/pub_date: month "Smarch" is not a month`,
+				`error calendar-date /pubmed_articles/0/pubmed_data/history/0: 2021-2-29 does not exist (the month has 28 days)`,
+			},
+		},
+		{
+			name: "unresolved reference xref in a subsection",
+			data: func() interface{} { return pmcArticle("B1 B3") },
+			want: []string{
+				`warning xref-target /body/sections/0/subsections/0/xrefs/0/rid: no reference with id B3`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disabled, err := jsonTools.CheckRuleNames(tt.disabled)
+			if err != nil {
+				t.Fatalf("CheckRuleNames failed: %v", err)
+			}
+
+			var got []string
+			for _, finding := range jsonTools.CheckRules(tt.data(), disabled) {
+				got = append(got, finding.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings mismatch\n got: %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCheckRuleNames verifies that unknown rule names are rejected.
+func TestCheckRuleNames(t *testing.T) {
+	if _, err := jsonTools.CheckRuleNames([]string{"doi-format", "no-such-rule"}); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
+
+// TestProcessAllFiles_FindingPointers verifies that the report points findings at the
+// documents as written: legacy keys with --legacy-keys, and the article's own file
+// with --split.
+func TestProcessAllFiles_FindingPointers(t *testing.T) {
+	tests := []struct {
+		name   string // Descriptive name for subtest
+		legacy bool   // --legacy-keys
+		split  bool   // --split
+		want   string // Pointer of the pmid-numeric finding; %s is the output directory
+	}{
+		{"set", false, false, " /pubmed_articles/1/medline_citation/pmid: "},
+		{"legacy keys", true, false, " /PubmedArticles/1/MedlineCitation/PMID: "},
+		{"split", false, true, " %s/12a.json#/medline_citation/pmid: "},
+		{"split with legacy keys", true, true, " %s/12a.json#/MedlineCitation/PMID: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inDir, outDir := t.TempDir(), t.TempDir()
+			fin := filepath.Join(inDir, "set.xml")
+			set := `<PubmedArticleSet>` +
+				`<PubmedArticle><MedlineCitation><PMID>1</PMID><Article><ArticleTitle>T</ArticleTitle></Article></MedlineCitation></PubmedArticle>` +
+				`<PubmedArticle><MedlineCitation><PMID>12a</PMID><Article><ArticleTitle>T</ArticleTitle></Article></MedlineCitation></PubmedArticle>` +
+				`</PubmedArticleSet>`
+			if err := os.WriteFile(fin, []byte(set), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", fin, err)
+			}
+
+			args := fileIO.Arguments{Format: "json", OnInvalid: "fail", LegacyKeys: tt.legacy, Split: tt.split}
+			args.OutputPath.Path = outDir
+			args.InputPath.Files = []string{fin}
+			args.OutputPath.Files = []string{filepath.Join(outDir, "set.json")}
+
+			report, err := os.Create(filepath.Join(outDir, "report.tsv"))
+			if err != nil {
+				t.Fatalf("failed to create report: %v", err)
+			}
+			defer report.Close()
+
+			if err := jsonTools.ProcessAllFiles(context.Background(), args, "pubmed", report, 1); err != nil {
+				t.Fatalf("ProcessAllFiles failed: %v", err)
+			}
+
+			content, _ := os.ReadFile(report.Name())
+			want := ">>> Finding: " + fin + "\t error pmid-numeric" + tt.want
+			if strings.Contains(tt.want, "%s") {
+				want = fmt.Sprintf(want, outDir)
+			}
+			if !strings.Contains(string(content), want) {
+				t.Errorf("expected report to contain %q, got:\n%s", want, content)
+			}
+		})
+	}
+}
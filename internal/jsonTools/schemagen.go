@@ -0,0 +1,239 @@
+package jsonTools
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//go:generate go run ../../cmd/schemagen -o .
+
+//
+// ------------------------ GenerateSchemas ------------------------
+//
+
+/*
+GenerateSchemas builds the bundled document schemas from the xmlTools structs.
+
+Returns:
+  - The indented draft-07 schema per bundled name (PubmedSchema, PMCSchema), ending in a newline.
+  - An error if a struct uses a type the generator cannot describe.
+
+Behavior:
+  - Every named struct becomes a definition, e.g. "#/definitions/PubmedArticle", so
+    single articles can be validated against a fragment.
+  - Keys come from json tags; fields tagged json:"-" are skipped.
+  - Slices, maps and pointers without omitempty may be null, as encoding/json writes them.
+  - Fields are only required when tagged schema:"required", so adding a field does not
+    invalidate earlier output. schema:"enum=a|b" restricts a string to the listed values.
+  - Objects allow only their struct's keys, so a key that was renamed or removed is
+    reported wherever it appears.
+  - The root requires "schema_version" equal to xmlTools.SchemaVersion. Definitions do
+    not describe it; validateDocument checks it for single-article documents.
+*/
+func GenerateSchemas() (map[string][]byte, error) {
+	schemas := map[string][]byte{}
+	for name, spec := range map[string]struct {
+		title string
+		roots []reflect.Type
+	}{
+		PubmedSchema: {"PubMed XML Unified Schema", []reflect.Type{reflect.TypeOf(xmlTools.PubmedArticleSet{}), reflect.TypeOf(xmlTools.PubmedBookArticleSet{})}},
+		PMCSchema:    {"PMCArticle", []reflect.Type{reflect.TypeOf(xmlTools.PMCArticle{})}},
+	} {
+		g := &schemaGenerator{definitions: map[string]interface{}{}}
+		schema, err := g.document(spec.title, spec.roots)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		content, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		schemas[name] = append(content, '\n')
+	}
+	return schemas, nil
+}
+
+// jsonSchema is one schema object; encoding/json writes its keys sorted.
+type jsonSchema map[string]interface{}
+
+// schemaGenerator collects the definitions of one document schema.
+type schemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+// document builds the root schema: the union of the roots' fields, each root's own
+// fields required in an anyOf when there are several.
+func (g *schemaGenerator) document(title string, roots []reflect.Type) (jsonSchema, error) {
+	properties := jsonSchema{"schema_version": jsonSchema{"type": "string", "const": xmlTools.SchemaVersion}}
+	shared := map[string]int{}
+	fields := make([][]string, len(roots))
+	required := []string{"schema_version"}
+
+	for i, root := range roots {
+		object, err := g.object(root)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range object["properties"].(jsonSchema) {
+			properties[key] = value
+			shared[key]++
+			fields[i] = append(fields[i], key)
+		}
+		if req, ok := object["required"].([]string); ok {
+			required = appendMissing(required, req...)
+		}
+	}
+
+	schema := jsonSchema{
+		"$schema":              "http://json-schema.org/draft-07/schema#",
+		"title":                title,
+		"description":          "Generated from the xmlTools structs by go generate ./internal/jsonTools; do not edit.",
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+		"definitions":          g.definitions,
+	}
+	if len(roots) > 1 {
+		var anyOf []jsonSchema
+		for i := range roots {
+			var own []string
+			for _, key := range fields[i] {
+				if shared[key] == 1 {
+					own = appendMissing(own, key)
+				}
+			}
+			sort.Strings(own)
+			anyOf = append(anyOf, jsonSchema{"required": own})
+		}
+		schema["anyOf"] = anyOf
+	}
+	return schema, nil
+}
+
+// schemaOf describes a Go type, registering named structs as definitions.
+func (g *schemaGenerator) schemaOf(t reflect.Type) (jsonSchema, error) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schemaOf(t.Elem())
+	case reflect.String:
+		return jsonSchema{"type": "string"}, nil
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonSchema{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{"type": "number"}, nil
+	case reflect.Interface:
+		return jsonSchema{}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return jsonSchema{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return jsonSchema{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, ok := g.definitions[t.Name()]; !ok {
+			g.definitions[t.Name()] = nil // Reserve the name for recursive types
+			object, err := g.object(t)
+			if err != nil {
+				return nil, err
+			}
+			g.definitions[t.Name()] = object
+		}
+		return jsonSchema{"$ref": "#/definitions/" + t.Name()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// object describes a struct's fields.
+func (g *schemaGenerator) object(t reflect.Type) (jsonSchema, error) {
+	properties := jsonSchema{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+		key := tag[0]
+		if key == "" {
+			key = f.Name
+		}
+		omitempty := false
+		for _, option := range tag[1:] {
+			omitempty = omitempty || option == "omitempty"
+		}
+
+		schema, err := g.schemaOf(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+		switch f.Type.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			if !omitempty {
+				schema = nullable(schema)
+			}
+		}
+
+		for _, annotation := range strings.Split(f.Tag.Get("schema"), ",") {
+			switch {
+			case annotation == "required":
+				required = append(required, key)
+			case strings.HasPrefix(annotation, "enum="):
+				schema["enum"] = strings.Split(strings.TrimPrefix(annotation, "enum="), "|")
+			}
+		}
+		properties[key] = schema
+	}
+
+	object := jsonSchema{"type": "object", "properties": properties, "additionalProperties": false}
+	if len(required) > 0 {
+		object["required"] = required
+	}
+	return object, nil
+}
+
+// nullable lets a schema also accept null.
+func nullable(schema jsonSchema) jsonSchema {
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []string{typ, "null"}
+		return schema
+	}
+	return jsonSchema{"anyOf": []jsonSchema{schema, {"type": "null"}}}
+}
+
+func appendMissing(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			found = found || existing == v
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,86 @@
+package jsonTools_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/customErrors"
+	"github.com/ashahide/pubparse/internal/jsonTools"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Test: GenerateSchemas ------------------------
+//
+
+// TestGenerateSchemas_UpToDate fails when the checked-in schemas no longer match the
+// xmlTools structs. Regenerate them with `go generate ./internal/jsonTools`.
+func TestGenerateSchemas_UpToDate(t *testing.T) {
+	schemas, err := jsonTools.GenerateSchemas()
+	if err != nil {
+		t.Fatalf("GenerateSchemas failed: %v", err)
+	}
+
+	for _, name := range []string{jsonTools.PubmedSchema, jsonTools.PMCSchema} {
+		checkedIn, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if string(checkedIn) != string(schemas[name]) {
+			t.Errorf("%s is stale; run `go generate ./internal/jsonTools`", name)
+		}
+	}
+}
+
+// TestGenerateSchemas_Validate verifies that the generated schemas accept marshaled
+// structs and report a missing required field with its JSON pointer.
+func TestGenerateSchemas_Validate(t *testing.T) {
+	set := &xmlTools.PubmedArticleSet{
+		PubmedArticles: []xmlTools.PubmedArticle{{}},
+		SourceFormat:   &xmlTools.SourceFormat{RootElement: "PubmedArticleSet", Family: "pubmed"},
+	}
+	set.PubmedArticles[0].MedlineCitation.PMID = "1"
+
+	pmc := &xmlTools.PMCArticle{ArticleType: "research-article"}
+	book := &xmlTools.PubmedBookArticleSet{PubmedBookArticles: []xmlTools.PubmedBookArticle{{}}}
+
+	tests := []struct {
+		name    string      // Descriptive name for subtest
+		value   interface{} // Marshaled document
+		schema  string      // Schema reference
+		pointer string      // Expected pointer of the first violation, "" when valid
+	}{
+		{"pubmed set", set, jsonTools.PubmedSchema, ""},
+		{"pubmed book set", book, jsonTools.PubmedSchema, ""},
+		{"pmc article", pmc, jsonTools.PMCSchema, ""},
+		{"single article", &set.PubmedArticles[0], jsonTools.PubmedSchema + "#/definitions/PubmedArticle", ""},
+		{"bad family", &xmlTools.PubmedArticleSet{SourceFormat: &xmlTools.SourceFormat{Family: "other"}}, jsonTools.PubmedSchema, "/source_format/family"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			raw, err := json.Marshal(test.value)
+			if err != nil {
+				t.Fatalf("marshal failed: %v", err)
+			}
+			if !strings.Contains(test.schema, "#") {
+				// Definitions do not describe the schema version of the document
+				raw = xmlTools.WithSchemaVersion(raw, xmlTools.SchemaVersion)
+			}
+			err = jsonTools.ValidateJsonBytesAgainstSchema(raw, test.schema)
+
+			var invalid *customErrors.ValidationError
+			switch {
+			case test.pointer == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case test.pointer != "" && !errors.As(err, &invalid):
+				t.Errorf("expected a ValidationError, got %v", err)
+			case test.pointer != "" && invalid.Violations[0].Pointer != test.pointer:
+				t.Errorf("expected violation at %s, got %v", test.pointer, invalid.Violations)
+			}
+		})
+	}
+}
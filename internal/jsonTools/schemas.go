@@ -0,0 +1,79 @@
+package jsonTools
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+//
+// ------------------------ Embedded schemas ------------------------
+//
+
+// Names of the bundled document schemas. Schema references are a name, optionally
+// with a fragment, e.g. PubmedSchema + "#/definitions/PubmedArticle". The validation
+// profiles are bundled as profiles/<profile>/<type>.json.
+const (
+	PubmedSchema = "pubmed_json_schema.json"
+	PMCSchema    = "pmc_json_schema.json"
+)
+
+//go:embed pubmed_json_schema.json pmc_json_schema.json profiles
+var embeddedSchemas embed.FS
+
+// compiled caches every schema reference compiled during the run.
+var compiled = struct {
+	sync.Mutex
+	schemas map[string]*gojsonschema.Schema
+}{schemas: map[string]*gojsonschema.Schema{}}
+
+/*
+compiledSchema returns the compiled schema for a reference, compiling it on first use.
+
+Parameters:
+  - ref: A bundled schema name (PubmedSchema, PMCSchema, profiles/...) or a path to a schema
+    file on disk, optionally followed by a "#/..." fragment.
+
+Returns:
+  - The compiled schema, shared by all workers.
+  - An error if the schema cannot be loaded or compiled.
+*/
+func compiledSchema(ref string) (*gojsonschema.Schema, error) {
+	compiled.Lock()
+	defer compiled.Unlock()
+
+	if schema, ok := compiled.schemas[ref]; ok {
+		return schema, nil
+	}
+
+	schema, err := gojsonschema.NewSchema(schemaLoader(ref))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema %q: %w", ref, err)
+	}
+	compiled.schemas[ref] = schema
+	return schema, nil
+}
+
+// schemaLoader resolves bundled names against the embedded files and anything
+// else against the file system.
+func schemaLoader(ref string) gojsonschema.JSONLoader {
+	name, fragment, _ := strings.Cut(ref, "#")
+	if fragment != "" {
+		fragment = "#" + fragment
+	}
+
+	if _, err := embeddedSchemas.Open(name); err == nil {
+		return gojsonschema.NewReferenceLoaderFileSystem("file:///"+name+fragment, http.FS(embeddedSchemas))
+	}
+
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		abs = name
+	}
+	return gojsonschema.NewReferenceLoader("file://" + filepath.ToSlash(abs) + fragment)
+}
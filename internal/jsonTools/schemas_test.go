@@ -0,0 +1,71 @@
+package jsonTools_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/customErrors"
+	"github.com/ashahide/pubparse/internal/jsonTools"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Test: Embedded schemas ------------------------
+//
+
+// chdir changes the working directory for the rest of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("failed to change to %s: %v", dir, err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// TestConvertToJSON_EmbeddedSchemas verifies that documents are validated against the
+// embedded schemas, with no schema file in the working directory, and that an invalid
+// document is rejected before anything is written.
+func TestConvertToJSON_EmbeddedSchemas(t *testing.T) {
+	dir := t.TempDir()
+	chdir(t, dir)
+
+	valid := &xmlTools.PubmedArticleSet{
+		PubmedArticles: []xmlTools.PubmedArticle{{MedlineCitation: xmlTools.MedlineCitation{PMID: "1"}}},
+		SourceFormat:   &xmlTools.SourceFormat{RootElement: "PubmedArticleSet", Family: "pubmed"},
+	}
+	invalid := &xmlTools.PubmedArticleSet{SourceFormat: &xmlTools.SourceFormat{Family: "other"}}
+
+	tests := []struct {
+		name   string      // Descriptive name for subtest
+		value  interface{} // Document to write
+		schema string      // Schema reference
+		valid  bool        // Whether the document passes and is written
+	}{
+		{"pubmed set", valid, jsonTools.PubmedSchema, true},
+		{"single article", &valid.PubmedArticles[0], jsonTools.PubmedSchema + "#/definitions/PubmedArticle", true},
+		{"pmc article", &xmlTools.PMCArticle{ArticleType: "research-article"}, jsonTools.PMCSchema, true},
+		{"invalid set", invalid, jsonTools.PubmedSchema, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := filepath.Join(dir, "out.json")
+			os.Remove(out)
+
+			err := jsonTools.ConvertToJSON(test.value, out, test.schema, jsonTools.EncodeOptions{OnInvalid: "fail"})
+			var violations *customErrors.ValidationError
+			if test.valid && err != nil || !test.valid && !errors.As(err, &violations) {
+				t.Fatalf("expected valid: %t, got %v", test.valid, err)
+			}
+			if _, err := os.Stat(out); (err == nil) != test.valid {
+				t.Errorf("expected the document to be written: %t, got %v", test.valid, err)
+			}
+		})
+	}
+}
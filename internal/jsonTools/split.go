@@ -0,0 +1,294 @@
+package jsonTools
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/ashahide/pubparse/internal/customErrors"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+// SplitOutput maps one article of a multi-article input to the file it was written to.
+type SplitOutput struct {
+	PMID    string
+	Index   int // Position of the article in its input
+	Path    string
+	Invalid *customErrors.ValidationError // Schema violations of a document kept by --on-invalid warn or quarantine
+}
+
+// SplitCollision records two articles of a run that named the same split output file.
+type SplitCollision struct {
+	PMID    string
+	Path    string
+	Kept    string // The article whose document the file holds
+	Dropped string // The article that was not written or was overwritten
+}
+
+// unsafeFileChars matches characters not allowed in split output file names.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+//
+// ------------------------ splitFileName ------------------------
+//
+
+/*
+splitFileName builds the output file name for one article.
+
+Parameters:
+  - pmid: The article's PMID (may be empty for malformed records).
+  - version: The citation version; only used when versioned is true.
+  - versioned: Whether to include the version (<pmid>.v<version>.json).
+  - fallback: Name used when the PMID is missing (e.g. "<input>_<index>").
+
+Returns:
+  - A file name safe to join with the output directory.
+*/
+func splitFileName(pmid, version string, versioned bool, fallback string) string {
+	name := strings.TrimSpace(pmid)
+	if name == "" {
+		name = "unknown_" + fallback
+	}
+	if versioned {
+		if version = strings.TrimSpace(version); version == "" {
+			version = "1"
+		}
+		name += ".v" + version
+	}
+	return unsafeFileChars.ReplaceAllString(name, "_") + ".json"
+}
+
+//
+// ------------------------ splitArticles ------------------------
+//
+
+// articleDoc is one article of a parsed input, ready to be written as its own document.
+type articleDoc struct {
+	Value   interface{} // *xmlTools.PubmedArticle, *xmlTools.PubmedBookArticle or *xmlTools.PMCArticle
+	PMID    string      // PMID (PubMed) or PMCID (PMC); may be empty
+	Version string      // Citation version (PubMed only)
+	Schema  string      // Schema reference validating a single article
+}
+
+/*
+splitArticles normalizes a parsed input and lists its articles individually.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet or *xmlTools.PMCArticle.
+
+Returns:
+  - One articleDoc per article, in input order. A PMC file yields a single article.
+  - An error for unsupported types.
+
+Behavior:
+  - PubMed articles are validated against the article definitions of the PubMed schema
+    ("#/definitions/PubmedArticle" and "#/definitions/PubmedBookArticle").
+  - PubMed articles get the set's source format, so each document records its DTD.
+*/
+func splitArticles(data interface{}) ([]articleDoc, error) {
+	var docs []articleDoc
+
+	switch v := data.(type) {
+	case *xmlTools.PubmedArticleSet:
+		xmlTools.NormalizePubmedArticleSet(v)
+		for i := range v.PubmedArticles {
+			v.PubmedArticles[i].SourceFormat = v.SourceFormat
+			citation := v.PubmedArticles[i].MedlineCitation
+			docs = append(docs, articleDoc{
+				Value:   &v.PubmedArticles[i],
+				PMID:    citation.PMID,
+				Version: citation.VersionID,
+				Schema:  PubmedSchema + "#/definitions/PubmedArticle",
+			})
+		}
+
+	case *xmlTools.PubmedBookArticleSet:
+		xmlTools.NormalizePubmedArticleSet(v)
+		for i := range v.PubmedBookArticles {
+			v.PubmedBookArticles[i].SourceFormat = v.SourceFormat
+			docs = append(docs, articleDoc{
+				Value:  &v.PubmedBookArticles[i],
+				PMID:   v.PubmedBookArticles[i].BookDocument.PMID,
+				Schema: PubmedSchema + "#/definitions/PubmedBookArticle",
+			})
+		}
+
+	case *xmlTools.PMCArticle:
+		xmlTools.NormalizePMCArticle(v)
+		var pmcid string
+		for _, id := range v.Front.ArticleMeta.ArticleID {
+			if id.IDType == "pmc" || id.IDType == "pmcid" {
+				pmcid = id.Value
+			}
+		}
+		docs = append(docs, articleDoc{
+			Value:  v,
+			PMID:   pmcid,
+			Schema: PMCSchema,
+		})
+
+	default:
+		return nil, fmt.Errorf("unsupported data type for serialization")
+	}
+
+	return docs, nil
+}
+
+//
+// ------------------------ splitClaims ------------------------
+//
+
+// splitClaim identifies the article written to a split output file.
+type splitClaim struct {
+	fin     string
+	input   int // Index of the input in the run
+	article int // Index of the article in its input
+	version int // Citation version, 1 when absent
+}
+
+// outranks reports whether c replaces o in their file: the higher citation version
+// wins, then the later article in input order (an update file supersedes the
+// baseline), so the outcome does not depend on which worker finishes first.
+func (c splitClaim) outranks(o splitClaim) bool {
+	if c.version != o.version {
+		return c.version > o.version
+	}
+	if c.input != o.input {
+		return c.input > o.input
+	}
+	return c.article > o.article
+}
+
+func (c splitClaim) String() string {
+	return fmt.Sprintf("%s article %d version %d", c.fin, c.article+1, c.version)
+}
+
+// splitClaims tracks the split output files written by a run, shared by its workers.
+type splitClaims struct {
+	mu    sync.Mutex
+	files map[string]*splitFile // Keyed by output path
+}
+
+// splitFile is one split output file; mu is held while it is written.
+type splitFile struct {
+	mu    sync.Mutex
+	claim *splitClaim // nil until written
+}
+
+func newSplitClaims() *splitClaims {
+	return &splitClaims{files: map[string]*splitFile{}}
+}
+
+// file returns the entry of an output path, creating it on first use.
+func (sc *splitClaims) file(path string) *splitFile {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	f, ok := sc.files[path]
+	if !ok {
+		f = &splitFile{}
+		sc.files[path] = f
+	}
+	return f
+}
+
+//
+// ------------------------ writeSplitArticles ------------------------
+//
+
+/*
+writeSplitArticles writes every article of a PubMed set to its own JSON file.
+
+Parameters:
+  - data: *xmlTools.PubmedArticleSet or *xmlTools.PubmedBookArticleSet.
+  - fin: Input file the articles came from (used for fallback names).
+  - input: Index of fin in the run's input list.
+  - outputDir: Directory receiving the <pmid>.json files.
+  - versioned: Name files <pmid>.v<version>.json, using MedlineCitation's VersionID (1 when absent).
+  - opts: Projection and key naming of the written documents.
+  - claims: The files written so far by the run.
+
+Behavior:
+  - Normalizes the set, then writes each article as a bare JSON object.
+  - Each document is validated against the article definition of the PubMed schema,
+    unless it is projected, before any file is written: under the "fail" policy an
+    invalid article rejects the whole input and nothing is written. Invalid articles
+    kept by opts.OnInvalid are listed with their violations.
+  - When an article names a file already written by the run (a duplicate PMID in
+    this or another input), the article that outranks the other (see
+    splitClaim.outranks) is kept; writes to one file never overlap.
+
+Returns:
+  - One SplitOutput per article written, in input order.
+  - One SplitCollision per article that displaced or was displaced by another.
+  - The first error encountered; a *customErrors.ValidationError when an article
+    is rejected under the "fail" policy.
+*/
+func writeSplitArticles(data interface{}, fin string, input int, outputDir string, versioned bool, opts EncodeOptions, claims *splitClaims) ([]SplitOutput, []SplitCollision, error) {
+	switch data.(type) {
+	case *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet:
+	default:
+		return nil, nil, fmt.Errorf("split output is only supported for PubMed article sets, got %T", data)
+	}
+
+	docs, err := splitArticles(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Validate every article before writing any, so a rejected input leaves no files behind
+	base := strings.TrimSuffix(filepath.Base(fin), filepath.Ext(fin))
+	paths := make([]string, len(docs))
+	checked := make([]checkedDocument, len(docs))
+	for i, doc := range docs {
+		paths[i] = filepath.Join(outputDir, splitFileName(doc.PMID, doc.Version, versioned, fmt.Sprintf("%s_%d", base, i)))
+		if checked[i], err = checkDocument(doc.Value, doc.Schema, opts); err != nil {
+			return nil, nil, fmt.Errorf("failed to write article %q: %w", doc.PMID, err)
+		}
+		if invalid := checked[i].invalid; invalid != nil && !keepsInvalid(opts.OnInvalid) {
+			invalid.File = paths[i]
+			return nil, nil, fmt.Errorf("failed to write article %q: %w", doc.PMID, invalid)
+		}
+	}
+
+	var outputs []SplitOutput
+	var collisions []SplitCollision
+	for i, doc := range docs {
+		path := paths[i]
+		claim := splitClaim{fin: fin, input: input, article: i, version: xmlTools.CitationVersion(doc.Version)}
+
+		file := claims.file(path)
+		file.mu.Lock()
+		prev := file.claim
+		if prev != nil && prev.outranks(claim) {
+			file.mu.Unlock()
+			collisions = append(collisions, SplitCollision{PMID: doc.PMID, Path: path, Kept: prev.String(), Dropped: claim.String()})
+			continue
+		}
+
+		err := checked[i].write(path, opts.OnInvalid)
+		var invalid *customErrors.ValidationError
+		if err != nil && !errors.As(err, &invalid) {
+			file.mu.Unlock()
+			return outputs, collisions, fmt.Errorf("failed to write article %q: %w", doc.PMID, err)
+		}
+		written := path
+		if invalid != nil {
+			written = invalid.File
+		}
+		// A quarantined document leaves the file to the article that wrote it
+		if written == path {
+			if prev != nil {
+				collisions = append(collisions, SplitCollision{PMID: doc.PMID, Path: path, Kept: claim.String(), Dropped: prev.String()})
+			}
+			file.claim = &claim
+		}
+		file.mu.Unlock()
+		outputs = append(outputs, SplitOutput{PMID: doc.PMID, Index: i, Path: written, Invalid: invalid})
+	}
+
+	return outputs, collisions, nil
+}
@@ -0,0 +1,148 @@
+package jsonTools_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/customErrors"
+	"github.com/ashahide/pubparse/internal/fileIO"
+	"github.com/ashahide/pubparse/internal/jsonTools"
+)
+
+//
+// ------------------------ Test: ProcessAllFiles with --split ------------------------
+//
+
+// splitArticle returns a PubmedArticle element; an empty pmid leaves the PMID out.
+func splitArticle(pmid, version, title string) string {
+	var id string
+	if pmid != "" {
+		id = "<PMID>" + pmid + "</PMID>"
+	}
+	return `<PubmedArticle><MedlineCitation VersionID="` + version + `">` + id +
+		`<Article><ArticleTitle>` + title + `</ArticleTitle></Article></MedlineCitation></PubmedArticle>`
+}
+
+// TestProcessAllFiles_Split verifies the split file names and that articles naming the
+// same file, within one input or across inputs, are resolved by version and then by
+// input order whatever the order the workers finish in, with each collision reported.
+func TestProcessAllFiles_Split(t *testing.T) {
+	inputs := []struct{ name, articles string }{
+		{"a", splitArticle("10", "1", "A1") + splitArticle("10", "2", "A2") + splitArticle("", "1", "A3") + splitArticle("x/1", "1", "A4")},
+		{"b", splitArticle("10", "1", "B1") + splitArticle("11", "", "B2")},
+		{"c", splitArticle("11", "1", "C1")},
+	}
+
+	tests := []struct {
+		name       string
+		versioned  bool              // --split-versions
+		files      map[string]string // Output file name -> title it must hold
+		collisions int               // Expected ">>> Duplicate PMID:" lines
+	}{
+		{"by pmid", false, map[string]string{
+			"10.json":          "A2",
+			"11.json":          "C1",
+			"unknown_a_2.json": "A3",
+			"x_1.json":         "A4",
+		}, 3},
+		{"by pmid and version", true, map[string]string{
+			"10.v1.json":          "B1",
+			"10.v2.json":          "A2",
+			"11.v1.json":          "C1",
+			"unknown_a_2.v1.json": "A3",
+			"x_1.v1.json":         "A4",
+		}, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inDir, outDir := t.TempDir(), t.TempDir()
+
+			args := fileIO.Arguments{Format: "json", OnInvalid: "fail", Split: true, SplitVersions: test.versioned}
+			args.OutputPath.Path = outDir
+			for _, input := range inputs {
+				path := filepath.Join(inDir, input.name+".xml")
+				if err := os.WriteFile(path, []byte("<PubmedArticleSet>"+input.articles+"</PubmedArticleSet>"), 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", path, err)
+				}
+				args.InputPath.Files = append(args.InputPath.Files, path)
+				args.OutputPath.Files = append(args.OutputPath.Files, filepath.Join(outDir, input.name+".json"))
+			}
+
+			report, err := os.Create(filepath.Join(outDir, "report.tsv"))
+			if err != nil {
+				t.Fatalf("failed to create report: %v", err)
+			}
+			defer report.Close()
+
+			if err := jsonTools.ProcessAllFiles(context.Background(), args, "pubmed", report, 3); err != nil {
+				t.Fatalf("ProcessAllFiles failed: %v", err)
+			}
+
+			entries, _ := os.ReadDir(outDir)
+			var names []string
+			for _, entry := range entries {
+				if strings.HasSuffix(entry.Name(), ".json") {
+					names = append(names, entry.Name())
+				}
+			}
+			if len(names) != len(test.files) {
+				t.Errorf("expected files %v, got %v", test.files, names)
+			}
+			for name, title := range test.files {
+				content, err := os.ReadFile(filepath.Join(outDir, name))
+				if err != nil {
+					t.Errorf("expected %s to be written: %v", name, err)
+					continue
+				}
+				if !strings.Contains(string(content), `"article_title":"`+title+`"`) {
+					t.Errorf("expected %s to hold article %s, got %s", name, title, content)
+				}
+				if !strings.Contains(string(content), `"source_format":{`) {
+					t.Errorf("expected %s to carry the source format, got %s", name, content)
+				}
+			}
+
+			content, _ := os.ReadFile(report.Name())
+			if got := strings.Count(string(content), ">>> Duplicate PMID: "); got != test.collisions {
+				t.Errorf("expected %d collisions in the report, got %d:\n%s", test.collisions, got, content)
+			}
+		})
+	}
+}
+
+// TestProcessAllFiles_SplitRejected verifies that under --on-invalid fail an invalid
+// article rejects its whole input before any of its articles is written.
+func TestProcessAllFiles_SplitRejected(t *testing.T) {
+	inDir, outDir := t.TempDir(), t.TempDir()
+	fin := filepath.Join(inDir, "a.xml")
+	// The first article has the abstract the text-mining profile requires, the second does not
+	content := "<PubmedArticleSet>" + abstractArticle("10") + splitArticle("11", "1", "A2") + "</PubmedArticleSet>"
+	if err := os.WriteFile(fin, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", fin, err)
+	}
+
+	args := fileIO.Arguments{Format: "json", OnInvalid: "fail", Profile: "text-mining", Split: true}
+	args.OutputPath.Path = outDir
+	args.InputPath.Files = []string{fin}
+	args.OutputPath.Files = []string{filepath.Join(outDir, "a.json")}
+
+	err := jsonTools.ProcessAllFiles(context.Background(), args, "pubmed", nil, 1)
+	var invalid *customErrors.ValidationError
+	if !errors.As(err, &invalid) || invalid.File != filepath.Join(outDir, "11.json") {
+		t.Fatalf("expected a validation error for 11.json, got %v", err)
+	}
+
+	entries, _ := os.ReadDir(outDir)
+	if len(entries) != 0 {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("expected no files to be written, got %v", names)
+	}
+}
@@ -0,0 +1,247 @@
+package jsonTools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ashahide/pubparse/internal/customErrors"
+	"github.com/ashahide/pubparse/internal/fileIO"
+	"github.com/ashahide/pubparse/internal/makeReports"
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ ValidateOutputFile ------------------------
+//
+
+// FileValidation is the outcome of validating one existing output file.
+type FileValidation struct {
+	File      string
+	Documents int                           // Documents validated (lines of a .jsonl file)
+	Skipped   bool                          // Not pubparse output, e.g. CSL-JSON or BioC
+	Invalid   *customErrors.ValidationError // Schema violations, with Line set for .jsonl files
+	Err       error                         // The file could not be read or decoded; the message does not repeat File
+}
+
+/*
+ValidateOutputFile validates an existing pubparse .json or .jsonl file without
+re-parsing its XML input.
+
+Parameters:
+  - path: The file to validate.
+  - v: Profile and --schema schemas, as returned by LoadValidation; nil for the bundled ones only.
+
+Behavior:
+  - Recognizes each document by its top-level keys and validates the bytes as written
+    against the schema it was validated against when written, so keys that the current
+    schema no longer allows are reported.
+  - Legacy-key documents (--legacy-keys) are validated in their snake_case form, as at write time.
+  - A file whose first document is well-formed JSON but not pubparse output is skipped;
+    a later unrecognized line of a .jsonl file, and malformed or truncated JSON
+    anywhere, is a failure.
+  - Single articles (--split and .jsonl output) have their schema_version checked too.
+
+Returns:
+  - The outcome for the file.
+*/
+func ValidateOutputFile(path string, v *Validation) FileValidation {
+	result := FileValidation{File: path}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	jsonl := strings.EqualFold(filepath.Ext(path), ".jsonl")
+	documents := [][]byte{content}
+	if jsonl {
+		documents = bytes.Split(content, []byte("\n"))
+	}
+
+	for i, document := range documents {
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+		line := 0
+		if jsonl {
+			line = i + 1
+		}
+
+		err := validateOutputDocument(document, v)
+		if errors.Is(err, errUnrecognizedDocument) && result.Documents == 0 && result.Invalid == nil {
+			result.Skipped = true
+			return result
+		}
+
+		var invalid *customErrors.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			if result.Invalid == nil {
+				result.Invalid = &customErrors.ValidationError{File: path, Schema: invalid.Schema}
+			}
+			for _, violation := range invalid.Violations {
+				violation.Line = line
+				result.Invalid.Violations = append(result.Invalid.Violations, violation)
+			}
+		case err != nil && line > 0:
+			result.Err = fmt.Errorf("line %d: %w", line, err)
+			return result
+		case err != nil:
+			result.Err = err
+			return result
+		}
+		result.Documents++
+	}
+	return result
+}
+
+// validateOutputDocument validates one written document. A document that does not
+// decode into the current structs is still checked against its document schema,
+// whose violations explain the mismatch better than the decoding error.
+func validateOutputDocument(raw []byte, v *Validation) error {
+	doc, schemaRef, legacy, decodeErr := decodeDocumentJSON(raw)
+	if errors.Is(decodeErr, errUnrecognizedDocument) {
+		return decodeErr
+	}
+
+	canonical := raw
+	if legacy {
+		snake, err := xmlTools.FromLegacyKeys(raw, doc)
+		if err != nil {
+			return err
+		}
+		canonical = xmlTools.WithSchemaVersion(snake, xmlTools.SchemaVersion)
+	}
+	if decodeErr != nil {
+		doc = nil // Per-article schemas need the decoded articles
+	}
+	if err := validateDocument(doc, canonical, schemaRef, v); err != nil {
+		return err
+	}
+	return decodeErr
+}
+
+//
+// ------------------------ ValidateAllFiles ------------------------
+//
+
+/*
+ValidateAllFiles validates existing output files with parallel workers.
+
+Parameters:
+  - args: args.InputPath.Files lists the .json/.jsonl files; args.Profile and
+    args.SchemaPath select extra schemas as for conversion.
+  - workers: Number of files validated at once.
+
+Behavior:
+  - Uses the same semaphore, WaitGroup and progress tracker as ProcessAllFiles.
+  - A failing file never stops the others.
+
+Returns:
+  - One outcome per file, in input order.
+  - An error if the profile or --schema schemas cannot be loaded.
+*/
+func ValidateAllFiles(args fileIO.Arguments, workers int) ([]FileValidation, error) {
+	validation, err := LoadValidation(args.Profile, args.SchemaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	results := make([]FileValidation, len(args.InputPath.Files))
+
+	var wg sync.WaitGroup
+	var doneCount int32
+	stopCh := make(chan struct{})
+
+	// Start progress tracker in background
+	go makeReports.TrackProgress(len(args.InputPath.Files), &doneCount, startTime, stopCh)
+
+	sema := make(chan struct{}, workers) // concurrency limiter
+
+	for i, path := range args.InputPath.Files {
+		wg.Add(1)
+		sema <- struct{}{} // acquire slot
+
+		go func(i int, path string) {
+			defer wg.Done()
+			defer func() { <-sema }() // release slot
+
+			results[i] = ValidateOutputFile(path, validation)
+			atomic.AddInt32(&doneCount, 1)
+		}(i, path)
+	}
+
+	wg.Wait()
+	close(stopCh)
+	return results, nil
+}
+
+//
+// ------------------------ WriteValidationFailures ------------------------
+//
+
+// ValidationFailure is one line of the failure list written by `pubparse validate`.
+type ValidationFailure struct {
+	File        string `json:"file"`
+	Line        int    `json:"line,omitempty"` // 1-based line of a .jsonl file
+	Schema      string `json:"schema,omitempty"`
+	Pointer     string `json:"pointer"` // JSON pointer into the document; "" is the root
+	Description string `json:"description"`
+}
+
+/*
+WriteValidationFailures writes the failures of a validation run as JSON Lines.
+
+Parameters:
+  - results: Outcomes returned by ValidateAllFiles.
+  - path: The failure list to create; an existing list is replaced, so an empty
+    file means every file passed.
+
+Behavior:
+  - Writes one line per violation, and one line (with the error as description) per
+    file that could not be read or decoded.
+
+Returns:
+  - An error if the file cannot be written; otherwise nil.
+*/
+func WriteValidationFailures(results []FileValidation, path string) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	for _, result := range results {
+		if result.Invalid != nil {
+			for _, violation := range result.Invalid.Violations {
+				failure := ValidationFailure{
+					File:        result.File,
+					Line:        violation.Line,
+					Schema:      result.Invalid.Schema,
+					Pointer:     violation.Pointer,
+					Description: violation.Description,
+				}
+				if err := enc.Encode(failure); err != nil {
+					return err
+				}
+			}
+		}
+		if result.Err != nil {
+			if err := enc.Encode(ValidationFailure{File: result.File, Description: result.Err.Error()}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write failure list %q: %w", path, err)
+	}
+	return nil
+}
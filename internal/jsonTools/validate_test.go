@@ -0,0 +1,168 @@
+package jsonTools_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/jsonTools"
+)
+
+//
+// ------------------------ Test: ValidateOutputFile ------------------------
+//
+
+// TestValidateOutputFile verifies that written documents are validated as they are on
+// disk, that JSON Lines violations carry their line and that other JSON is skipped.
+func TestValidateOutputFile(t *testing.T) {
+	const article = `{"medline_citation":{"pmid":"1","article":{"article_title":"Title"}}}`
+
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		documents int    // Expected number of validated documents
+		skipped   bool   // Expect the file to be skipped
+		invalid   bool   // Expect schema violations
+		pointer   string // Expected pointer of the first violation; "" is the root
+		line      int    // Expected line of the first violation
+		err       bool   // Expect a read or decode error
+	}{
+		{
+			name:      "valid set",
+			file:      "set.json",
+			content:   `{"schema_version":"3.0","pubmed_articles":[` + article + `]}`,
+			documents: 1,
+		},
+		{
+			name:      "legacy keys",
+			file:      "legacy.json",
+			content:   `{"schema_version":"1.0","PubmedArticles":[{"MedlineCitation":{"PMID":"1","Article":{"ArticleTitle":"Title"}}}]}`,
+			documents: 1,
+		},
+		{
+			name:      "key no longer allowed",
+			file:      "old.json",
+			content:   `{"schema_version":"3.0","pubmed_articles":[` + article + `],"removed_key":1}`,
+			documents: 1,
+			invalid:   true,
+		},
+		{
+			name:      "abstract as a single string",
+			file:      "abstract.json",
+			content:   `{"schema_version":"3.0","pubmed_articles":[{"medline_citation":{"pmid":"1","article":{"abstract":{"abstract_text":"Text"}}}}]}`,
+			documents: 1,
+			invalid:   true,
+			pointer:   "/pubmed_articles/0/medline_citation/article/abstract/abstract_text",
+		},
+		{
+			name:      "key no longer allowed below the root",
+			file:      "nested.json",
+			content:   `{"schema_version":"3.0","pubmed_articles":[{"medline_citation":{"pmid":"1","removed_key":1}}]}`,
+			documents: 1,
+			invalid:   true,
+			pointer:   "/pubmed_articles/0/medline_citation",
+		},
+		{
+			name:      "old schema version of a single article",
+			file:      "version.jsonl",
+			content:   `{"schema_version":"2.0",` + article[1:] + "\n",
+			documents: 1,
+			invalid:   true,
+			pointer:   "/schema_version",
+			line:      1,
+		},
+		{
+			name:    "truncated document",
+			file:    "truncated.json",
+			content: `{"schema_version":"3.0","pubmed_articles":[` + article,
+			err:     true,
+		},
+		{
+			name:    "malformed first line",
+			file:    "malformed.jsonl",
+			content: `{"schema_version":"3.0",` + article[1:len(article)-2] + "\n" + `{"schema_version":"3.0",` + article[1:] + "\n",
+			err:     true,
+		},
+		{
+			name:      "wrong type is a violation, not a decoding error",
+			file:      "lines.jsonl",
+			content:   `{"schema_version":"3.0",` + article[1:] + "\n" + `{"schema_version":"3.0","medline_citation":{"pmid":2,"article":{}}}` + "\n",
+			documents: 2,
+			invalid:   true,
+			pointer:   "/medline_citation/pmid",
+			line:      2,
+		},
+		{
+			name:      "unrecognized line after articles",
+			file:      "mixed.jsonl",
+			content:   `{"schema_version":"3.0",` + article[1:] + "\n" + `{"chunk_id":"x"}` + "\n",
+			documents: 1,
+			err:       true,
+		},
+		{
+			name:    "not pubparse output",
+			file:    "set.csl.json",
+			content: `[{"id":"pmid:1","type":"article-journal"}]`,
+			skipped: true,
+		},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", path, err)
+			}
+
+			result := jsonTools.ValidateOutputFile(path, nil)
+			if result.Skipped != tt.skipped || result.Documents != tt.documents || (result.Err != nil) != tt.err {
+				t.Fatalf("unexpected result: skipped=%v documents=%d err=%v", result.Skipped, result.Documents, result.Err)
+			}
+
+			switch {
+			case !tt.invalid && result.Invalid != nil:
+				t.Errorf("unexpected violations: %v", result.Invalid)
+			case tt.invalid && result.Invalid == nil:
+				t.Errorf("expected a violation at %q", tt.pointer)
+			case tt.invalid:
+				first := result.Invalid.Violations[0]
+				if first.Pointer != tt.pointer || first.Line != tt.line {
+					t.Errorf("expected violation at line %d %q, got %v", tt.line, tt.pointer, result.Invalid.Violations)
+				}
+			}
+		})
+	}
+}
+
+// TestWriteValidationFailures verifies that the failure list holds one JSON line per
+// violation and is emptied when every file passes.
+func TestWriteValidationFailures(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"schema_version":"1.5","pubmed_articles":[],"removed_key":1}`), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", bad, err)
+	}
+	list := filepath.Join(dir, "failures.jsonl")
+
+	results := []jsonTools.FileValidation{jsonTools.ValidateOutputFile(bad, nil)}
+	if err := jsonTools.WriteValidationFailures(results, list); err != nil {
+		t.Fatalf("WriteValidationFailures failed: %v", err)
+	}
+	content, _ := os.ReadFile(list)
+	if lines := strings.Count(string(content), "\n"); lines != 2 {
+		t.Errorf("expected 2 failure lines, got %d:\n%s", lines, content)
+	}
+	if !strings.Contains(string(content), `"file":"`+bad+`"`) {
+		t.Errorf("expected the failure list to name %s, got %s", bad, content)
+	}
+
+	if err := jsonTools.WriteValidationFailures(nil, list); err != nil {
+		t.Fatalf("WriteValidationFailures failed: %v", err)
+	}
+	if content, _ := os.ReadFile(list); len(content) != 0 {
+		t.Errorf("expected an empty failure list, got %s", content)
+	}
+}
@@ -1,9 +1,12 @@
 package jsonTools
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"os"
+	"strings"
 
+	"github.com/ashahide/pubparse/internal/customErrors"
 	"github.com/xeipuuv/gojsonschema"
 )
 
@@ -15,36 +18,69 @@ import (
 //
 // Arguments:
 //   - path_to_json:   Path to the JSON file to be validated.
-//   - path_to_schema: Path to the JSON Schema file (.json) to validate against.
+//   - path_to_schema: Schema reference: a bundled schema name (PubmedSchema, PMCSchema)
+//     or a path to a schema file, optionally with a "#/..." fragment.
 //
 // Returns:
-//   - error: Any error encountered during validation (e.g., file access, schema parse).
-//   - If the schema is invalid or the JSON does not match, the error is logged and returned.
+//   - error: Any error encountered reading the file or compiling the schema, or a
+//     *customErrors.ValidationError naming the file and listing every violation
+//     if the JSON does not match.
 //
 // Behavior:
-//   - Uses the github.com/xeipuuv/gojsonschema package for validation.
-//   - Prints all validation errors if the JSON fails schema validation.
-//   - Calls log.Fatal for fatal load/parse errors (can be replaced with return for safer handling).
+//   - Reads the file and validates it with ValidateJsonBytesAgainstSchema.
 func ValidateJsonAgainstSchema(path_to_json string, path_to_schema string) error {
-	// Load the schema and document from file paths using the file:// URI scheme
-	schemaLoader := gojsonschema.NewReferenceLoader("file://" + path_to_schema)
-	documentLoader := gojsonschema.NewReferenceLoader("file://" + path_to_json)
+	document, err := os.ReadFile(path_to_json)
+	if err != nil {
+		return fmt.Errorf("failed to read %q: %w", path_to_json, err)
+	}
+
+	err = ValidateJsonBytesAgainstSchema(document, path_to_schema)
+	var invalid *customErrors.ValidationError
+	if errors.As(err, &invalid) {
+		invalid.File = path_to_json
+	}
+	return err
+}
 
-	// Perform schema validation
-	result, err := gojsonschema.Validate(schemaLoader, documentLoader)
+//
+// ------------------------ ValidateJsonBytesAgainstSchema ------------------------
+//
+
+// ValidateJsonBytesAgainstSchema is ValidateJsonAgainstSchema for an in-memory document,
+// used to validate output before it is written.
+//
+// Arguments:
+//   - document:       Marshalled JSON document.
+//   - path_to_schema: Schema reference, e.g. PubmedSchema + "#/definitions/PubmedArticle".
+//
+// Returns:
+//   - error: nil if the document is valid; the compile error; or a
+//     *customErrors.ValidationError (without File) holding a JSON pointer and
+//     description per violation.
+//
+// Behavior:
+//   - Each schema reference is compiled once per run (see compiledSchema).
+func ValidateJsonBytesAgainstSchema(document []byte, path_to_schema string) error {
+	schema, err := compiledSchema(path_to_schema)
 	if err != nil {
-		// Fatal error: likely unable to load or parse one of the files
-		log.Fatal("Validation error:", err)
+		return err
+	}
+
+	result, err := schema.Validate(gojsonschema.NewBytesLoader(document))
+	if err != nil {
+		return fmt.Errorf("failed to validate against %q: %w", path_to_schema, err)
 	}
 
-	// Output validation results
 	if !result.Valid() {
-		fmt.Println("JSON is NOT valid against the schema.")
+		invalid := &customErrors.ValidationError{Schema: path_to_schema}
 		for _, desc := range result.Errors() {
-			fmt.Println(" -", desc)
+			invalid.Violations = append(invalid.Violations, customErrors.Violation{
+				Pointer:     strings.TrimPrefix(desc.Context().String("/"), "(root)"),
+				Description: desc.Description(),
+			})
 		}
+		return invalid
 	}
 
-	// Return error (nil if valid, or validation failure)
-	return err
+	return nil
 }
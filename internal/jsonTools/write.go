@@ -1,15 +1,20 @@
 package jsonTools
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/ashahide/pubparse/internal/customErrors"
+	"github.com/ashahide/pubparse/internal/exportTools"
 	"github.com/ashahide/pubparse/internal/fileIO"
 	"github.com/ashahide/pubparse/internal/makeReports"
 	"github.com/ashahide/pubparse/internal/xmlTools"
@@ -19,37 +24,126 @@ import (
 // ------------------------ ConvertToJSON ------------------------
 //
 
+// EncodeOptions controls how documents are written as JSON.
+type EncodeOptions struct {
+	Projection *xmlTools.Projection // Fields to keep (--fields / --exclude); nil keeps all
+	LegacyKeys bool                 // Write the pre-2.0 Go field names instead of snake_case keys
+	OnInvalid  string               // What to do with documents failing validation; see InvalidPolicies
+	Validation *Validation          // Profile and --schema schemas; nil for the bundled ones only
+}
+
+// encodeOptions collects the JSON encoding options from the parsed arguments.
+// The validation schemas were checked by LoadValidation in ProcessAllFiles.
+func encodeOptions(args fileIO.Arguments) EncodeOptions {
+	validation, _ := LoadValidation(args.Profile, args.SchemaPath)
+	return EncodeOptions{Projection: args.Projection, LegacyKeys: args.LegacyKeys, OnInvalid: args.OnInvalid, Validation: validation}
+}
+
+/*
+encodeDocument marshals one output document.
+
+Returns:
+  - canonical: snake_case JSON stamped with xmlTools.SchemaVersion, used for schema validation.
+  - out: The bytes to write; canonical, or the legacy keys stamped with
+    xmlTools.LegacySchemaVersion when opts.LegacyKeys is set.
+*/
+func encodeDocument(v interface{}, opts EncodeOptions) (canonical, out []byte, err error) {
+	raw, err := json.Marshal(opts.Projection.Projected(v))
+	if err != nil {
+		return nil, nil, err
+	}
+	canonical = xmlTools.WithSchemaVersion(raw, xmlTools.SchemaVersion)
+	if !opts.LegacyKeys {
+		return canonical, canonical, nil
+	}
+
+	legacy, err := xmlTools.ToLegacyKeys(raw, v)
+	if err != nil {
+		return nil, nil, err
+	}
+	return canonical, xmlTools.WithSchemaVersion(legacy, xmlTools.LegacySchemaVersion), nil
+}
+
 /*
-ConvertToJSON serializes a normalized PubMed or PMC structure to JSON and validates it against a schema.
+ConvertToJSON serializes a normalized PubMed or PMC structure to JSON, validates it against a schema and writes it.
 
 Parameters:
   - result: The parsed and normalized data structure. Must be one of:
     *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet, or *xmlTools.PMCArticle.
   - fileName: Path to save the output JSON.
-  - schemaPath: Path to the JSON Schema file to validate against.
+  - schemaRef: Schema to validate against (PubmedSchema, PMCSchema, optionally with a
+    fragment); "" skips validation.
+  - opts: Projection and key naming.
 
 Behavior:
-  - Serializes the structure into compact JSON using encoding/json.
-  - Writes the output to the specified file.
-  - Validates the output file against the given schema using ValidateJsonAgainstSchema.
-
-Fatal:
-  - Terminates the program via log.Fatal if marshaling or file writing fails.
+  - Serializes the structure into compact JSON with snake_case keys and a leading
+    "schema_version", or legacy keys with opts.LegacyKeys.
+  - Validates the snake_case bytes in memory against the schema and the per-article
+    schemas of opts.Validation, so legacy output is checked against the same schemas.
+    Projected documents are not validated.
+  - Writes a valid document to fileName. An invalid one is handled by opts.OnInvalid:
+    not written ("fail"), written anyway ("warn") or written to QuarantineDir ("quarantine").
 
 Returns:
-  - An error from schema validation if validation fails; otherwise nil.
+  - A *customErrors.ValidationError for an invalid document, with File set to where
+    it was written (fileName if it was not written).
+  - Any marshaling or write error; otherwise nil.
 */
-func ConvertToJSON(result interface{}, fileName, schemaPath string) error {
-	jsonData, err := json.Marshal(result)
+func ConvertToJSON(result interface{}, fileName, schemaRef string, opts EncodeOptions) error {
+	doc, err := checkDocument(result, schemaRef, opts)
 	if err != nil {
-		log.Fatal("failed to marshal result to JSON:", err)
+		return err
 	}
+	return doc.write(fileName, opts.OnInvalid)
+}
+
+// checkedDocument is an encoded output document and the outcome of its validation.
+type checkedDocument struct {
+	out     []byte                        // The bytes to write
+	invalid *customErrors.ValidationError // Violations; nil for a valid or unvalidated document
+}
 
-	if err := os.WriteFile(fileName, jsonData, 0644); err != nil {
-		log.Fatal("failed to write JSON to file:", err)
+// checkDocument encodes and validates a document as ConvertToJSON does, without writing it.
+func checkDocument(result interface{}, schemaRef string, opts EncodeOptions) (checkedDocument, error) {
+	canonical, jsonData, err := encodeDocument(result, opts)
+	if err != nil {
+		return checkedDocument{}, fmt.Errorf("failed to marshal result to JSON: %w", err)
 	}
 
-	return ValidateJsonAgainstSchema(fileName, schemaPath)
+	doc := checkedDocument{out: jsonData}
+	if schemaRef != "" && opts.Projection == nil {
+		if err := validateDocument(result, canonical, schemaRef, opts.Validation); err != nil && !errors.As(err, &doc.invalid) {
+			return checkedDocument{}, err
+		}
+	}
+	return doc, nil
+}
+
+// write writes a checked document to fileName, or where onInvalid sends an invalid one.
+// It returns the document's *customErrors.ValidationError with File set to where it
+// was written (fileName if it was not written).
+func (d checkedDocument) write(fileName, onInvalid string) error {
+	target := fileName
+	if d.invalid != nil {
+		d.invalid.File = fileName
+		var err error
+		if target, err = invalidTarget(fileName, onInvalid); err != nil {
+			return err
+		}
+	}
+
+	if target != "" {
+		if err := os.WriteFile(target, d.out, 0644); err != nil {
+			return fmt.Errorf("failed to write JSON to file: %w", err)
+		}
+	}
+	if d.invalid != nil {
+		if target != "" {
+			d.invalid.File = target
+		}
+		return d.invalid
+	}
+	return nil
 }
 
 //
@@ -61,37 +155,128 @@ serializeAndValidate prepares and saves a parsed PubMed or PMC structure to JSON
 
 Parameters:
   - data: The parsed structure, such as *PubmedArticleSet, *PubmedBookArticleSet, or *PMCArticle.
+  - fin: The input file; its modification time is the date of BioC collections.
   - outputPath: The full path where the JSON should be written.
+  - args: Parsed arguments; args.Format selects "json" (one document per input),
+    "jsonl" (one article per line), "parquet" (one flattened row per article), a
+    citation format ("bibtex", "ris", "csl-json") or a full-text format ("text",
+    "markdown", rendered with args.DropReferences and args.StripCitations) or
+    "chunks" (section-aware JSON Lines chunks bounded by args.ChunkSize), a BioC
+    encoding ("bioc-xml", "bioc-json") or "es-bulk" (_bulk actions for args.IndexName).
 
 Behavior:
   - Normalizes the data depending on its type.
   - Selects the appropriate JSON schema.
-  - Calls ConvertToJSON to write and validate the file.
+  - Calls ConvertToJSON to write and validate the file, or ConvertToJSONL
+    to write and validate it line by line, or exportTools.WriteParquet / WriteCitations / WriteText / WriteChunks / WriteBioC / WriteBulk.
+  - Parquet, citation, full-text, chunk, BioC and bulk output is not schema-validated.
+  - With args.Projection, JSON and JSON Lines output holds only the selected fields
+    and is not schema-validated either.
 
 Returns:
-  - The path to the schema used.
+  - The schema reference used ("" for all formats other than json and jsonl).
   - An error if serialization or validation fails.
 */
-func serializeAndValidate(data interface{}, outputPath string) (string, error) {
+func serializeAndValidate(data interface{}, fin, outputPath string, args fileIO.Arguments) (string, error) {
+	format := args.Format
+	if format == "parquet" {
+		normalize(data)
+		return "", exportTools.WriteParquet(data, outputPath)
+	}
+
+	if exportTools.IsCitationFormat(format) {
+		normalize(data)
+		return "", exportTools.WriteCitations(data, outputPath, format)
+	}
+
+	if exportTools.IsTextFormat(format) {
+		normalize(data)
+		return "", exportTools.WriteText(data, outputPath, exportTools.TextOptions{
+			Markdown:       format == "markdown",
+			DropReferences: args.DropReferences,
+			StripCitations: args.StripCitations,
+		})
+	}
+
+	if exportTools.IsBioCFormat(format) {
+		normalize(data)
+		info, err := os.Stat(fin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the modification time of %q: %w", fin, err)
+		}
+		return "", exportTools.WriteBioC(data, outputPath, format, info.ModTime())
+	}
+
+	if format == "es-bulk" {
+		normalize(data)
+		return "", exportTools.WriteBulk(data, outputPath, args.IndexName)
+	}
+
+	if format == "chunks" {
+		normalize(data)
+		return "", exportTools.WriteChunks(data, outputPath, exportTools.ChunkOptions{
+			Size: args.ChunkSize,
+			Unit: args.ChunkUnit,
+		})
+	}
+
+	if format == "jsonl" {
+		docs, err := splitArticles(data)
+		if err != nil {
+			return "", err
+		}
+		schema := ""
+		if len(docs) > 0 && args.Projection == nil {
+			schema = docs[0].Schema
+		}
+		return schema, ConvertToJSONL(docs, outputPath, encodeOptions(args))
+	}
+
+	var schema string
 	switch v := data.(type) {
 	case *xmlTools.PubmedArticleSet:
 		xmlTools.NormalizePubmedArticleSet(v)
-		schema := filepath.Join("internal", "jsonTools", "pubmed_json_schema.json")
-		return schema, ConvertToJSON(v, outputPath, schema)
+		schema = PubmedSchema
 
 	case *xmlTools.PubmedBookArticleSet:
 		xmlTools.NormalizePubmedArticleSet(v)
-		schema := filepath.Join("internal", "jsonTools", "pubmed_json_schema.json")
-		return schema, ConvertToJSON(v, outputPath, schema)
+		schema = PubmedSchema
 
 	case *xmlTools.PMCArticle:
 		xmlTools.NormalizePMCArticle(v)
-		schema := filepath.Join("internal", "jsonTools", "pmc_json_schema.json")
-		return schema, ConvertToJSON(v, outputPath, schema)
+		schema = PMCSchema
 
 	default:
 		return "", fmt.Errorf("unsupported data type for serialization")
 	}
+
+	// Projected output no longer has the fields the schemas require
+	if args.Projection != nil {
+		schema = ""
+	}
+	return schema, ConvertToJSON(data, outputPath, schema, encodeOptions(args))
+}
+
+// decodeOptions selects how inputs are decoded: formats that render prose fold
+// inline markup into the text (see exportTools.TextDecodeOptions), the others
+// decode the structs unchanged.
+func decodeOptions(args fileIO.Arguments) xmlTools.DecodeOptions {
+	if exportTools.FoldsInline(args.Format) {
+		return exportTools.TextDecodeOptions(args.Lenient, args.StripCitations && exportTools.IsTextFormat(args.Format))
+	}
+	return xmlTools.DecodeOptions{Lenient: args.Lenient}
+}
+
+// normalize applies the type-specific normalization to a parsed structure.
+func normalize(data interface{}) {
+	switch v := data.(type) {
+	case *xmlTools.PubmedArticleSet:
+		xmlTools.NormalizePubmedArticleSet(v)
+	case *xmlTools.PubmedBookArticleSet:
+		xmlTools.NormalizePubmedArticleSet(v)
+	case *xmlTools.PMCArticle:
+		xmlTools.NormalizePMCArticle(v)
+	}
 }
 
 //
@@ -103,18 +288,25 @@ processFile executes the full processing pipeline for a single input file:
 parse XML, normalize, convert to JSON, validate, and log result.
 
 Parameters:
+  - ctx: Cancelled on SIGINT/SIGTERM; checked before the output is created and again
+    once the XML is parsed, after which the file is always finished.
   - i: Index of the file in the file list.
-  - args: The input/output file path configuration.
+  - args: The input/output file path configuration and decoding options.
   - mode: "pubmed" or "pmc", used to guide XML parsing.
   - report: Open report file handle for logging.
   - mu: Mutex to ensure thread-safe access to the report file.
   - start: Start time of the entire processing batch (for progress).
   - doneCounter: Atomic counter tracking how many files have been processed.
+  - sink: Shared writer for aggregate formats (tsv, sqlite); nil for per-input formats.
+  - claims: The split output files written so far by the run; nil without --split.
 
 Returns:
+  - Whether the output was kept with schema violations (--on-invalid warn).
+  - ctx.Err() if the file was abandoned; the caller removes its output.
   - An error if any stage in processing fails; otherwise nil.
 */
 func processFile(
+	ctx context.Context,
 	i int,
 	args fileIO.Arguments,
 	mode string,
@@ -122,36 +314,152 @@ func processFile(
 	mu *sync.Mutex,
 	start time.Time,
 	doneCounter *int32,
-) error {
+	sink exportTools.ArticleSink,
+	claims *splitClaims,
+) (bool, error) {
 	fin := args.InputPath.Files[i]
 	fout := args.OutputPath.Files[i]
 
-	// Ensure output file is created before writing
-	if err := fileIO.MakeFile(fout); err != nil {
-		return fmt.Errorf("failed to create output file %q: %w", fout, err)
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	// Ensure output file is created before writing (split mode names files after parsing,
+	// aggregate formats append to shared files)
+	if !args.Split && sink == nil {
+		if err := fileIO.MakeFile(fout); err != nil {
+			return false, fmt.Errorf("failed to create output file %q: %w", fout, err)
+		}
 	}
 
 	// Parse XML file into appropriate structure
-	data, err := xmlTools.ParsePubmedXML(fin)
+	data, info, err := xmlTools.ParsePubmedXMLWithOptions(fin, decodeOptions(args))
+	if err != nil {
+		return false, &customErrors.ParseError{File: fin, Err: err}
+	}
+
+	// Abandon the file if the run was interrupted while parsing; nothing is written yet
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	// Check the semantic rules on the complete record, before any field is dropped
+	disabled, err := CheckRuleNames(args.DisabledRules)
 	if err != nil {
-		return fmt.Errorf("failed to parse XML %q: %w", fin, err)
+		return false, err
 	}
+	ruleFindings := CheckRules(data, disabled)
 
-	// Convert and validate
-	if _, convErr := serializeAndValidate(data, fout); convErr != nil {
-		return fmt.Errorf("failed to convert to JSON for %q: %w", fout, convErr)
+	// Drop the fields not selected by --fields / --exclude
+	args.Projection.Apply(data)
+
+	// Aggregate formats: hand this input's articles to the shared writer
+	if sink != nil {
+		normalize(data)
+		if err := sink.WriteArticles(data); err != nil {
+			return false, fmt.Errorf("failed to export %q: %w", fin, err)
+		}
+		fout = args.OutputPath.Path
+		if args.OutputFile != "" {
+			fout = args.OutputFile
+		}
 	}
 
-	// Write mapping to report
+	// Convert and validate: one file per article in split mode, otherwise one per input.
+	// Schema violations go to the report; --on-invalid decides whether they fail the input.
+	var split bool
+	var splits []SplitOutput
+	var collisions []SplitCollision
+	var invalid *customErrors.ValidationError
+	switch data.(type) {
+	case *xmlTools.PubmedArticleSet, *xmlTools.PubmedBookArticleSet:
+		if split = args.Split; split {
+			splits, collisions, err = writeSplitArticles(data, fin, i, filepath.Dir(fout), args.SplitVersions, encodeOptions(args), claims)
+			if err != nil {
+				if errors.As(err, &invalid) {
+					reportInvalid(report, mu, fin, args.OnInvalid, invalid)
+				}
+				return false, fmt.Errorf("failed to split %q: %w", fin, err)
+			}
+		}
+	}
+	if !split && sink == nil {
+		if _, convErr := serializeAndValidate(data, fin, fout, args); convErr != nil {
+			if !errors.As(convErr, &invalid) || !keepsInvalid(args.OnInvalid) {
+				// Leave no empty or partial file behind for a rejected input
+				os.Remove(fout)
+				if invalid != nil {
+					reportInvalid(report, mu, fin, args.OnInvalid, invalid)
+				}
+				return false, fmt.Errorf("failed to convert to JSON for %q: %w", fout, convErr)
+			}
+			if invalid.File != fout {
+				os.Remove(fout)
+				fout = invalid.File
+			}
+		}
+	}
+
+	// Write mapping, validation findings, source format, decoding fixups and skipped records to report
 	if report != nil {
-		if err := makeReports.WriteToReport(report, mu, fin, fout); err != nil {
-			return fmt.Errorf("failed to write to report: %w", err)
+		if !split {
+			if err := makeReports.WriteToReport(report, mu, fin, fout); err != nil {
+				return false, fmt.Errorf("failed to write to report: %w", err)
+			}
+		}
+		for _, article := range splits {
+			if err := makeReports.WriteSplitToReport(report, mu, article.PMID, fin, article.Path); err != nil {
+				return false, fmt.Errorf("failed to write to report: %w", err)
+			}
+			if article.Invalid != nil {
+				if err := makeReports.WriteValidationToReport(report, mu, fin, args.OnInvalid, article.Invalid); err != nil {
+					return false, fmt.Errorf("failed to write to report: %w", err)
+				}
+			}
+		}
+		for _, collision := range collisions {
+			if err := makeReports.WriteSplitCollisionToReport(report, mu, collision.PMID, collision.Path, collision.Kept, collision.Dropped); err != nil {
+				return false, fmt.Errorf("failed to write to report: %w", err)
+			}
+		}
+		if invalid != nil {
+			if err := makeReports.WriteValidationToReport(report, mu, fin, args.OnInvalid, invalid); err != nil {
+				return false, fmt.Errorf("failed to write to report: %w", err)
+			}
+		}
+		var findings []string
+		for _, finding := range documentFindings(ruleFindings, data, args.LegacyKeys, splits) {
+			findings = append(findings, finding.String())
+		}
+		if err := makeReports.WriteFindingsToReport(report, mu, fin, findings); err != nil {
+			return false, fmt.Errorf("failed to write to report: %w", err)
+		}
+		if err := makeReports.WriteSourceFormatToReport(report, mu, fin, info.Source.String(), info.Warnings); err != nil {
+			return false, fmt.Errorf("failed to write to report: %w", err)
+		}
+		if err := makeReports.WriteFixupsToReport(report, mu, fin, info.Fixups); err != nil {
+			return false, fmt.Errorf("failed to write to report: %w", err)
+		}
+		var skipped []string
+		for _, record := range info.Skipped {
+			skipped = append(skipped, record.String())
+		}
+		if err := makeReports.WriteSkippedToReport(report, mu, fin, skipped); err != nil {
+			return false, fmt.Errorf("failed to write to report: %w", err)
 		}
 	}
 
 	// Increment progress
 	atomic.AddInt32(doneCounter, 1)
-	return nil
+	return invalid != nil, nil
+}
+
+// reportInvalid records the violations of a rejected input before its error is
+// returned; a failure to write them is secondary to that error and ignored.
+func reportInvalid(report *os.File, mu *sync.Mutex, fin, policy string, invalid *customErrors.ValidationError) {
+	if report != nil {
+		makeReports.WriteValidationToReport(report, mu, fin, policy, invalid)
+	}
 }
 
 //
@@ -162,6 +470,7 @@ func processFile(
 ProcessAllFiles runs the main pipeline on all input files with parallel workers.
 
 Parameters:
+  - ctx: Cancelled on SIGINT/SIGTERM to stop the run (see Behavior).
   - args: Holds the input/output file lists and paths.
   - mode: Either "pubmed" or "pmc", used to guide XML parsing logic.
   - report: Open file handle to write report log entries.
@@ -172,13 +481,70 @@ Behavior:
   - Uses a semaphore (channel) to enforce the worker limit.
   - Each file is processed in its own goroutine, with error handling and safe report logging.
   - All workers wait using a sync.WaitGroup.
+  - With args.ContinueOnError, records a status line per input in the report (see
+    FailureStatus) and lists the failed inputs in FailureManifest.
+  - Once ctx is cancelled, no new file is started and files still parsing are abandoned;
+    files already being written are finished. The empty outputs pre-created for the
+    unprocessed inputs are removed, the inputs are listed in PendingManifest (which -i
+    accepts) and a summary is written to the report. A run that is not interrupted
+    removes a PendingManifest left by an earlier one.
+  - With args.Split, resolves articles naming the same file the same way whatever the
+    order the workers finish in (see writeSplitArticles).
+  - For formats with one output per input, records each written output in the
+    ContentManifest; with args.Incremental, inputs whose outputs are current (see
+    Manifest.Current) are reported as unchanged instead of being processed.
 
 Returns:
   - The first encountered error during processing, or nil if all files succeed.
+  - With args.ContinueOnError, a *customErrors.PartialFailureError instead when some inputs failed.
+  - A *customErrors.InterruptedError instead when ctx was cancelled before every input was processed.
 */
-func ProcessAllFiles(args fileIO.Arguments, mode string, report *os.File, workers int) error {
+func ProcessAllFiles(ctx context.Context, args fileIO.Arguments, mode string, report *os.File, workers int) error {
 	startTime := time.Now()
 
+	// Compile the profile and --schema schemas once, before any worker starts
+	if _, err := LoadValidation(args.Profile, args.SchemaPath); err != nil {
+		return err
+	}
+
+	// Aggregate formats share one writer across all workers
+	var sink exportTools.ArticleSink
+	switch args.Format {
+	case "tsv":
+		tables, err := exportTools.NewTableWriter(args.OutputPath.Path)
+		if err != nil {
+			return err
+		}
+		sink = tables
+	case "sqlite":
+		db, err := exportTools.NewSQLiteWriter(args.OutputFile)
+		if err != nil {
+			return err
+		}
+		sink = db
+	case "es-bulk":
+		// One mapping for the index, next to the per-input bulk files
+		mapping := filepath.Join(args.OutputPath.Path, args.IndexName+".mapping.json")
+		if err := exportTools.WriteSearchMapping(mapping); err != nil {
+			return err
+		}
+	}
+
+	// Split outputs are shared by every input naming the same PMID
+	var claims *splitClaims
+	if args.Split {
+		claims = newSplitClaims()
+	}
+
+	// Outputs written from the same content and settings can be kept
+	var manifest *Manifest
+	if perInputOutputs(args) {
+		var err error
+		if manifest, err = LoadManifest(args.OutputPath.Path, outputSettings(mode, args)); err != nil && args.Incremental {
+			return err
+		}
+	}
+
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var doneCount int32
@@ -189,18 +555,79 @@ func ProcessAllFiles(args fileIO.Arguments, mode string, report *os.File, worker
 
 	sema := make(chan struct{}, workers)                   // concurrency limiter
 	errChan := make(chan error, len(args.InputPath.Files)) // error collection channel
+	failed := make([]error, len(args.InputPath.Files))     // per-input errors for --continue-on-error
+	pending := make([]bool, len(args.InputPath.Files))     // inputs not processed when ctx was cancelled
 
-	// Launch worker goroutines
+	// Launch worker goroutines until all are started or the run is interrupted
 	for i := range args.InputPath.Files {
+		if ctx.Err() == nil {
+			select {
+			case sema <- struct{}{}: // acquire slot
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			for j := i; j < len(pending); j++ {
+				pending[j] = true
+			}
+			break
+		}
 		wg.Add(1)
-		sema <- struct{}{} // acquire slot
 
 		go func(i int) {
 			defer wg.Done()
 			defer func() { <-sema }() // release slot
 
-			if err := processFile(i, args, mode, report, &mu, startTime, &doneCount); err != nil {
+			// Describe the input before it is read, so a change during the run is seen next time
+			var entry ManifestEntry
+			var inspectErr error
+			if manifest != nil {
+				entry, inspectErr = manifest.Inspect(args.InputPath.Files[i], args.OutputPath.Files[i])
+				if inspectErr == nil && args.Incremental && manifest.Current(&entry) {
+					manifest.Record(entry)
+					atomic.AddInt32(&doneCount, 1)
+					if report != nil {
+						// The report lines are secondary to the kept output; write failures are ignored
+						makeReports.WriteUnchangedToReport(report, &mu, entry.Input, entry.Output)
+						if args.ContinueOnError {
+							makeReports.WriteStatusToReport(report, &mu, entry.Input, StatusUnchanged, "")
+						}
+					}
+					return
+				}
+			}
+
+			invalid, err := processFile(ctx, i, args, mode, report, &mu, startTime, &doneCount, sink, claims)
+			if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+				// Abandoned: the pre-created output is removed with the other pending ones
+				pending[i] = true
+				return
+			}
+			if err != nil {
+				// Leave no empty pre-created or partial output behind for a failed input;
+				// shared aggregate files and split outputs are not this input's to remove
+				if perInputOutputs(args) {
+					os.Remove(args.OutputPath.Files[i])
+				}
 				errChan <- err
+				failed[i] = err
+			} else if manifest != nil && inspectErr == nil && !invalid {
+				// An output kept with violations is converted again, so they are reported again
+				manifest.Record(entry)
+			}
+			if !args.ContinueOnError {
+				return
+			}
+			if err != nil {
+				atomic.AddInt32(&doneCount, 1) // A failed input still counts towards progress
+			}
+			if report != nil {
+				status, message := StatusOK, ""
+				if err != nil {
+					status, message = FailureStatus(err), err.Error()
+				}
+				// The status is secondary to the input's own outcome; a write failure is ignored
+				makeReports.WriteStatusToReport(report, &mu, args.InputPath.Files[i], status, message)
 			}
 		}(i)
 	}
@@ -210,6 +637,29 @@ func ProcessAllFiles(args fileIO.Arguments, mode string, report *os.File, worker
 	close(errChan)
 	close(stopCh)
 
+	if sink != nil {
+		if err := sink.Close(); err != nil {
+			return fmt.Errorf("failed to finish %s output: %w", args.Format, err)
+		}
+	}
+
+	if manifest != nil {
+		if err := manifest.Write(args.InputPath.Files); err != nil {
+			return err
+		}
+	}
+
+	if slices.Contains(pending, true) {
+		return writeInterruption(args, report, &mu, failed, pending)
+	}
+	if err := removeStaleManifest(filepath.Join(args.OutputPath.Path, PendingManifest)); err != nil {
+		return err
+	}
+
+	if args.ContinueOnError {
+		return writeFailureManifest(args, failed)
+	}
+
 	// Return first encountered error, if any
 	for err := range errChan {
 		if err != nil {
@@ -219,3 +669,151 @@ func ProcessAllFiles(args fileIO.Arguments, mode string, report *os.File, worker
 
 	return nil
 }
+
+// perInputOutputs reports whether args.OutputPath.Files are outputs of their own
+// input, created or truncated by this run (VerifyWriteAccess, MakeFile). They are
+// not with --split, where files are named after the PMIDs, nor for the aggregate
+// formats, whose shared files hold every input's rows.
+func perInputOutputs(args fileIO.Arguments) bool {
+	return !args.Split && !fileIO.AggregateFormat(args.Format)
+}
+
+//
+// ------------------------ Failure manifest ------------------------
+//
+
+// Per-input statuses recorded in the report with --continue-on-error.
+const (
+	StatusOK              = "ok"
+	StatusUnchanged       = "unchanged"        // Skipped by --incremental; the output is current
+	StatusParseError      = "parse_error"      // The XML could not be decoded
+	StatusValidationError = "validation_error" // The output failed its schema under --on-invalid fail
+	StatusIOError         = "io_error"         // Reading, converting or writing failed otherwise
+)
+
+// FailureManifest is the file listing the failed inputs of a --continue-on-error run.
+const FailureManifest = "failures.txt"
+
+// FailureStatus classifies the error returned for one input.
+func FailureStatus(err error) string {
+	var parseErr *customErrors.ParseError
+	var invalid *customErrors.ValidationError
+	switch {
+	case errors.As(err, &parseErr):
+		return StatusParseError
+	case errors.As(err, &invalid):
+		return StatusValidationError
+	default:
+		return StatusIOError
+	}
+}
+
+/*
+writeFailureManifest writes the failed inputs of a --continue-on-error run to
+FailureManifest in the output directory.
+
+Parameters:
+  - args: The run's arguments; the manifest goes to args.OutputPath.Path.
+  - failed: The error per input (args.InputPath.Files order); nil for inputs that succeeded.
+
+Behavior:
+  - Lists one absolute input path per line, so the file can be passed back with -i.
+  - Removes a manifest left by an earlier run when every input succeeded.
+
+Returns:
+  - A *customErrors.PartialFailureError if any input failed; an error if the
+    manifest cannot be written; otherwise nil.
+*/
+func writeFailureManifest(args fileIO.Arguments, failed []error) error {
+	manifest := filepath.Join(args.OutputPath.Path, FailureManifest)
+
+	var list strings.Builder
+	count := 0
+	for i, err := range failed {
+		if err != nil {
+			list.WriteString(args.InputPath.Files[i] + "\n")
+			count++
+		}
+	}
+
+	if count == 0 {
+		return removeStaleManifest(manifest)
+	}
+	if err := os.WriteFile(manifest, []byte(list.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", manifest, err)
+	}
+	return &customErrors.PartialFailureError{Failed: count, Total: len(failed), Manifest: manifest}
+}
+
+// removeStaleManifest removes a manifest left by an earlier run, if any.
+func removeStaleManifest(manifest string) error {
+	if err := os.Remove(manifest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale %s: %w", manifest, err)
+	}
+	return nil
+}
+
+//
+// ------------------------ Pending manifest ------------------------
+//
+
+// PendingManifest is the file listing the inputs an interrupted run did not process.
+const PendingManifest = "pending.txt"
+
+/*
+writeInterruption finishes a run stopped by SIGINT or SIGTERM.
+
+Parameters:
+  - args: The run's arguments; the manifests go to args.OutputPath.Path.
+  - report: Open report file handle; nil to skip the summary.
+  - mu: Mutex guarding the report file.
+  - failed: The error per input; nil for inputs that succeeded or are pending.
+  - pending: Whether each input was left unprocessed.
+
+Behavior:
+  - Removes the outputs of the pending inputs that are still empty, i.e. those
+    pre-created by VerifyWriteAccess or MakeFile; nothing else is deleted.
+  - Lists the pending inputs in PendingManifest, one absolute path per line, and
+    with args.ContinueOnError the failed ones in FailureManifest as usual.
+  - Writes the completed, failed and pending counts to the report.
+
+Returns:
+  - A *customErrors.InterruptedError, or an error if a manifest cannot be written.
+*/
+func writeInterruption(args fileIO.Arguments, report *os.File, mu *sync.Mutex, failed []error, pending []bool) error {
+	manifest := filepath.Join(args.OutputPath.Path, PendingManifest)
+	interrupted := &customErrors.InterruptedError{Manifest: manifest}
+
+	var list strings.Builder
+	for i, isPending := range pending {
+		switch {
+		case isPending:
+			list.WriteString(args.InputPath.Files[i] + "\n")
+			interrupted.Pending++
+			if perInputOutputs(args) && i < len(args.OutputPath.Files) {
+				if info, err := os.Stat(args.OutputPath.Files[i]); err == nil && info.Mode().IsRegular() && info.Size() == 0 {
+					os.Remove(args.OutputPath.Files[i])
+				}
+			}
+		case failed[i] != nil:
+			interrupted.Failed++
+		default:
+			interrupted.Completed++
+		}
+	}
+
+	if err := os.WriteFile(manifest, []byte(list.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", manifest, err)
+	}
+	if args.ContinueOnError {
+		var partial *customErrors.PartialFailureError
+		if err := writeFailureManifest(args, failed); err != nil && !errors.As(err, &partial) {
+			return err
+		}
+	}
+	if report != nil {
+		// The summary is secondary to the interruption itself; a write failure is ignored
+		makeReports.WriteInterruptedToReport(report, mu, interrupted.Completed, interrupted.Failed, interrupted.Pending, manifest)
+	}
+	return interrupted
+}
@@ -3,7 +3,11 @@ package makeReports
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/ashahide/pubparse/internal/customErrors"
 )
 
 //
@@ -40,3 +44,347 @@ func WriteToReport(report *os.File, mu *sync.Mutex, fin, fout string) error {
 	// Flush to disk to ensure durability
 	return report.Sync()
 }
+
+//
+// ------------------------ WriteSplitToReport ------------------------
+//
+
+/*
+WriteSplitToReport records that one article (by PMID) of a multi-article input
+was written to its own output file.
+
+Parameters:
+  - report: An open *os.File for writing report entries.
+  - mu: Pointer to a sync.Mutex used to guard concurrent access to the file.
+  - pmid: PMID of the article.
+  - fin: Path to the input XML file the article came from.
+  - fout: Path to the per-article JSON file.
+
+Returns:
+  - An error if writing or syncing the report file fails; otherwise nil.
+*/
+func WriteSplitToReport(report *os.File, mu *sync.Mutex, pmid, fin, fout string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, err := report.WriteString(fmt.Sprintf(">>> PMID: %s\t Input file: %s\t Output file: %s\n", pmid, fin, fout)); err != nil {
+		return err
+	}
+	return report.Sync()
+}
+
+//
+// ------------------------ WriteSplitCollisionToReport ------------------------
+//
+
+/*
+WriteSplitCollisionToReport records two articles that named the same split output file.
+
+Parameters:
+  - report: An open *os.File for writing report entries.
+  - mu: Pointer to a sync.Mutex used to guard concurrent access to the file.
+  - pmid: PMID of the articles.
+  - fout: Path to the per-article JSON file.
+  - kept: The article whose document the file holds.
+  - dropped: The article that was not written or was overwritten.
+
+Returns:
+  - An error if writing or syncing the report file fails; otherwise nil.
+*/
+func WriteSplitCollisionToReport(report *os.File, mu *sync.Mutex, pmid, fout, kept, dropped string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, err := report.WriteString(fmt.Sprintf(">>> Duplicate PMID: %s\t Output file: %s\t Kept: %s\t Dropped: %s\n", pmid, fout, kept, dropped)); err != nil {
+		return err
+	}
+	return report.Sync()
+}
+
+//
+// ------------------------ WriteFixupsToReport ------------------------
+//
+
+/*
+WriteFixupsToReport records the decoding fixups applied to an input file
+(legacy charset conversion, DTD entity substitution, non-strict recovery).
+
+Parameters:
+  - report: An open *os.File for writing report entries.
+  - mu: Pointer to a sync.Mutex used to guard concurrent access to the file.
+  - fin: Path to the input XML file.
+  - fixups: Fixups reported by the XML parser, e.g. "charset:iso-8859-1".
+
+Behavior:
+  - Writes nothing when no fixups were needed.
+  - Otherwise appends one line listing all fixups, comma-separated.
+
+Returns:
+  - An error if writing or syncing the report file fails; otherwise nil.
+*/
+func WriteFixupsToReport(report *os.File, mu *sync.Mutex, fin string, fixups []string) error {
+	if len(fixups) == 0 {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, err := report.WriteString(fmt.Sprintf(">>> Fixups: %s\t %s\n", fin, strings.Join(fixups, ", "))); err != nil {
+		return err
+	}
+	return report.Sync()
+}
+
+//
+// ------------------------ WriteSourceFormatToReport ------------------------
+//
+
+/*
+WriteSourceFormatToReport records the DTD/JATS version an input file declared,
+followed by one line per version warning.
+
+Parameters:
+  - report: An open *os.File for writing report entries.
+  - mu: Pointer to a sync.Mutex used to guard concurrent access to the file.
+  - fin: Path to the input XML file.
+  - format: Summary of the detected format, e.g. "pubmed 2025" or "jats 1.3".
+  - warnings: Version warnings reported by the XML parser.
+
+Returns:
+  - An error if writing or syncing the report file fails; otherwise nil.
+*/
+func WriteSourceFormatToReport(report *os.File, mu *sync.Mutex, fin, format string, warnings []string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, err := report.WriteString(fmt.Sprintf(">>> Source format: %s\t %s\n", fin, format)); err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		if _, err := report.WriteString(fmt.Sprintf(">>> Warning: %s\t %s\n", fin, w)); err != nil {
+			return err
+		}
+	}
+	return report.Sync()
+}
+
+//
+// ------------------------ WriteValidationToReport ------------------------
+//
+
+/*
+WriteValidationToReport records a document that failed schema validation,
+followed by one line per violation.
+
+Parameters:
+  - report: An open *os.File for writing report entries.
+  - mu: Pointer to a sync.Mutex used to guard concurrent access to the file.
+  - fin: Path to the input XML file.
+  - policy: The --on-invalid policy applied: "fail", "warn" or "quarantine".
+  - invalid: The validation error, naming the output file and its violations.
+
+Returns:
+  - An error if writing or syncing the report file fails; otherwise nil.
+*/
+func WriteValidationToReport(report *os.File, mu *sync.Mutex, fin, policy string, invalid *customErrors.ValidationError) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, err := report.WriteString(fmt.Sprintf(">>> Invalid: %s\t %s\t %s against %s\n", fin, invalid.File, policy, invalid.Schema)); err != nil {
+		return err
+	}
+	for _, v := range invalid.Violations {
+		if _, err := report.WriteString(fmt.Sprintf(">>> Violation: %s\t %s\n", fin, v)); err != nil {
+			return err
+		}
+	}
+	return report.Sync()
+}
+
+//
+// ------------------------ WriteFindingsToReport ------------------------
+//
+
+/*
+WriteFindingsToReport records the semantic rule findings of an input file,
+one line per finding.
+
+Parameters:
+  - report: An open *os.File for writing report entries.
+  - mu: Pointer to a sync.Mutex used to guard concurrent access to the file.
+  - fin: Path to the input XML file.
+  - findings: Formatted findings, e.g. "warning doi-format /pubmed_articles/0/...: ...".
+
+Behavior:
+  - Writes nothing when every rule passed.
+
+Returns:
+  - An error if writing or syncing the report file fails; otherwise nil.
+*/
+func WriteFindingsToReport(report *os.File, mu *sync.Mutex, fin string, findings []string) error {
+	if len(findings) == 0 {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, f := range findings {
+		if _, err := report.WriteString(fmt.Sprintf(">>> Finding: %s\t %s\n", fin, f)); err != nil {
+			return err
+		}
+	}
+	return report.Sync()
+}
+
+//
+// ------------------------ WriteStatusToReport ------------------------
+//
+
+/*
+WriteStatusToReport records the outcome of one input file in a --continue-on-error run.
+
+Parameters:
+  - report: An open *os.File for writing report entries.
+  - mu: Pointer to a sync.Mutex used to guard concurrent access to the file.
+  - fin: Path to the input XML file.
+  - status: "ok", "parse_error", "validation_error" or "io_error".
+  - message: The error message; "" for inputs that succeeded.
+
+Returns:
+  - An error if writing or syncing the report file fails; otherwise nil.
+*/
+func WriteStatusToReport(report *os.File, mu *sync.Mutex, fin, status, message string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	line := fmt.Sprintf(">>> Status: %s\t %s", fin, status)
+	if message != "" {
+		// Keep the entry on one line
+		line += "\t " + strings.Join(strings.Fields(message), " ")
+	}
+	if _, err := report.WriteString(line + "\n"); err != nil {
+		return err
+	}
+	return report.Sync()
+}
+
+//
+// ------------------------ WriteSkippedToReport ------------------------
+//
+
+/*
+WriteSkippedToReport records the records of a multi-article input that could not be
+decoded and were left out of its output, one line per record.
+
+Parameters:
+  - report: An open *os.File for writing report entries.
+  - mu: Pointer to a sync.Mutex used to guard concurrent access to the file.
+  - fin: Path to the input XML file.
+  - skipped: Formatted records, e.g. "offset 1234 PMID 5678: XML syntax error ...".
+
+Behavior:
+  - Writes nothing when every record was decoded.
+
+Returns:
+  - An error if writing or syncing the report file fails; otherwise nil.
+*/
+func WriteSkippedToReport(report *os.File, mu *sync.Mutex, fin string, skipped []string) error {
+	if len(skipped) == 0 {
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, s := range skipped {
+		if _, err := report.WriteString(fmt.Sprintf(">>> Skipped record: %s\t %s\n", fin, s)); err != nil {
+			return err
+		}
+	}
+	return report.Sync()
+}
+
+//
+// ------------------------ WriteInterruptedToReport ------------------------
+//
+
+/*
+WriteInterruptedToReport records the summary of a run stopped by SIGINT or SIGTERM.
+
+Parameters:
+  - report: An open *os.File for writing report entries.
+  - mu: Pointer to a sync.Mutex used to guard concurrent access to the file.
+  - completed, failed, pending: Inputs written, failed and not processed.
+  - manifest: The file listing the pending inputs.
+
+Returns:
+  - An error if writing or syncing the report file fails; otherwise nil.
+*/
+func WriteInterruptedToReport(report *os.File, mu *sync.Mutex, completed, failed, pending int, manifest string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	summary := fmt.Sprintf(
+		">>> Interrupted: %s\n>>> Completed Inputs: %d\n>>> Failed Inputs: %d\n>>> Pending Inputs: %d\t %s\n",
+		time.Now().Format("2006-01-02 15:04:05"), completed, failed, pending, manifest,
+	)
+	if _, err := report.WriteString(summary); err != nil {
+		return err
+	}
+	return report.Sync()
+}
+
+//
+// ------------------------ WriteUnchangedToReport ------------------------
+//
+
+/*
+WriteUnchangedToReport records an input skipped by an incremental run because its
+output is current.
+
+Parameters:
+  - report: An open *os.File for writing report entries.
+  - mu: Pointer to a sync.Mutex used to guard concurrent access to the file.
+  - fin: Path to the input XML file.
+  - fout: Path to its existing output file.
+
+Returns:
+  - An error if writing or syncing the report file fails; otherwise nil.
+*/
+func WriteUnchangedToReport(report *os.File, mu *sync.Mutex, fin, fout string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, err := report.WriteString(fmt.Sprintf(">>> Unchanged: %s\t Output file: %s\n", fin, fout)); err != nil {
+		return err
+	}
+	return report.Sync()
+}
+
+//
+// ------------------------ WriteNotArticlesToReport ------------------------
+//
+
+/*
+WriteNotArticlesToReport records a JSON input skipped by `pubparse cite` because it
+is not pubparse article output, e.g. a CSL-JSON file or an es-bulk mapping.
+
+Parameters:
+  - report: An open *os.File for writing report entries.
+  - mu: Pointer to a sync.Mutex used to guard concurrent access to the file.
+  - fin: Path to the skipped JSON file.
+
+Returns:
+  - An error if writing or syncing the report file fails; otherwise nil.
+*/
+func WriteNotArticlesToReport(report *os.File, mu *sync.Mutex, fin string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, err := report.WriteString(fmt.Sprintf(">>> Not pubparse output: %s\n", fin)); err != nil {
+		return err
+	}
+	return report.Sync()
+}
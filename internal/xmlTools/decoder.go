@@ -0,0 +1,296 @@
+package xmlTools
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DecodeOptions controls how input XML is decoded.
+type DecodeOptions struct {
+	// Lenient retries documents that fail strict decoding with a non-strict
+	// decoder (unknown entities kept verbatim, unclosed HTML-style tags auto-closed).
+	Lenient bool
+
+	// Tokens, if set, filters the token stream before it is decoded into the
+	// structs; full-text export uses it to fold inline markup into the text.
+	Tokens func(xml.TokenReader) xml.TokenReader
+}
+
+// ParseInfo describes how an input file was decoded.
+type ParseInfo struct {
+	// Fixups lists the recovery steps needed to read the file, e.g.
+	// "charset:iso-8859-1", "entity:nbsp" or "non-strict". Empty for clean UTF-8 input.
+	Fixups []string
+
+	// Source is the DTD the file declared; Warnings flags versions the structs
+	// were not designed for (see DetectSourceFormat).
+	Source   SourceFormat
+	Warnings []string
+
+	// Skipped lists the records of a multi-article file that could not be decoded
+	// and were left out, while the other records were kept.
+	Skipped []SkippedRecord
+}
+
+// ------------------------ Entities ------------------------
+
+// Entities maps named character entities to their replacement text.
+//
+// PMC and older PubMed files are produced against DTDs that declare the ISO 8879
+// entity sets (via JATS/NLM), so references such as &nbsp;, &ndash; or &alpha;
+// appear without a declaration the standard decoder can see. The map starts from
+// the HTML 4 set (which covers ISO Latin-1, ISO Greek and most symbols) and adds
+// the ISO entities JATS declares beyond it.
+var Entities = buildEntities()
+
+// isoExtraEntities holds ISO 8879 entities used by JATS/NLM that HTML 4 lacks.
+var isoExtraEntities = map[string]string{
+	// ISOpub / ISOnum
+	"ensp": " ", "emsp": " ", "emsp13": " ", "emsp14": " ",
+	"numsp": " ", "puncsp": " ", "hairsp": " ", "thinsp": " ",
+	"dash": "‐", "horbar": "―", "Vert": "‖", "nldr": "‥",
+	"mldr": "…", "caret": "⁁", "hybull": "⁃", "incare": "℅",
+	"half": "½", "frac13": "⅓", "frac23": "⅔", "frac15": "⅕",
+	"frac25": "⅖", "frac35": "⅗", "frac45": "⅘", "frac16": "⅙",
+	"frac56": "⅚", "frac18": "⅛", "frac38": "⅜", "frac58": "⅝",
+	"frac78": "⅞", "ohm": "Ω", "angst": "Å", "check": "✓",
+	"cross": "✗", "male": "♂", "female": "♀", "phone": "☎",
+	"star": "☆", "starf": "★", "sharp": "♯", "flat": "♭",
+	"natur": "♮", "ell": "ℓ", "blank": "␣", "block": "█",
+	"squ": "□", "square": "□", "squf": "▪", "rect": "▭",
+	"marker": "▮", "utri": "▵", "utrif": "▴", "dtri": "▿",
+	"dtrif": "▾", "ltri": "◃", "ltrif": "◂", "rtri": "▹",
+	"rtrif": "▸", "sext": "✶", "lsquor": "‚", "ldquor": "„",
+	"colon": ":", "comma": ",", "commat": "@", "dollar": "$", "excl": "!",
+	"equals": "=", "num": "#", "percnt": "%", "period": ".", "plus": "+",
+	"quest": "?", "semi": ";", "sol": "/", "bsol": "\\", "verbar": "|",
+	"lowbar": "_", "lpar": "(", "rpar": ")", "lsqb": "[", "rsqb": "]",
+	"lcub": "{", "rcub": "}", "ast": "*", "hyphen": "-",
+	// ISOamsr / ISOamsb / ISOtech
+	"ap": "≈", "ape": "≊", "bsim": "∽", "cong": "≅",
+	"esdot": "≐", "gsim": "≳", "lsim": "≲", "npr": "⊀",
+	"nsc": "⊁", "pr": "≺", "sc": "≻", "sime": "≃",
+	"ges": "⩾", "les": "⩽", "gE": "≧", "lE": "≦",
+	"Gt": "≫", "Lt": "≪", "nlt": "≮", "ngt": "≯",
+	"nle": "≰", "nge": "≱", "ne": "≠", "plusmn": "±",
+	"mnplus": "∓", "times": "×", "divide": "÷", "compfn": "∘",
+	"Prime": "″", "tprime": "‴", "conint": "∮",
+	"par": "∥", "npar": "∦", "perp": "⊥",
+	// ISOgrk1 / ISOgrk3 variants
+	"agr": "α", "bgr": "β", "ggr": "γ", "dgr": "δ",
+	"egr": "ε", "zgr": "ζ", "eegr": "η", "thgr": "θ",
+	"igr": "ι", "kgr": "κ", "lgr": "λ", "mgr": "μ",
+	"ngr": "ν", "xgr": "ξ", "ogr": "ο", "pgr": "π",
+	"rgr": "ρ", "sgr": "σ", "tgr": "τ", "ugr": "υ",
+	"phgr": "φ", "khgr": "χ", "psgr": "ψ", "ohgr": "ω",
+	"Agr": "Α", "Bgr": "Β", "Ggr": "Γ", "Dgr": "Δ",
+	"Egr": "Ε", "Zgr": "Ζ", "EEgr": "Η", "THgr": "Θ",
+	"Igr": "Ι", "Kgr": "Κ", "Lgr": "Λ", "Mgr": "Μ",
+	"Ngr": "Ν", "Xgr": "Ξ", "Ogr": "Ο", "Pgr": "Π",
+	"Rgr": "Ρ", "Sgr": "Σ", "Tgr": "Τ", "Ugr": "Υ",
+	"PHgr": "Φ", "KHgr": "Χ", "PSgr": "Ψ", "OHgr": "Ω",
+	"epsi": "ε", "epsiv": "ϵ", "thetav": "ϑ", "phiv": "ϕ",
+	"piv": "ϖ", "sigmav": "ς", "kappav": "ϰ", "rhov": "ϱ",
+	"gammad": "ϝ", "Gammad": "Ϝ", "b.alpha": "α", "b.beta": "β",
+	// ISOlat2 (common in author names)
+	"abreve": "ă", "aogon": "ą", "cacute": "ć", "ccaron": "č",
+	"dcaron": "ď", "ecaron": "ě", "eogon": "ę", "gbreve": "ğ",
+	"lstrok": "ł", "nacute": "ń", "ncaron": "ň", "odblac": "ő",
+	"rcaron": "ř", "sacute": "ś", "scedil": "ş", "tcaron": "ť",
+	"uring": "ů", "udblac": "ű", "zacute": "ź", "zdot": "ż",
+	"zcaron": "ž", "Ccaron": "Č", "Lstrok": "Ł", "Scaron": "Š",
+	"Scedil": "Ş", "Zcaron": "Ž", "Rcaron": "Ř", "Ecaron": "Ě",
+	"inodot": "ı", "Idot": "İ", "amacr": "ā", "emacr": "ē",
+	"imacr": "ī", "omacr": "ō", "umacr": "ū",
+}
+
+func buildEntities() map[string]string {
+	entities := make(map[string]string, len(xml.HTMLEntity)+len(isoExtraEntities))
+	for name, value := range xml.HTMLEntity {
+		entities[name] = value
+	}
+	for name, value := range isoExtraEntities {
+		entities[name] = value
+	}
+	return entities
+}
+
+// predefinedEntities are the five entities every XML decoder understands.
+var predefinedEntities = map[string]bool{"amp": true, "lt": true, "gt": true, "quot": true, "apos": true}
+
+// entityRefPattern matches named entity references such as &nbsp;.
+var entityRefPattern = regexp.MustCompile(`&([A-Za-z][A-Za-z0-9.\-]*);`)
+
+// ------------------------ Charsets ------------------------
+
+// windows1252High maps bytes 0x80-0x9F of Windows-1252 to Unicode.
+// The remaining bytes coincide with ISO-8859-1.
+var windows1252High = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', '\u008D', 'Ž', '\u008F',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', '\u009D', 'ž', 'Ÿ',
+}
+
+// singleByteReader transcodes a single-byte encoding to UTF-8.
+type singleByteReader struct {
+	src     *bufio.Reader
+	decode  func(b byte) rune
+	pending []byte
+}
+
+func (r *singleByteReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(r.pending) > 0 {
+			c := copy(p[n:], r.pending)
+			r.pending = r.pending[c:]
+			n += c
+			continue
+		}
+		b, err := r.src.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		var buf [utf8.UTFMax]byte
+		size := utf8.EncodeRune(buf[:], r.decode(b))
+		r.pending = append(r.pending[:0], buf[:size]...)
+	}
+	return n, nil
+}
+
+// charsetReader returns a CharsetReader for xml.Decoder that understands the
+// legacy encodings seen in PubMed/PMC archives and records which one was used.
+func charsetReader(info *ParseInfo) func(string, io.Reader) (io.Reader, error) {
+	return func(charset string, input io.Reader) (io.Reader, error) {
+		name := strings.ToLower(strings.TrimSpace(charset))
+		var decode func(b byte) rune
+
+		switch name {
+		case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "latin-1", "l1":
+			decode = func(b byte) rune { return rune(b) }
+		case "windows-1252", "cp1252", "x-cp1252":
+			decode = func(b byte) rune {
+				if b >= 0x80 && b <= 0x9F {
+					return windows1252High[b-0x80]
+				}
+				return rune(b)
+			}
+		case "us-ascii", "ascii":
+			decode = func(b byte) rune { return rune(b) }
+		default:
+			return nil, fmt.Errorf("unsupported XML encoding %q", charset)
+		}
+
+		info.addFixup("charset:" + name)
+		return &singleByteReader{src: bufio.NewReader(input), decode: decode}, nil
+	}
+}
+
+// ------------------------ Decoding ------------------------
+
+// newDecoder returns an xml.Decoder configured for legacy PubMed/PMC input.
+// Charset conversions it performs are recorded in info.
+func newDecoder(r io.Reader, strict bool, info *ParseInfo) *xml.Decoder {
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = charsetReader(info)
+	dec.Entity = Entities
+	dec.Strict = strict
+	if !strict {
+		dec.AutoClose = xml.HTMLAutoClose
+	}
+	return dec
+}
+
+// filter wraps dec with opts.Tokens, keeping its strictness; dec itself without a filter.
+func (opts DecodeOptions) filter(dec *xml.Decoder) *xml.Decoder {
+	if opts.Tokens == nil {
+		return dec
+	}
+	filtered := xml.NewTokenDecoder(opts.Tokens(dec))
+	filtered.Strict = dec.Strict
+	return filtered
+}
+
+/*
+decodeXML unmarshals xmlBytes into v using the legacy-aware decoder.
+
+Parameters:
+  - xmlBytes: Raw document.
+  - v: Pointer to the target structure.
+  - opts: Decoding options.
+
+Returns:
+  - The fixups applied for this attempt.
+  - The strict decoding error if the document could not be read.
+
+Behavior:
+  - Decodes strictly first.
+  - If that fails and opts.Lenient is set, retries with a non-strict decoder
+    into a fresh value and records "non-strict".
+  - Records every non-predefined entity referenced by the document.
+  - Both attempts read the token stream through opts.Tokens, if set.
+*/
+func decodeXML(xmlBytes []byte, v interface{}, opts DecodeOptions) (ParseInfo, error) {
+	var info ParseInfo
+	err := opts.filter(newDecoder(bytes.NewReader(xmlBytes), true, &info)).Decode(v)
+
+	if err != nil && opts.Lenient {
+		var retry ParseInfo
+		// Discard whatever the failed strict attempt managed to fill in
+		target := reflect.ValueOf(v).Elem()
+		target.Set(reflect.Zero(target.Type()))
+		if retryErr := opts.filter(newDecoder(bytes.NewReader(xmlBytes), false, &retry)).Decode(v); retryErr == nil {
+			info = retry
+			info.addFixup("non-strict")
+			err = nil
+		}
+	}
+	if err != nil {
+		return info, err
+	}
+
+	for _, name := range referencedEntities(xmlBytes) {
+		info.addFixup("entity:" + name)
+	}
+	return info, nil
+}
+
+// referencedEntities lists, in sorted order, the named entities in xmlBytes that
+// need the Entities map (i.e. everything except the five XML predefined ones).
+func referencedEntities(xmlBytes []byte) []string {
+	seen := map[string]bool{}
+	for _, m := range entityRefPattern.FindAllSubmatch(xmlBytes, -1) {
+		name := string(m[1])
+		if !predefinedEntities[name] {
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// addFixup records a fixup once.
+func (p *ParseInfo) addFixup(fixup string) {
+	for _, f := range p.Fixups {
+		if f == fixup {
+			return
+		}
+	}
+	p.Fixups = append(p.Fixups, fixup)
+}
@@ -0,0 +1,176 @@
+package xmlTools_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Test: ParsePubmedXMLWithOptions ------------------------
+//
+
+// writeXML writes raw bytes to a temporary .xml file and returns its path.
+func writeXML(t *testing.T, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "input.xml")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	return path
+}
+
+// TestParse_Latin1AndEntities verifies that an ISO-8859-1 PubMed file using
+// DTD entities decodes correctly and that both fixups are reported.
+func TestParse_Latin1AndEntities(t *testing.T) {
+	doc := append([]byte(`<?xml version="1.0" encoding="ISO-8859-1"?>
+<PubmedArticleSet><PubmedArticle><MedlineCitation><PMID>1</PMID><Article>
+<ArticleTitle>Caf`), 0xE9) // "é" in Latin-1
+	doc = append(doc, []byte(` effects&nbsp;&ndash; &agr;-blockers</ArticleTitle>
+</Article></MedlineCitation></PubmedArticle></PubmedArticleSet>`)...)
+
+	data, info, err := xmlTools.ParsePubmedXMLWithOptions(writeXML(t, doc), xmlTools.DecodeOptions{})
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	set, ok := data.(*xmlTools.PubmedArticleSet)
+	if !ok {
+		t.Fatalf("expected *PubmedArticleSet, got %T", data)
+	}
+	title := set.PubmedArticles[0].MedlineCitation.Article.ArticleTitle
+	if title != "Café effects – α-blockers" {
+		t.Errorf("unexpected title %q", title)
+	}
+
+	joined := strings.Join(info.Fixups, ",")
+	for _, want := range []string{"charset:iso-8859-1", "entity:agr", "entity:nbsp", "entity:ndash"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected fixup %q in %v", want, info.Fixups)
+		}
+	}
+}
+
+// TestParse_Lenient verifies that a document with an undeclared entity fails in
+// strict mode with a descriptive error and is recovered in lenient mode.
+func TestParse_Lenient(t *testing.T) {
+	path := writeXML(t, []byte(`<article><front><article-meta>
+<title-group><article-title>Bad &undeclared; entity</article-title></title-group>
+</article-meta></front></article>`))
+
+	_, _, err := xmlTools.ParsePubmedXMLWithOptions(path, xmlTools.DecodeOptions{})
+	if err == nil {
+		t.Fatal("expected strict parse to fail, got nil")
+	}
+	if !strings.Contains(err.Error(), "undeclared") {
+		t.Errorf("expected decoder error to be surfaced, got %v", err)
+	}
+
+	data, info, err := xmlTools.ParsePubmedXMLWithOptions(path, xmlTools.DecodeOptions{Lenient: true})
+	if err != nil {
+		t.Fatalf("unexpected lenient parse error: %v", err)
+	}
+	if _, ok := data.(*xmlTools.PMCArticle); !ok {
+		t.Fatalf("expected *PMCArticle, got %T", data)
+	}
+	if !strings.Contains(strings.Join(info.Fixups, ","), "non-strict") {
+		t.Errorf("expected non-strict fixup, got %v", info.Fixups)
+	}
+}
+
+// TestParse_InlineMarkup verifies that inline markup is left out of the structs by
+// default, so that JSON never glues citation markers to the text, and that a
+// DecodeOptions.Tokens filter sees the token stream.
+func TestParse_InlineMarkup(t *testing.T) {
+	path := writeXML(t, []byte(`<article><front><article-meta>
+<title-group><article-title>Effects</article-title></title-group>
+</article-meta></front><body><sec><title>Intro</title>
+<p>Testing matters<xref ref-type="bibr" rid="r1">1</xref>.</p>
+</sec></body></article>`))
+
+	data, _, err := xmlTools.ParsePubmedXMLWithOptions(path, xmlTools.DecodeOptions{})
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if got := data.(*xmlTools.PMCArticle).Body.Sections[0].Paragraphs[0]; got != "Testing matters." {
+		t.Errorf("unexpected paragraph %q", got)
+	}
+
+	upper := func(r xml.TokenReader) xml.TokenReader { return upperCaseReader{r} }
+	data, _, err = xmlTools.ParsePubmedXMLWithOptions(path, xmlTools.DecodeOptions{Tokens: upper})
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if got := data.(*xmlTools.PMCArticle).Front.ArticleMeta.TitleGroup.ArticleTitle; got != "EFFECTS" {
+		t.Errorf("expected the filter to apply, got title %q", got)
+	}
+}
+
+// upperCaseReader upper-cases character data.
+type upperCaseReader struct{ r xml.TokenReader }
+
+func (u upperCaseReader) Token() (xml.Token, error) {
+	tok, err := u.r.Token()
+	if data, ok := tok.(xml.CharData); ok {
+		return xml.CharData(bytes.ToUpper(data)), err
+	}
+	return tok, err
+}
+
+// TestParse_RecordRecovery verifies that a malformed article costs only itself: the
+// other articles of the set are kept (in the file's charset) and the bad one is
+// reported with its offset, PMID and file line.
+func TestParse_RecordRecovery(t *testing.T) {
+	article := func(pmid, title string) string {
+		return "<PubmedArticle><MedlineCitation><PMID>" + pmid + "</PMID><Article><ArticleTitle>" +
+			title + "</ArticleTitle></Article></MedlineCitation></PubmedArticle>\n"
+	}
+	head := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<PubmedArticleSet>\n"
+	bad := article("2", "<i>Unclosed</ArticleTitle>")
+
+	t.Run("bad record is skipped", func(t *testing.T) {
+		doc := []byte(head + article("1", "Caf\xe9") + bad + article("3", "Third") + "</PubmedArticleSet>") // "é" in Latin-1
+
+		data, info, err := xmlTools.ParsePubmedXMLWithOptions(writeXML(t, doc), xmlTools.DecodeOptions{})
+		if err != nil {
+			t.Fatalf("unexpected parse error: %v", err)
+		}
+		set := data.(*xmlTools.PubmedArticleSet)
+		if len(set.PubmedArticles) != 2 || set.PubmedArticles[1].MedlineCitation.PMID != "3" {
+			t.Fatalf("expected articles 1 and 3, got %+v", set.PubmedArticles)
+		}
+		if title := set.PubmedArticles[0].MedlineCitation.Article.ArticleTitle; title != "Café" {
+			t.Errorf("expected the record to keep the file's charset, got %q", title)
+		}
+		if set.SourceFormat == nil || set.SourceFormat.RootElement != "PubmedArticleSet" {
+			t.Errorf("expected the source format on the recovered set, got %+v", set.SourceFormat)
+		}
+
+		if len(info.Skipped) != 1 {
+			t.Fatalf("expected one skipped record, got %v", info.Skipped)
+		}
+		skipped := info.Skipped[0]
+		if skipped.PMID != "2" || skipped.Offset != int64(strings.Index(string(doc), "<PubmedArticle><MedlineCitation><PMID>2")) {
+			t.Errorf("unexpected skipped record %s", skipped)
+		}
+		if !strings.Contains(skipped.String(), "line 4") {
+			t.Errorf("expected the syntax error at file line 4, got %s", skipped)
+		}
+		if !strings.Contains(strings.Join(info.Fixups, ","), "record-recovery") {
+			t.Errorf("expected a record-recovery fixup in %v", info.Fixups)
+		}
+	})
+
+	t.Run("no record decodes", func(t *testing.T) {
+		doc := head + bad + "</PubmedArticleSet>"
+		if _, _, err := xmlTools.ParsePubmedXMLWithOptions(writeXML(t, []byte(doc)), xmlTools.DecodeOptions{}); err == nil ||
+			!strings.Contains(err.Error(), "none of the 1 records") {
+			t.Errorf("expected a record recovery error, got %v", err)
+		}
+	})
+}
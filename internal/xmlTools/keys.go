@@ -0,0 +1,216 @@
+package xmlTools
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaVersion is written as "schema_version" at the top of every JSON document.
+// It changes whenever a key is renamed, removed or changes type (3.0: abstract_text
+// became a list of sections); adding keys does not change it.
+const SchemaVersion = "3.0"
+
+// LegacySchemaVersion marks documents written with --legacy-keys: the Go field names
+// used as JSON keys before the snake_case schema.
+const LegacySchemaVersion = "1.0"
+
+//
+// ------------------------ Legacy keys ------------------------
+//
+
+// legacyName returns the pre-2.0 JSON key of a struct field: its legacy tag, or
+// the Go field name for fields that had no json tag.
+func legacyName(f reflect.StructField) string {
+	if name := f.Tag.Get("legacy"); name != "" {
+		return name
+	}
+	return f.Name
+}
+
+/*
+ToLegacyKeys renames the snake_case keys of a marshaled document to the legacy
+Go field names.
+
+Parameters:
+  - raw: JSON produced by marshaling v (possibly projected).
+  - v: The marshaled value, used for its type.
+
+Returns:
+  - The document with renamed keys, in the same order. Keys that are not struct
+    fields (such as "schema_version") are kept as they are.
+*/
+func ToLegacyKeys(raw []byte, v interface{}) ([]byte, error) {
+	return renameKeys(raw, reflect.TypeOf(v), func(f reflect.StructField) (string, string) {
+		name, _ := jsonName(f)
+		return name, legacyName(f)
+	})
+}
+
+/*
+FromLegacyKeys renames legacy keys to snake_case so that pre-2.0 output can be
+decoded into the current structs.
+
+Parameters:
+  - raw: A legacy JSON document.
+  - v: A value of the type the document decodes into, e.g. &PMCArticle{}.
+
+Returns:
+  - The document with snake_case keys.
+*/
+func FromLegacyKeys(raw []byte, v interface{}) ([]byte, error) {
+	return renameKeys(raw, reflect.TypeOf(v), func(f reflect.StructField) (string, string) {
+		name, _ := jsonName(f)
+		return legacyName(f), name
+	})
+}
+
+/*
+LegacyPointer converts a JSON pointer into a snake_case document to the same value
+in the document written with --legacy-keys.
+
+Parameters:
+  - pointer: A pointer such as "/pubmed_articles/3/medline_citation/pmid".
+  - v: The marshaled value, used for its type.
+
+Returns:
+  - The pointer with legacy keys, e.g. "/PubmedArticles/3/MedlineCitation/PMID".
+    Segments that are not struct fields are kept as they are.
+*/
+func LegacyPointer(pointer string, v interface{}) string {
+	segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	t := reflect.TypeOf(v)
+	for i, segment := range segments {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch {
+		case t == nil:
+		case t.Kind() == reflect.Struct:
+			var next reflect.Type
+			for j := 0; j < t.NumField(); j++ {
+				if name, ok := jsonName(t.Field(j)); ok && name == segment {
+					segments[i], next = legacyName(t.Field(j)), t.Field(j).Type
+					break
+				}
+			}
+			t = next
+		case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+			t = t.Elem()
+		default:
+			t = nil
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// renameKeys walks raw alongside type t and renames the keys of struct objects.
+// rename maps a field to its (current key, new key).
+func renameKeys(raw []byte, t reflect.Type, rename func(reflect.StructField) (string, string)) ([]byte, error) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	raw = bytes.TrimSpace(raw)
+	if t == nil || len(raw) == 0 {
+		return raw, nil
+	}
+
+	switch {
+	case t.Kind() == reflect.Struct && raw[0] == '{':
+		fields := map[string]reflect.StructField{}
+		for i := 0; i < t.NumField(); i++ {
+			if _, ok := jsonName(t.Field(i)); ok {
+				from, _ := rename(t.Field(i))
+				fields[from] = t.Field(i)
+			}
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := tok.(string)
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return nil, err
+			}
+
+			if f, ok := fields[key]; ok {
+				_, key = rename(f)
+				if value, err = renameKeys(value, f.Type, rename); err != nil {
+					return nil, err
+				}
+			}
+			if buf.Len() > 1 {
+				buf.WriteByte(',')
+			}
+			name, _ := json.Marshal(key)
+			buf.Write(name)
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+		buf.WriteByte('}')
+		return buf.Bytes(), nil
+
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && raw[0] == '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, item := range items {
+			item, err := renameKeys(item, t.Elem(), rename)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(item)
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), nil
+
+	default:
+		return raw, nil
+	}
+}
+
+//
+// ------------------------ WithSchemaVersion ------------------------
+//
+
+// WithSchemaVersion returns the JSON object raw with "schema_version" as its first
+// key, replacing any existing value. Non-object documents are returned unchanged.
+func WithSchemaVersion(raw []byte, version string) []byte {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] != '{' {
+		return raw
+	}
+
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &keys); err == nil {
+		if _, ok := keys["schema_version"]; ok {
+			raw, _ = pruneJSON(raw, nil, true, [][]string{{"schema_version"}})
+		}
+	}
+
+	stamp, _ := json.Marshal(version)
+	var buf bytes.Buffer
+	buf.WriteString(`{"schema_version":`)
+	buf.Write(stamp)
+	if body := bytes.TrimSpace(raw[1:]); len(body) > 0 && body[0] != '}' {
+		buf.WriteByte(',')
+	}
+	buf.Write(raw[1:])
+	return buf.Bytes()
+}
@@ -0,0 +1,184 @@
+package xmlTools_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Test: JSON tags ------------------------
+//
+
+// snakeCase matches the JSON keys of the public output schema.
+var snakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// TestJSONTags verifies that every exported field of the output structs has an
+// explicit snake_case json tag and that no struct repeats a key.
+func TestJSONTags(t *testing.T) {
+	seen := map[reflect.Type]bool{}
+	var walk func(t reflect.Type)
+	check := func(typ reflect.Type) {
+		keys := map[string]string{}
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name := strings.Split(tag, ",")[0]
+			if !snakeCase.MatchString(name) {
+				t.Errorf("%s.%s: json key %q is not snake_case", typ.Name(), f.Name, name)
+			}
+			if other, ok := keys[name]; ok {
+				t.Errorf("%s: fields %s and %s share json key %q", typ.Name(), other, f.Name, name)
+			}
+			keys[name] = f.Name
+			walk(f.Type)
+		}
+	}
+	walk = func(typ reflect.Type) {
+		for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			typ = typ.Elem()
+		}
+		if typ.Kind() != reflect.Struct || seen[typ] {
+			return
+		}
+		seen[typ] = true
+		check(typ)
+	}
+
+	walk(reflect.TypeOf(xmlTools.PubmedArticleSet{}))
+	walk(reflect.TypeOf(xmlTools.PubmedBookArticleSet{}))
+	walk(reflect.TypeOf(xmlTools.PMCArticle{}))
+}
+
+//
+// ------------------------ Test: Legacy keys ------------------------
+//
+
+// TestLegacyKeys_RoundTrip verifies that legacy keys are the Go field names (or
+// legacy tags) and that converting back restores the snake_case document.
+func TestLegacyKeys_RoundTrip(t *testing.T) {
+	set := &xmlTools.PubmedArticleSet{
+		PubmedArticles: []xmlTools.PubmedArticle{{}},
+		SourceFormat:   &xmlTools.SourceFormat{RootElement: "PubmedArticleSet", Family: "pubmed"},
+	}
+	set.PubmedArticles[0].MedlineCitation.PMID = "1"
+	set.PubmedArticles[0].MedlineCitation.Article.ArticleTitle = "Title"
+
+	canonical, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	legacy, err := xmlTools.ToLegacyKeys(canonical, set)
+	if err != nil {
+		t.Fatalf("ToLegacyKeys failed: %v", err)
+	}
+
+	got := string(legacy)
+	for _, want := range []string{`"PubmedArticles":[`, `"MedlineCitation":{`, `"PMID":"1"`, `"ArticleTitle":"Title"`, `"source_format":{`, `"root_element":"PubmedArticleSet"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected legacy JSON to contain %s, got %s", want, got)
+		}
+	}
+	if strings.Contains(got, `"medline_citation"`) {
+		t.Errorf("expected no snake_case struct keys, got %s", got)
+	}
+
+	back, err := xmlTools.FromLegacyKeys(legacy, set)
+	if err != nil {
+		t.Fatalf("FromLegacyKeys failed: %v", err)
+	}
+	if string(back) != string(canonical) {
+		t.Errorf("round trip changed the document:\n got %s\nwant %s", back, canonical)
+	}
+}
+
+// TestLegacyPointer verifies that pointers follow the renamed keys through structs
+// and arrays and keep segments that are not struct fields.
+func TestLegacyPointer(t *testing.T) {
+	tests := []struct {
+		pointer string      // snake_case pointer
+		value   interface{} // Marshaled value
+		want    string      // Legacy pointer
+	}{
+		{"/pubmed_articles/3/medline_citation/pmid", &xmlTools.PubmedArticleSet{}, "/PubmedArticles/3/MedlineCitation/PMID"},
+		{"/medline_citation/date_revised", &xmlTools.PubmedArticle{}, "/MedlineCitation/DateRevised"},
+		{"/source_format/family", &xmlTools.PubmedArticleSet{}, "/source_format/family"},
+		{"/front/article_meta/article_id/0/value", &xmlTools.PMCArticle{}, "/Front/ArticleMeta/ArticleID/0/Value"},
+		{"/schema_version", &xmlTools.PMCArticle{}, "/schema_version"},
+	}
+
+	for _, test := range tests {
+		if got := xmlTools.LegacyPointer(test.pointer, test.value); got != test.want {
+			t.Errorf("LegacyPointer(%q) = %q, want %q", test.pointer, got, test.want)
+		}
+	}
+}
+
+//
+// ------------------------ Test: WithSchemaVersion ------------------------
+//
+
+// TestWithSchemaVersion verifies that the version becomes the first key and
+// replaces an existing one.
+func TestWithSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name string // Descriptive name for subtest
+		in   string // Input document
+		want string // Expected output
+	}{
+		{"object", `{"a":1}`, `{"schema_version":"3.0","a":1}`},
+		{"empty object", `{}`, `{"schema_version":"3.0"}`},
+		{"existing version", `{"a":1,"schema_version":"1.0"}`, `{"schema_version":"3.0","a":1}`},
+		{"not an object", `[1]`, `[1]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := string(xmlTools.WithSchemaVersion([]byte(test.in), xmlTools.SchemaVersion))
+			if got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+//
+// ------------------------ Test: AbstractTexts ------------------------
+//
+
+// TestAbstractTexts_UnmarshalJSON verifies that the section list and the single string
+// written before schema 3.0 both decode.
+func TestAbstractTexts_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string                 // abstract_text value
+		want xmlTools.AbstractTexts // Expected sections
+	}{
+		{"sections", `[{"label":"METHODS","nlm_category":"METHODS","text":"How."}]`, xmlTools.AbstractTexts{{Label: "METHODS", NlmCategory: "METHODS", Text: "How."}}},
+		{"pre-3.0 string", `"Text."`, xmlTools.AbstractTexts{{Text: "Text."}}},
+		{"empty string", `""`, nil},
+		{"null", `null`, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var abstract xmlTools.Abstract
+			if err := json.Unmarshal([]byte(`{"abstract_text":`+test.raw+`}`), &abstract); err != nil {
+				t.Fatalf("unmarshal failed: %v", err)
+			}
+			if !reflect.DeepEqual(abstract.AbstractText, test.want) {
+				t.Errorf("expected %+v, got %+v", test.want, abstract.AbstractText)
+			}
+		})
+	}
+}
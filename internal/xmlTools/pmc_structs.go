@@ -4,255 +4,268 @@ import "encoding/xml"
 
 // PMCArticle represents the root element of a JATS XML article.
 type PMCArticle struct {
-	XMLName     xml.Name        `xml:"article"`
-	ArticleType string          `xml:"article-type,attr"`
-	Front       PMCFront        `xml:"front"`
-	Body        *PMCBody        `xml:"body,omitempty"`
-	Back        *PMCBack        `xml:"back,omitempty"`
-	FloatsGroup *PMCFloatsGroup `xml:"floats-group,omitempty"`
+	XMLName      xml.Name        `xml:"article" json:"-"`
+	ArticleType  string          `xml:"article-type,attr" json:"article_type"`
+	Front        PMCFront        `xml:"front" json:"front" schema:"required"`
+	Body         *PMCBody        `xml:"body,omitempty" json:"body"`
+	Back         *PMCBack        `xml:"back,omitempty" json:"back"`
+	FloatsGroup  *PMCFloatsGroup `xml:"floats-group,omitempty" json:"floats_group"`
+	ExtLinks     []PMCExtLink    `xml:"-" json:"-"`
+	RegistryIDs  []RegistryID    `xml:"-" json:"registry_ids"`
+	SourceFormat *SourceFormat   `xml:"-" json:"source_format,omitempty" legacy:"source_format"`
+}
+
+// PMCExtLink is an <ext-link> found anywhere in the article.
+// Links nested in paragraphs are lost by the string-valued PMC fields,
+// so they are collected in a separate pass over the XML (see scanExtLinks).
+type PMCExtLink struct {
+	Type     string `json:"type"`     // ext-link-type attribute, e.g. "clintrialgov" (see addLink)
+	Href     string `json:"href"`     // xlink:href attribute
+	Text     string `json:"text"`     // link text
+	Location string `json:"location"` // enclosing part of the article: "metadata", "abstract", "body" or "back"
 }
 
 // PMCFloatsGroup represents a group of floating objects such as figures and tables.
 type PMCFloatsGroup struct {
-	Figures []PMCFigure    `xml:"fig"`
-	Tables  []PMCTableWrap `xml:"table-wrap"`
+	Figures []PMCFigure    `xml:"fig" json:"figures"`
+	Tables  []PMCTableWrap `xml:"table-wrap" json:"tables"`
 }
 
 // PMCFront contains metadata about the article.
 type PMCFront struct {
-	JournalMeta PMCJournalMeta `xml:"journal-meta"`
-	ArticleMeta PMCArticleMeta `xml:"article-meta"`
+	JournalMeta PMCJournalMeta `xml:"journal-meta" json:"journal_meta"`
+	ArticleMeta PMCArticleMeta `xml:"article-meta" json:"article_meta"`
 }
 
 // PMCJournalMeta holds metadata about the publishing journal.
 type PMCJournalMeta struct {
-	JournalID    []PMCID      `xml:"journal-id"`
-	JournalTitle string       `xml:"journal-title-group>journal-title"`
-	ISSN         []PMCISSN    `xml:"issn"`
-	Publisher    PMCPublisher `xml:"publisher"`
+	JournalID    []PMCID      `xml:"journal-id" json:"journal_id"`
+	JournalTitle string       `xml:"journal-title-group>journal-title" json:"journal_title"`
+	ISSN         []PMCISSN    `xml:"issn" json:"issn"`
+	Publisher    PMCPublisher `xml:"publisher" json:"publisher"`
 }
 
 // PMCID is a journal identifier.
 type PMCID struct {
-	IDType string `xml:"journal-id-type,attr"`
-	Value  string `xml:",chardata"`
+	IDType string `xml:"journal-id-type,attr" json:"id_type"`
+	Value  string `xml:",chardata" json:"value"`
 }
 
 type PMCISSN struct {
-	PubType string `xml:"pub-type,attr"`
-	Value   string `xml:",chardata"`
+	PubType string `xml:"pub-type,attr" json:"pub_type"`
+	Value   string `xml:",chardata" json:"value"`
 }
 
 type PMCPublisher struct {
-	PublisherName string `xml:"publisher-name"`
-	PublisherLoc  string `xml:"publisher-loc"`
+	PublisherName string `xml:"publisher-name" json:"publisher_name"`
+	PublisherLoc  string `xml:"publisher-loc" json:"publisher_loc"`
 }
 
 // PMCArticleMeta describes the metadata of the article.
 type PMCArticleMeta struct {
-	ArticleID         []PMCArticleID      `xml:"article-id"`
-	ArticleCategories []PMCSubjectGroup   `xml:"article-categories>subj-group"`
-	TitleGroup        PMCTitleGroup       `xml:"title-group"`
-	ContribGroup      []PMCContribGroup   `xml:"contrib-group"`
-	AuthorNotes       *PMCAuthorNotes     `xml:"author-notes"`
-	PubDate           []PMCPubDate        `xml:"pub-date"`
-	History           []PMCDate           `xml:"history>date"`
-	Abstract          *PMCAbstract        `xml:"abstract"`
-	Permissions       *PMCPermissions     `xml:"permissions"`
-	SelfURI           *PMCSelfURI         `xml:"self-uri"`
-	RelatedArticle    *PMCRelatedArticle  `xml:"related-article"`
-	CustomMetaGroup   *PMCCustomMetaGroup `xml:"custom-meta-group"`
-	Volume            string              `xml:"volume"`
-	Issue             string              `xml:"issue"`
-	FPage             string              `xml:"fpage"`
-	LPage             string              `xml:"lpage"`
-	AffList           []PMCAff            `xml:"aff"`
+	ArticleID         []PMCArticleID      `xml:"article-id" json:"article_id"`
+	ArticleCategories []PMCSubjectGroup   `xml:"article-categories>subj-group" json:"article_categories"`
+	TitleGroup        PMCTitleGroup       `xml:"title-group" json:"title_group"`
+	ContribGroup      []PMCContribGroup   `xml:"contrib-group" json:"contrib_group"`
+	AuthorNotes       *PMCAuthorNotes     `xml:"author-notes" json:"author_notes"`
+	PubDate           []PMCPubDate        `xml:"pub-date" json:"pub_date"`
+	History           []PMCDate           `xml:"history>date" json:"history"`
+	Abstract          *PMCAbstract        `xml:"abstract" json:"abstract"`
+	Permissions       *PMCPermissions     `xml:"permissions" json:"permissions"`
+	SelfURI           *PMCSelfURI         `xml:"self-uri" json:"self_uri"`
+	RelatedArticle    *PMCRelatedArticle  `xml:"related-article" json:"related_article"`
+	CustomMetaGroup   *PMCCustomMetaGroup `xml:"custom-meta-group" json:"custom_meta_group"`
+	Volume            string              `xml:"volume" json:"volume"`
+	Issue             string              `xml:"issue" json:"issue"`
+	FPage             string              `xml:"fpage" json:"fpage"`
+	LPage             string              `xml:"lpage" json:"lpage"`
+	AffList           []PMCAff            `xml:"aff" json:"aff_list"`
 }
 
 type PMCArticleID struct {
-	IDType string `xml:"pub-id-type,attr"`
-	Value  string `xml:",chardata"`
+	IDType string `xml:"pub-id-type,attr" json:"id_type"`
+	Value  string `xml:",chardata" json:"value"`
 }
 
 type PMCSubjectGroup struct {
-	SubjectGroupType string   `xml:"subj-group-type,attr"`
-	Subjects         []string `xml:"subject"`
+	SubjectGroupType string   `xml:"subj-group-type,attr" json:"subject_group_type"`
+	Subjects         []string `xml:"subject" json:"subjects"`
 }
 
 type PMCTitleGroup struct {
-	ArticleTitle string `xml:"article-title"`
+	ArticleTitle string `xml:"article-title" json:"article_title"`
 }
 
 type PMCContribGroup struct {
-	Contrib []PMCContrib `xml:"contrib"`
+	Contrib []PMCContrib `xml:"contrib" json:"contrib"`
 }
 
 type PMCContrib struct {
-	ContribType string  `xml:"contrib-type,attr"`
-	Name        PMCName `xml:"name"`
-	Degrees     string  `xml:"degrees"`
-	Aff         *PMCAff `xml:"aff,omitempty"`
-	Corresp     string  `xml:"corresp,attr,omitempty"`
+	ContribType string  `xml:"contrib-type,attr" json:"contrib_type"`
+	Name        PMCName `xml:"name" json:"name"`
+	Degrees     string  `xml:"degrees" json:"degrees"`
+	Aff         *PMCAff `xml:"aff,omitempty" json:"aff"`
+	Corresp     string  `xml:"corresp,attr,omitempty" json:"corresp"`
 }
 
 type PMCName struct {
-	Surname    string `xml:"surname"`
-	GivenNames string `xml:"given-names"`
+	Surname    string `xml:"surname" json:"surname"`
+	GivenNames string `xml:"given-names" json:"given_names"`
 }
 
 type PMCAff struct {
-	ID   string `xml:"id,attr,omitempty"`
-	Text string `xml:",chardata"`
+	ID   string `xml:"id,attr,omitempty" json:"id"`
+	Text string `xml:",chardata" json:"text"`
 }
 
 type PMCAuthorNotes struct {
-	Corresp []PMCCorresp `xml:"corresp"`
+	Corresp []PMCCorresp `xml:"corresp" json:"corresp"`
 }
 
 type PMCCorresp struct {
-	ID    string `xml:"id,attr"`
-	Email string `xml:"email"`
-	Text  string `xml:",chardata"`
+	ID    string `xml:"id,attr" json:"id"`
+	Email string `xml:"email" json:"email"`
+	Text  string `xml:",chardata" json:"text"`
 }
 
 type PMCPubDate struct {
-	PubType string `xml:"pub-type,attr"`
-	Year    string `xml:"year"`
-	Month   string `xml:"month,omitempty"`
-	Day     string `xml:"day,omitempty"`
+	PubType string `xml:"pub-type,attr" json:"pub_type"`
+	Year    string `xml:"year" json:"year"`
+	Month   string `xml:"month,omitempty" json:"month"`
+	Day     string `xml:"day,omitempty" json:"day"`
 }
 
 type PMCDate struct {
-	DateType string `xml:"date-type,attr"`
-	Year     string `xml:"year"`
-	Month    string `xml:"month,omitempty"`
-	Day      string `xml:"day,omitempty"`
+	DateType string `xml:"date-type,attr" json:"date_type"`
+	Year     string `xml:"year" json:"year"`
+	Month    string `xml:"month,omitempty" json:"month"`
+	Day      string `xml:"day,omitempty" json:"day"`
 }
 
 type PMCAbstract struct {
-	Title      string           `xml:"title"`
-	Paragraphs []string         `xml:"p"`
-	Sec        []PMCAbstractSec `xml:"sec"`
+	Title      string           `xml:"title" json:"title"`
+	Paragraphs []string         `xml:"p" json:"paragraphs"`
+	Sec        []PMCAbstractSec `xml:"sec" json:"sec"`
 }
 
 type PMCAbstractSec struct {
-	Title      string   `xml:"title"`
-	Paragraphs []string `xml:"p"`
+	Title      string   `xml:"title" json:"title"`
+	Paragraphs []string `xml:"p" json:"paragraphs"`
 }
 
 type PMCPermissions struct {
-	CopyrightStatement string `xml:"copyright-statement"`
+	CopyrightStatement string `xml:"copyright-statement" json:"copyright_statement"`
 }
 
 type PMCElementCitation struct {
-	PublicationType string    `xml:"publication-type,attr"`
-	ArticleTitle    string    `xml:"article-title"`
-	Source          string    `xml:"source"`
-	Year            string    `xml:"year"`
-	Volume          string    `xml:"volume"`
-	FPage           string    `xml:"fpage"`
-	LPage           string    `xml:"lpage"`
-	PubID           string    `xml:"pub-id"`
-	Name            []PMCName `xml:"name"`
+	PublicationType string    `xml:"publication-type,attr" json:"publication_type"`
+	ArticleTitle    string    `xml:"article-title" json:"article_title"`
+	Source          string    `xml:"source" json:"source"`
+	Year            string    `xml:"year" json:"year"`
+	Volume          string    `xml:"volume" json:"volume"`
+	FPage           string    `xml:"fpage" json:"fpage"`
+	LPage           string    `xml:"lpage" json:"lpage"`
+	PubID           string    `xml:"pub-id" json:"pub_id"`
+	Name            []PMCName `xml:"name" json:"name"`
 }
 
 type PMCSelfURI struct {
-	Href string `xml:"xlink:href,attr"`
+	Href string `xml:"xlink:href,attr" json:"href"`
 }
 
 type PMCRelatedArticle struct {
-	Type string `xml:"related-article-type,attr"`
-	ID   string `xml:"id,attr"`
-	Href string `xml:"xlink:href,attr"`
+	Type string `xml:"related-article-type,attr" json:"type"`
+	ID   string `xml:"id,attr" json:"id"`
+	Href string `xml:"xlink:href,attr" json:"href"`
 }
 
 type PMCCustomMetaGroup struct {
-	CustomMeta []PMCCustomMeta `xml:"custom-meta"`
+	CustomMeta []PMCCustomMeta `xml:"custom-meta" json:"custom_meta"`
 }
 
 type PMCCustomMeta struct {
-	Name  string `xml:"meta-name"`
-	Value string `xml:"meta-value"`
+	Name  string `xml:"meta-name" json:"name"`
+	Value string `xml:"meta-value" json:"value"`
 }
 
 type PMCBody struct {
-	Sections []PMCSection `xml:"sec"`
+	Sections []PMCSection `xml:"sec" json:"sections"`
 }
 
 type PMCSection struct {
-	ID          string         `xml:"id,attr,omitempty"`
-	SecType     string         `xml:"sec-type,attr,omitempty"`
-	Title       string         `xml:"title"`
-	Paragraphs  []string       `xml:"p"`
-	SubSections []PMCSection   `xml:"sec"`
-	Figures     []PMCFigure    `xml:"fig"`
-	Tables      []PMCTableWrap `xml:"table-wrap"`
-	XRefs       []PMCXRef      `xml:"xref"`
+	ID          string         `xml:"id,attr,omitempty" json:"id"`
+	SecType     string         `xml:"sec-type,attr,omitempty" json:"sec_type"`
+	Title       string         `xml:"title" json:"title"`
+	Paragraphs  []string       `xml:"p" json:"paragraphs"`
+	SubSections []PMCSection   `xml:"sec" json:"subsections"`
+	Figures     []PMCFigure    `xml:"fig" json:"figures"`
+	Tables      []PMCTableWrap `xml:"table-wrap" json:"tables"`
+	XRefs       []PMCXRef      `xml:"xref" json:"xrefs"`
 }
 
 type PMCXRef struct {
-	RefType string `xml:"ref-type,attr"`
-	RID     string `xml:"rid,attr"`
-	Text    string `xml:",chardata"`
+	RefType string `xml:"ref-type,attr" json:"ref_type"`
+	RID     string `xml:"rid,attr" json:"rid"`
+	Text    string `xml:",chardata" json:"text"`
 }
 
 type PMCTableWrap struct {
-	ID      string     `xml:"id,attr"`
-	Label   string     `xml:"label"`
-	Caption PMCCaption `xml:"caption"`
-	Graphic PMCGraphic `xml:"graphic"`
+	ID      string     `xml:"id,attr" json:"id"`
+	Label   string     `xml:"label" json:"label"`
+	Caption PMCCaption `xml:"caption" json:"caption"`
+	Graphic PMCGraphic `xml:"graphic" json:"graphic"`
 }
 
 type PMCFigure struct {
-	ID      string     `xml:"id,attr"`
-	Label   string     `xml:"label"`
-	Caption PMCCaption `xml:"caption"`
-	Graphic PMCGraphic `xml:"graphic"`
+	ID      string     `xml:"id,attr" json:"id"`
+	Label   string     `xml:"label" json:"label"`
+	Caption PMCCaption `xml:"caption" json:"caption"`
+	Graphic PMCGraphic `xml:"graphic" json:"graphic"`
 }
 
 type PMCCaption struct {
-	Paragraphs []string `xml:"p"`
+	Paragraphs []string `xml:"p" json:"paragraphs"`
 }
 
 type PMCGraphic struct {
-	Href string `xml:"xlink:href,attr"`
+	Href string `xml:"xlink:href,attr" json:"href"`
 }
 
 type PMCBack struct {
-	Acknowledgments *PMCAcknowledgments `xml:"ack,omitempty"`
-	References      *PMCReferences      `xml:"ref-list,omitempty"`
-	FnGroup         *PMCFnGroup         `xml:"fn-group,omitempty"`
+	Acknowledgments *PMCAcknowledgments `xml:"ack,omitempty" json:"acknowledgments"`
+	References      *PMCReferences      `xml:"ref-list,omitempty" json:"references"`
+	FnGroup         *PMCFnGroup         `xml:"fn-group,omitempty" json:"fn_group"`
 }
 
 type PMCAcknowledgments struct {
-	Paragraphs []string `xml:"p"`
+	Paragraphs []string `xml:"p" json:"paragraphs"`
 }
 
 type PMCReferences struct {
-	References []PMCReference `xml:"ref"`
+	References []PMCReference `xml:"ref" json:"references"`
 }
 
 type PMCReference struct {
-	ID              string              `xml:"id,attr"`
-	ElementCitation *PMCElementCitation `xml:"element-citation"`
-	MixedCitation   *PMCMixedCitation   `xml:"mixed-citation"`
+	ID              string              `xml:"id,attr" json:"id" schema:"required"`
+	ElementCitation *PMCElementCitation `xml:"element-citation" json:"element_citation"`
+	MixedCitation   *PMCMixedCitation   `xml:"mixed-citation" json:"mixed_citation"`
 }
 type PMCMixedCitation struct {
-	PublicationType string `xml:"publication-type,attr"`
-	ArticleTitle    string `xml:"article-title"`
-	Source          string `xml:"source"`
-	Year            string `xml:"year"`
-	Volume          string `xml:"volume"`
-	FPage           string `xml:"fpage"`
-	LPage           string `xml:"lpage"`
-	PubID           string `xml:"pub-id"`
+	PublicationType string `xml:"publication-type,attr" json:"publication_type"`
+	ArticleTitle    string `xml:"article-title" json:"article_title"`
+	Source          string `xml:"source" json:"source"`
+	Year            string `xml:"year" json:"year"`
+	Volume          string `xml:"volume" json:"volume"`
+	FPage           string `xml:"fpage" json:"fpage"`
+	LPage           string `xml:"lpage" json:"lpage"`
+	PubID           string `xml:"pub-id" json:"pub_id"`
 }
 
 type PMCFnGroup struct {
-	Footnotes []PMCFootnote `xml:"fn"`
+	Footnotes []PMCFootnote `xml:"fn" json:"footnotes"`
 }
 
 type PMCFootnote struct {
-	Type string   `xml:"fn-type,attr"`
-	Text []string `xml:"p"`
+	Type string   `xml:"fn-type,attr" json:"type"`
+	Text []string `xml:"p" json:"text"`
 }
@@ -0,0 +1,362 @@
+package xmlTools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Projection selects the article fields written by --fields and --exclude.
+// Paths are dotted JSON keys relative to one article, e.g.
+// "medline_citation.article.article_title" or "front.article_meta.title_group";
+// the legacy Go field names ("MedlineCitation.Article.ArticleTitle") are accepted too.
+// A nil *Projection keeps every field.
+type Projection struct {
+	fields  [][]string // Kept paths; nil keeps everything not excluded
+	exclude [][]string // Dropped paths, applied inside the kept ones
+}
+
+// projectionRoots are the article types a mode's paths are checked against.
+var projectionRoots = map[string][]reflect.Type{
+	"pubmed": {reflect.TypeOf(PubmedArticle{}), reflect.TypeOf(PubmedBookArticle{})},
+	"pmc":    {reflect.TypeOf(PMCArticle{})},
+}
+
+//
+// ------------------------ NewProjection ------------------------
+//
+
+/*
+NewProjection validates field paths and builds a Projection.
+
+Parameters:
+  - mode: "pubmed" (paths into PubmedArticle or PubmedBookArticle) or "pmc" (paths into PMCArticle).
+  - fields: Paths to keep; empty keeps all fields.
+  - exclude: Paths to drop.
+
+Returns:
+  - nil when both lists are empty.
+  - An error naming the first path that does not match a struct field, with the
+    fields available at that point.
+*/
+func NewProjection(mode string, fields, exclude []string) (*Projection, error) {
+	roots, ok := projectionRoots[mode]
+	if !ok {
+		return nil, fmt.Errorf("field projection is not supported for %q", mode)
+	}
+	if len(fields) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+
+	p := &Projection{}
+	for _, list := range []struct {
+		flag  string
+		paths []string
+		out   *[][]string
+	}{{"--fields", fields, &p.fields}, {"--exclude", exclude, &p.exclude}} {
+		for _, path := range list.paths {
+			segments, err := checkPath(roots, strings.Split(strings.TrimSpace(path), "."))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s path %q: %w", list.flag, path, err)
+			}
+			*list.out = append(*list.out, segments)
+		}
+	}
+	return p, nil
+}
+
+// checkPath resolves segments to JSON keys in the first root that has the field
+// chain, or reports why none does.
+func checkPath(roots []reflect.Type, segments []string) ([]string, error) {
+	var firstErr error
+	for _, root := range roots {
+		path, err := checkSegments(root, segments, nil)
+		if err == nil {
+			return path, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// checkSegments matches each segment against the JSON key or legacy name of a
+// field and returns the JSON keys.
+func checkSegments(t reflect.Type, segments, parent []string) ([]string, error) {
+	if len(segments) == 0 {
+		return parent, nil
+	}
+	t = elemType(t)
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s has no fields", strings.Join(parent, "."))
+	}
+
+	var available []string
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := jsonName(t.Field(i))
+		if !ok {
+			continue
+		}
+		if name == segments[0] || legacyName(t.Field(i)) == segments[0] {
+			return checkSegments(t.Field(i).Type, segments[1:], append(parent, name))
+		}
+		available = append(available, name)
+	}
+	sort.Strings(available)
+
+	where := "the article"
+	if len(parent) > 0 {
+		where = strings.Join(parent, ".")
+	}
+	return nil, fmt.Errorf("unknown field %q in %s (expected one of: %s)", segments[0], where, strings.Join(available, ", "))
+}
+
+// elemType strips pointers and slices down to the element type.
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
+
+// jsonName returns the JSON key of an exported struct field; ok is false for
+// unexported fields and fields tagged json:"-".
+func jsonName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, true
+	}
+	return f.Name, true
+}
+
+// String lists the resolved JSON key paths, e.g. "fields=medline_citation.pmid exclude=",
+// so that equal projections compare equal; "" for a nil projection.
+func (p *Projection) String() string {
+	if p == nil {
+		return ""
+	}
+	join := func(paths [][]string) string {
+		var dotted []string
+		for _, path := range paths {
+			dotted = append(dotted, strings.Join(path, "."))
+		}
+		return strings.Join(dotted, ",")
+	}
+	return "fields=" + join(p.fields) + " exclude=" + join(p.exclude)
+}
+
+//
+// ------------------------ Apply ------------------------
+//
+
+/*
+Apply zeroes the fields of every article in data that the projection drops, so
+formats built from the structs (parquet, tsv, sqlite, text, ...) only see the
+selected fields.
+
+Parameters:
+  - data: *PubmedArticleSet, *PubmedBookArticleSet or *PMCArticle.
+*/
+func (p *Projection) Apply(data interface{}) {
+	if p == nil {
+		return
+	}
+	switch v := data.(type) {
+	case *PubmedArticleSet:
+		p.zero(reflect.ValueOf(v.PubmedArticles), p.fields, p.fields == nil, p.exclude)
+	case *PubmedBookArticleSet:
+		p.zero(reflect.ValueOf(v.PubmedBookArticles), p.fields, p.fields == nil, p.exclude)
+	case *PMCArticle:
+		p.zero(reflect.ValueOf(v).Elem(), p.fields, p.fields == nil, p.exclude)
+	}
+}
+
+func (p *Projection) zero(v reflect.Value, keep [][]string, keepAll bool, exclude [][]string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			p.zero(v.Elem(), keep, keepAll, exclude)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			p.zero(v.Index(i), keep, keepAll, exclude)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			name, ok := jsonName(v.Type().Field(i))
+			if !ok {
+				continue
+			}
+			childKeep, childAll, childExclude, drop := descend(name, keep, keepAll, exclude)
+			if drop {
+				v.Field(i).Set(reflect.Zero(v.Field(i).Type()))
+				continue
+			}
+			p.zero(v.Field(i), childKeep, childAll, childExclude)
+		}
+	}
+}
+
+// descend returns the paths that apply below key, or drop if key is excluded
+// or not on any kept path.
+func descend(key string, keep [][]string, keepAll bool, exclude [][]string) (childKeep [][]string, childAll bool, childExclude [][]string, drop bool) {
+	for _, path := range exclude {
+		if path[0] != key {
+			continue
+		}
+		if len(path) == 1 {
+			return nil, false, nil, true
+		}
+		childExclude = append(childExclude, path[1:])
+	}
+
+	childAll = keepAll
+	if !keepAll {
+		matched := false
+		for _, path := range keep {
+			if path[0] != key {
+				continue
+			}
+			matched = true
+			if len(path) == 1 {
+				childAll = true
+			} else {
+				childKeep = append(childKeep, path[1:])
+			}
+		}
+		if !matched {
+			return nil, false, nil, true
+		}
+	}
+	if childAll {
+		childKeep = nil
+	}
+	return childKeep, childAll, childExclude, false
+}
+
+//
+// ------------------------ JSON ------------------------
+//
+
+// Projected wraps a value so that it marshals to JSON with only the selected
+// fields. Key order follows the unprojected output.
+func (p *Projection) Projected(v interface{}) json.Marshaler {
+	return projected{p: p, v: v}
+}
+
+type projected struct {
+	p *Projection
+	v interface{}
+}
+
+// MarshalJSON marshals the value and prunes it. Article sets keep their
+// set-level keys (e.g. "source_format") and project each article; articles
+// written as their own document keep their "source_format" too.
+func (pv projected) MarshalJSON() ([]byte, error) {
+	raw, err := json.Marshal(pv.v)
+	if err != nil || pv.p == nil {
+		return raw, err
+	}
+
+	keep, exclude := pv.p.fields, pv.p.exclude
+	switch pv.v.(type) {
+	case *PubmedArticleSet, *PubmedBookArticleSet:
+		var setKeep, setExclude [][]string
+		if keep != nil {
+			setKeep = [][]string{{"source_format"}}
+		}
+		for _, articles := range []string{"pubmed_articles", "pubmed_book_articles"} {
+			for _, path := range keep {
+				setKeep = append(setKeep, append([]string{articles}, path...))
+			}
+			for _, path := range exclude {
+				setExclude = append(setExclude, append([]string{articles}, path...))
+			}
+		}
+		keep, exclude = setKeep, setExclude
+	case *PubmedArticle, *PubmedBookArticle:
+		if keep != nil {
+			keep = append([][]string{{"source_format"}}, keep...)
+		}
+	}
+	return pruneJSON(raw, keep, keep == nil, exclude)
+}
+
+// pruneJSON removes the keys of raw that descend drops, preserving key order.
+func pruneJSON(raw json.RawMessage, keep [][]string, keepAll bool, exclude [][]string) (json.RawMessage, error) {
+	raw = bytes.TrimSpace(raw)
+	if keepAll && len(exclude) == 0 || len(raw) == 0 {
+		return raw, nil
+	}
+
+	switch raw[0] {
+	case '{':
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := tok.(string)
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return nil, err
+			}
+
+			childKeep, childAll, childExclude, drop := descend(key, keep, keepAll, exclude)
+			if drop {
+				continue
+			}
+			if value, err = pruneJSON(value, childKeep, childAll, childExclude); err != nil {
+				return nil, err
+			}
+			if buf.Len() > 1 {
+				buf.WriteByte(',')
+			}
+			name, _ := json.Marshal(key)
+			buf.Write(name)
+			buf.WriteByte(':')
+			buf.Write(value)
+		}
+		buf.WriteByte('}')
+		return buf.Bytes(), nil
+
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, item := range items {
+			item, err := pruneJSON(item, keep, keepAll, exclude)
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(item)
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), nil
+
+	default:
+		return raw, nil
+	}
+}
@@ -0,0 +1,96 @@
+package xmlTools_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Test: NewProjection ------------------------
+//
+
+// TestNewProjection verifies that paths are checked against the article structs of each mode.
+func TestNewProjection(t *testing.T) {
+	tests := []struct {
+		name    string   // Descriptive name for subtest
+		mode    string   // "pubmed" or "pmc"
+		fields  []string // Paths to keep
+		wantErr string   // Expected error substring, "" for success
+	}{
+		{"pubmed article path", "pubmed", []string{"medline_citation.article.article_title"}, ""},
+		{"pubmed book path", "pubmed", []string{"book_document.pmid"}, ""},
+		{"legacy field names", "pubmed", []string{"MedlineCitation.Article.ArticleTitle", "PubmedData.ReferenceList"}, ""},
+		{"pmc path", "pmc", []string{"front.article_meta.title_group", "registry_ids"}, ""},
+		{"typo", "pubmed", []string{"medline_citation.article.article_titel"}, `unknown field "article_titel" in medline_citation.article`},
+		{"wrong mode", "pmc", []string{"medline_citation.pmid"}, `unknown field "medline_citation" in the article`},
+		{"through a string", "pubmed", []string{"medline_citation.pmid.value"}, "medline_citation.pmid has no fields"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := xmlTools.NewProjection(test.mode, test.fields, nil)
+			if test.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+
+	if p, err := xmlTools.NewProjection("pubmed", nil, nil); p != nil || err != nil {
+		t.Errorf("expected nil projection without paths, got %v, %v", p, err)
+	}
+}
+
+//
+// ------------------------ Test: Projection ------------------------
+//
+
+// TestProjection_ApplyAndJSON verifies that dropped fields are zeroed in the structs
+// and absent from the JSON, with set-level keys kept.
+func TestProjection_ApplyAndJSON(t *testing.T) {
+	set := &xmlTools.PubmedArticleSet{
+		PubmedArticles: []xmlTools.PubmedArticle{{}},
+		SourceFormat:   &xmlTools.SourceFormat{Family: "pubmed"},
+	}
+	citation := &set.PubmedArticles[0].MedlineCitation
+	citation.PMID = "1"
+	citation.Article.ArticleTitle = "Title"
+	citation.Article.Abstract.AbstractText = xmlTools.AbstractTexts{{Text: "Abstract"}}
+	citation.Article.Journal.Title = "Journal"
+
+	p, err := xmlTools.NewProjection("pubmed",
+		[]string{"medline_citation.pmid", "medline_citation.article"},
+		[]string{"medline_citation.article.abstract"})
+	if err != nil {
+		t.Fatalf("NewProjection failed: %v", err)
+	}
+
+	content, err := json.Marshal(p.Projected(set))
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	got := string(content)
+	for _, want := range []string{`"pmid":"1"`, `"article_title":"Title"`, `"journal":{`, `"source_format":{`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected JSON to contain %s, got %s", want, got)
+		}
+	}
+	for _, notWant := range []string{`"abstract"`, `"pubmed_data"`, `"date_completed"`} {
+		if strings.Contains(got, notWant) {
+			t.Errorf("expected JSON not to contain %s, got %s", notWant, got)
+		}
+	}
+	if strings.Index(got, `"pmid"`) > strings.Index(got, `"article"`) {
+		t.Errorf("expected struct key order to be kept, got %s", got)
+	}
+
+	p.Apply(set)
+	if citation.PMID != "1" || citation.Article.ArticleTitle != "Title" || citation.Article.Abstract.AbstractText != nil {
+		t.Errorf("unexpected projected struct: %+v", citation)
+	}
+}
@@ -1,278 +1,378 @@
 package xmlTools
 
-import "encoding/xml"
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
 
 // PubmedArticleSet is the root of a regular PubMed XML file.
 // It contains a list of PubmedArticle elements.
 type PubmedArticleSet struct {
-	PubmedArticles []PubmedArticle `xml:"PubmedArticle" json:"PubmedArticles"`
+	PubmedArticles []PubmedArticle `xml:"PubmedArticle" json:"pubmed_articles"`
+	SourceFormat   *SourceFormat   `xml:"-" json:"source_format,omitempty" legacy:"source_format"`
 }
 
 // PubmedBookArticleSet is the root for PubMed Book XML files.
 type PubmedBookArticleSet struct {
-	PubmedBookArticles []PubmedBookArticle `xml:"PubmedBookArticle" json:"PubmedBookArticles"`
+	PubmedBookArticles []PubmedBookArticle `xml:"PubmedBookArticle" json:"pubmed_book_articles"`
+	SourceFormat       *SourceFormat       `xml:"-" json:"source_format,omitempty" legacy:"source_format"`
 }
 
 // PubmedArticle represents one article in the PubMed XML.
 // It includes citation details and PubMed-specific metadata.
 // Unknown captures unmapped tags.
 type PubmedArticle struct {
-	MedlineCitation MedlineCitation  `xml:"MedlineCitation"`
-	PubmedData      PubmedData       `xml:"PubmedData"`
-	Unknown         []UnknownElement `xml:",any"`
+	MedlineCitation MedlineCitation  `xml:"MedlineCitation" json:"medline_citation" schema:"required"`
+	PubmedData      PubmedData       `xml:"PubmedData" json:"pubmed_data"`
+	Unknown         []UnknownElement `xml:",any" json:"unknown"`
+	RegistryIDs     []RegistryID     `xml:"-" json:"registry_ids"`
+	SourceFormat    *SourceFormat    `xml:"-" json:"source_format,omitempty" legacy:"source_format"` // Set on articles written as their own document
 }
 
 // PubmedBookArticle represents one book chapter or article.
 type PubmedBookArticle struct {
-	BookDocument   BookDocument   `xml:"BookDocument"`
-	PubmedBookData PubmedBookData `xml:"PubmedBookData"`
+	BookDocument   BookDocument   `xml:"BookDocument" json:"book_document" schema:"required"`
+	PubmedBookData PubmedBookData `xml:"PubmedBookData" json:"pubmed_book_data" schema:"required"`
+	RegistryIDs    []RegistryID   `xml:"-" json:"registry_ids"`
+	SourceFormat   *SourceFormat  `xml:"-" json:"source_format,omitempty" legacy:"source_format"` // Set on articles written as their own document
 }
 
 // BookDocument holds metadata about a book section or article.
 type BookDocument struct {
-	PMID             string        `xml:"PMID"`
-	ArticleIdList    ArticleIdList `xml:"ArticleIdList"`
-	Book             BookInfo      `xml:"Book"`
-	ArticleTitle     string        `xml:"ArticleTitle"`
-	Abstract         Abstract      `xml:"Abstract"`
-	KeywordList      []Keyword     `xml:"KeywordList>Keyword" json:"KeywordList"`
-	GrantList        GrantList     `xml:"GrantList"`
-	ReferenceList    []Reference   `xml:"ReferenceList>Reference" json:"ReferenceList"`
-	PublicationType  string        `xml:"PublicationType"`
-	InvestigatorList string        `xml:"InvestigatorList"`
-	ContributionDate string        `xml:"ContributionDate"`
-	DateRevised      string        `xml:"DateRevised"`
-	ItemList         ItemList      `xml:"ItemList"`
-	LocationLabel    string        `xml:"LocationLabel"`
+	PMID             string        `xml:"PMID" json:"pmid" schema:"required"`
+	ArticleIdList    ArticleIdList `xml:"ArticleIdList" json:"article_id_list"`
+	Book             BookInfo      `xml:"Book" json:"book"`
+	ArticleTitle     string        `xml:"ArticleTitle" json:"article_title"`
+	Abstract         Abstract      `xml:"Abstract" json:"abstract"`
+	KeywordList      []Keyword     `xml:"KeywordList>Keyword" json:"keyword_list"`
+	GrantList        GrantList     `xml:"GrantList" json:"grant_list"`
+	ReferenceList    []Reference   `xml:"ReferenceList>Reference" json:"reference_list"`
+	PublicationType  string        `xml:"PublicationType" json:"publication_type"`
+	InvestigatorList string        `xml:"InvestigatorList" json:"investigator_list"`
+	ContributionDate string        `xml:"ContributionDate" json:"contribution_date"`
+	DateRevised      string        `xml:"DateRevised" json:"date_revised"`
+	ItemList         ItemList      `xml:"ItemList" json:"item_list"`
+	LocationLabel    string        `xml:"LocationLabel" json:"location_label"`
 }
 
 type BookInfo struct {
 	Publisher struct {
-		PublisherName     string `xml:"PublisherName"`
-		PublisherLocation string `xml:"PublisherLocation"`
-	} `xml:"Publisher"`
-	BookTitle string `xml:"BookTitle"`
+		PublisherName     string `xml:"PublisherName" json:"publisher_name"`
+		PublisherLocation string `xml:"PublisherLocation" json:"publisher_location"`
+	} `xml:"Publisher" json:"publisher"`
+	BookTitle string `xml:"BookTitle" json:"book_title"`
 	PubDate   struct {
-		Year  string `xml:"Year"`
-		Month string `xml:"Month"`
-	} `xml:"PubDate"`
+		Year  string `xml:"Year" json:"year"`
+		Month string `xml:"Month" json:"month"`
+	} `xml:"PubDate" json:"pub_date"`
 	BeginningDate struct {
-		Year  string `xml:"Year"`
-		Month string `xml:"Month"`
-	} `xml:"BeginningDate"`
-	Medium string `xml:"Medium"`
+		Year  string `xml:"Year" json:"year"`
+		Month string `xml:"Month" json:"month"`
+	} `xml:"BeginningDate" json:"beginning_date"`
+	Medium string `xml:"Medium" json:"medium"`
 }
 
 // MedlineCitation holds the main bibliographic content.
 type MedlineCitation struct {
-	PMID                    string                     `xml:"PMID"`
-	DateCompleted           PubMedPubDate              `xml:"DateCompleted"`
-	DateRevised             PubMedPubDate              `xml:"DateRevised"`
-	Article                 Article                    `xml:"Article"`
-	MedlineJournalInfo      Journal                    `xml:"MedlineJournalInfo"`
-	ChemicalList            []Chemical                 `xml:"ChemicalList>Chemical"`
-	SupplMeshList           SupplMeshList              `xml:"SupplMeshList"`
-	CitationSubset          string                     `xml:"CitationSubset"`
-	CommentsCorrectionsList []CommentsCorrectionsEntry `xml:"CommentsCorrectionsList>CommentsCorrections"`
-	GeneSymbolList          GeneSymbolList             `xml:"GeneSymbolList"`
-	MeshHeadingList         MeshHeadingList            `xml:"MeshHeadingList"`
-	NumberOfReferences      string                     `xml:"NumberOfReferences"`
-	PersonalNameSubjectList string                     `xml:"PersonalNameSubjectList"`
-	OtherID                 string                     `xml:"OtherID"`
-	OtherAbstract           Abstract                   `xml:"OtherAbstract"`
-	KeywordList             []string                   `xml:"KeywordList>Keyword" json:"KeywordList"`
-	CoiStatement            string                     `xml:"CoiStatement"`
-	SpaceFlightMission      string                     `xml:"SpaceFlightMission"`
-	InvestigatorList        string                     `xml:"InvestigatorList"`
-	GeneralNote             string                     `xml:"GeneralNote"`
-	Owner                   string                     `xml:"Owner,attr"`
-	Status                  string                     `xml:"Status,attr"`
-	Medline                 string                     `xml:"MEDLINE,attr"`
-	VersionID               string                     `xml:"VersionID,attr"`
-	VersionDate             string                     `xml:"VersionDate,attr"`
-	IndexingMethod          string                     `xml:"IndexingMethod,attr"`
+	PMID                    string                     `xml:"PMID" json:"pmid" schema:"required"`
+	DateCompleted           PubMedPubDate              `xml:"DateCompleted" json:"date_completed"`
+	DateRevised             PubMedPubDate              `xml:"DateRevised" json:"date_revised"`
+	Article                 Article                    `xml:"Article" json:"article" schema:"required"`
+	MedlineJournalInfo      Journal                    `xml:"MedlineJournalInfo" json:"medline_journal_info"`
+	ChemicalList            []Chemical                 `xml:"ChemicalList>Chemical" json:"chemical_list"`
+	SupplMeshList           SupplMeshList              `xml:"SupplMeshList" json:"suppl_mesh_list"`
+	CitationSubset          string                     `xml:"CitationSubset" json:"citation_subset"`
+	CommentsCorrectionsList []CommentsCorrectionsEntry `xml:"CommentsCorrectionsList>CommentsCorrections" json:"comments_corrections_list"`
+	GeneSymbolList          GeneSymbolList             `xml:"GeneSymbolList" json:"gene_symbol_list"`
+	MeshHeadingList         MeshHeadingList            `xml:"MeshHeadingList" json:"mesh_heading_list"`
+	NumberOfReferences      string                     `xml:"NumberOfReferences" json:"number_of_references"`
+	PersonalNameSubjectList string                     `xml:"PersonalNameSubjectList" json:"personal_name_subject_list"`
+	OtherID                 string                     `xml:"OtherID" json:"other_id"`
+	OtherAbstract           Abstract                   `xml:"OtherAbstract" json:"other_abstract"`
+	KeywordList             []string                   `xml:"KeywordList>Keyword" json:"keyword_list"`
+	CoiStatement            string                     `xml:"CoiStatement" json:"coi_statement"`
+	SpaceFlightMission      string                     `xml:"SpaceFlightMission" json:"space_flight_mission"`
+	InvestigatorList        string                     `xml:"InvestigatorList" json:"investigator_list"`
+	GeneralNote             string                     `xml:"GeneralNote" json:"general_note"`
+	Owner                   string                     `xml:"Owner,attr" json:"owner"`
+	Status                  string                     `xml:"Status,attr" json:"status"`
+	Medline                 string                     `xml:"MEDLINE,attr" json:"medline"`
+	VersionID               string                     `xml:"VersionID,attr" json:"version_id"`
+	VersionDate             string                     `xml:"VersionDate,attr" json:"version_date"`
+	IndexingMethod          string                     `xml:"IndexingMethod,attr" json:"indexing_method"`
+}
+
+// CitationVersion parses a MedlineCitation VersionID; absent or non-numeric
+// versions count as 1.
+func CitationVersion(versionID string) int {
+	if n, err := strconv.Atoi(strings.TrimSpace(versionID)); err == nil {
+		return n
+	}
+	return 1
 }
 
 // CommentsCorrectionsEntry represents a correction, retraction, or related citation.
 type CommentsCorrectionsEntry struct {
-	RefType   string `xml:"RefType,attr"`
-	RefSource string `xml:"RefSource"`
-	PMID      string `xml:"PMID"`
+	RefType   string `xml:"RefType,attr" json:"ref_type"`
+	RefSource string `xml:"RefSource" json:"ref_source"`
+	PMID      string `xml:"PMID" json:"pmid"`
 }
 
 // PubmedBookData contains metadata for books like IDs and objects.
 type PubmedBookData struct {
-	History           []PubmedPubDate `xml:"History>PubMedPubDate"`
-	PublicationStatus string          `xml:"PublicationStatus"`
-	ArticleIdList     ArticleIdList   `xml:"ArticleIdList"`
-	ObjectList        []Object        `xml:"ObjectList>Object"`
+	History           []PubmedPubDate `xml:"History>PubMedPubDate" json:"history"`
+	PublicationStatus string          `xml:"PublicationStatus" json:"publication_status" schema:"required"`
+	ArticleIdList     ArticleIdList   `xml:"ArticleIdList" json:"article_id_list"`
+	ObjectList        []Object        `xml:"ObjectList>Object" json:"object_list"`
 }
 
 // PubmedData contains reference lists and metadata.
 type PubmedData struct {
-	History           []PubmedPubDate `xml:"History>PubMedPubDate"`
-	PublicationStatus string          `xml:"PublicationStatus"`
-	ArticleIdList     ArticleIdList   `xml:"ArticleIdList"`
-	ObjectList        []Object        `xml:"ObjectList>Object"`
-	ReferenceList     []Reference     `xml:"ReferenceList>Reference" json:"ReferenceList"`
+	History           []PubmedPubDate `xml:"History>PubMedPubDate" json:"history"`
+	PublicationStatus string          `xml:"PublicationStatus" json:"publication_status" schema:"required"`
+	ArticleIdList     ArticleIdList   `xml:"ArticleIdList" json:"article_id_list"`
+	ObjectList        []Object        `xml:"ObjectList>Object" json:"object_list"`
+	ReferenceList     []Reference     `xml:"ReferenceList>Reference" json:"reference_list"`
 }
 
 type PubmedPubDate struct {
-	PubStatus string `xml:"PubStatus,attr"`
-	Year      string `xml:"Year"`
-	Month     string `xml:"Month"`
-	Day       string `xml:"Day"`
+	PubStatus string `xml:"PubStatus,attr" json:"pub_status"`
+	Year      string `xml:"Year" json:"year"`
+	Month     string `xml:"Month" json:"month"`
+	Day       string `xml:"Day" json:"day"`
 }
 
-// Abstract represents the article’s abstract.
+// Abstract represents the article’s abstract. A structured abstract has one
+// AbstractText per section (BACKGROUND, METHODS, ...), in document order.
 type Abstract struct {
-	AbstractText         string `xml:"AbstractText"`
-	CopyrightInformation string `xml:"CopyrightInformation"`
+	AbstractText         AbstractTexts `xml:"AbstractText" json:"abstract_text"`
+	CopyrightInformation string        `xml:"CopyrightInformation" json:"copyright_information"`
+}
+
+// AbstractText is one section of an abstract. Label is the heading printed by the
+// publisher and NlmCategory its NLM-assigned category (e.g. "METHODS").
+type AbstractText struct {
+	Label       string `xml:"Label,attr" json:"label"`
+	NlmCategory string `xml:"NlmCategory,attr" json:"nlm_category"`
+	Text        string `xml:",chardata" json:"text"`
+}
+
+// AbstractTexts holds the sections of an abstract. Documents written before schema
+// 3.0 stored the abstract as a single string, which decodes as one unlabeled section.
+type AbstractTexts []AbstractText
+
+// UnmarshalJSON accepts both the section list and the pre-3.0 string.
+func (a *AbstractTexts) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*a = nil
+		if text != "" {
+			*a = AbstractTexts{{Text: text}}
+		}
+		return nil
+	}
+	var sections []AbstractText
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return err
+	}
+	*a = sections
+	return nil
 }
 
 // AffiliationInfo holds author affiliation metadata.
 type AffiliationInfo struct {
-	Affiliation string `xml:"Affiliation"`
-	Identifier  string `xml:"Identifier"`
+	Affiliation string `xml:"Affiliation" json:"affiliation"`
+	Identifier  string `xml:"Identifier" json:"identifier"`
 }
 
 // ArticleId represents one identifier (DOI, PMID, etc.).
 type ArticleId struct {
-	ID     string `xml:",chardata"`
-	IdType string `xml:"IdType,attr"`
+	ID     string `xml:",chardata" json:"id"`
+	IdType string `xml:"IdType,attr" json:"id_type"`
 }
 
 // ArticleIdList is a wrapper for multiple ArticleIds.
 type ArticleIdList struct {
-	ArticleIds []ArticleId `xml:"ArticleId"`
+	ArticleIds []ArticleId `xml:"ArticleId" json:"article_ids"`
 }
 
 type Day struct{} // Placeholder if day-specific parsing is needed
 
 // GeneSymbolList represents a list of gene symbols.
 type GeneSymbolList struct {
-	GeneSymbols []string `xml:"GeneSymbol"`
+	GeneSymbols []string `xml:"GeneSymbol" json:"gene_symbols"`
 }
 
 // Grant holds one funding entry.
 type Grant struct {
-	GrantID string `xml:"GrantID"`
-	Acronym string `xml:"Acronym"`
-	Agency  string `xml:"Agency"`
-	Country string `xml:"Country"`
+	GrantID string `xml:"GrantID" json:"grant_id"`
+	Acronym string `xml:"Acronym" json:"acronym"`
+	Agency  string `xml:"Agency" json:"agency"`
+	Country string `xml:"Country" json:"country"`
 }
 
 // GrantList contains multiple grants.
 type GrantList struct {
-	Grants     []Grant `xml:"Grant"`
-	CompleteYN string  `xml:"CompleteYN,attr"`
+	Grants     []Grant `xml:"Grant" json:"grants"`
+	CompleteYN string  `xml:"CompleteYN,attr" json:"complete_yn"`
 }
 
 // UnknownElement captures unparsed or unexpected XML.
 type UnknownElement struct {
-	XMLName xml.Name
-	Content string `xml:",innerxml"`
+	XMLName xml.Name `json:"-"`
+	Content string   `xml:",innerxml" json:"content"`
 }
 
 // ItemList represents a named list of items.
 type ItemList struct {
-	Items    []string `xml:"Item"`
-	ListType string   `xml:"ListType,attr"`
+	Items    []string `xml:"Item" json:"items"`
+	ListType string   `xml:"ListType,attr" json:"list_type"`
 }
 
 // Journal holds metadata about the journal.
 type Journal struct {
-	ISSN            string `xml:"ISSN"`
-	JournalIssue    string `xml:"JournalIssue"`
-	Title           string `xml:"Title"`
-	ISOAbbreviation string `xml:"ISOAbbreviation"`
+	ISSN            string       `xml:"ISSN" json:"issn"`
+	JournalIssue    JournalIssue `xml:"JournalIssue" json:"journal_issue"`
+	Title           string       `xml:"Title" json:"title"`
+	ISOAbbreviation string       `xml:"ISOAbbreviation" json:"iso_abbreviation"`
+}
+
+// JournalIssue identifies the issue an article appeared in.
+type JournalIssue struct {
+	Volume      string         `xml:"Volume" json:"volume"`
+	Issue       string         `xml:"Issue" json:"issue"`
+	PubDate     JournalPubDate `xml:"PubDate" json:"pub_date"`
+	CitedMedium string         `xml:"CitedMedium,attr" json:"cited_medium"`
+}
+
+// JournalPubDate is the issue's publication date. Irregular dates
+// (e.g. "2010 Winter") are given as MedlineDate instead of Year/Month/Day.
+type JournalPubDate struct {
+	Year        string `xml:"Year" json:"year"`
+	Month       string `xml:"Month" json:"month"`
+	Day         string `xml:"Day" json:"day"`
+	Season      string `xml:"Season" json:"season"`
+	MedlineDate string `xml:"MedlineDate" json:"medline_date"`
+}
+
+// Pagination holds the page range of an article (e.g. "100-10").
+type Pagination struct {
+	StartPage  string `xml:"StartPage" json:"start_page"`
+	EndPage    string `xml:"EndPage" json:"end_page"`
+	MedlinePgn string `xml:"MedlinePgn" json:"medline_pgn"`
+}
+
+// ELocationID is an electronic location such as a DOI or publisher item identifier.
+type ELocationID struct {
+	ID      string `xml:",chardata" json:"id"`
+	EIdType string `xml:"EIdType,attr" json:"eid_type"`
+	ValidYN string `xml:"ValidYN,attr" json:"valid_yn"`
 }
 
 // Keyword is a keyword term.
 type Keyword struct {
-	Text string `xml:",chardata"`
+	Text string `xml:",chardata" json:"text"`
 }
 
 // Reference is a cited publication.
 type Reference struct {
-	Citation      string        `xml:"Citation"`
-	ArticleIdList ArticleIdList `xml:"ArticleIdList"`
+	Citation      string        `xml:"Citation" json:"citation"`
+	ArticleIdList ArticleIdList `xml:"ArticleIdList" json:"article_id_list"`
 }
 
 // Chemical holds chemical tag information.
 type Chemical struct {
-	RegistryNumber  string `xml:"RegistryNumber"`
-	NameOfSubstance string `xml:"NameOfSubstance"`
+	RegistryNumber  string `xml:"RegistryNumber" json:"registry_number"`
+	NameOfSubstance string `xml:"NameOfSubstance" json:"name_of_substance"`
 }
 
 // LocationLabel provides structural location (e.g., "Chapter 2").
 type LocationLabel struct {
-	Type string `xml:"Type,attr"`
+	Type string `xml:"Type,attr" json:"type"`
 }
 
 // QualifierName is a MeSH qualifier.
 type QualifierName struct {
-	Text         string `xml:",chardata"`
-	UI           string `xml:"UI,attr"`
-	MajorTopicYN string `xml:"MajorTopicYN,attr"`
+	Text         string `xml:",chardata" json:"text"`
+	UI           string `xml:"UI,attr" json:"ui"`
+	MajorTopicYN string `xml:"MajorTopicYN,attr" json:"major_topic_yn"`
 }
 
 // MeshHeading represents a subject term.
 type MeshHeading struct {
-	DescriptorName string          `xml:"DescriptorName"`
-	Qualifiers     []QualifierName `xml:"QualifierName"`
+	DescriptorName string          `xml:"DescriptorName" json:"descriptor_name"`
+	Qualifiers     []QualifierName `xml:"QualifierName" json:"qualifiers"`
 }
 
 // MeshHeadingList contains all MeSH headings.
 type MeshHeadingList struct {
-	MeshHeadings []MeshHeading `xml:"MeshHeading"`
+	MeshHeadings []MeshHeading `xml:"MeshHeading" json:"mesh_headings"`
 }
 
 // Object holds supplementary identifiers.
 type Object struct {
-	Param string `xml:"Param"`
-	Type  string `xml:"Type,attr"`
+	Param string `xml:"Param" json:"param"`
+	Type  string `xml:"Type,attr" json:"type"`
 }
 
 // PubMedPubDate holds timestamped metadata (e.g., publication or update).
 type PubMedPubDate struct {
-	Year      string `xml:"Year"`
-	Month     string `xml:"Month"`
-	Day       string `xml:"Day"`
-	Hour      string `xml:"Hour"`
-	Minute    string `xml:"Minute"`
-	PubStatus string `xml:"PubStatus,attr"`
+	Year      string `xml:"Year" json:"year"`
+	Month     string `xml:"Month" json:"month"`
+	Day       string `xml:"Day" json:"day"`
+	Hour      string `xml:"Hour" json:"hour"`
+	Minute    string `xml:"Minute" json:"minute"`
+	PubStatus string `xml:"PubStatus,attr" json:"pub_status"`
 }
 
 // SupplMeshList contains supplemental MeSH terms.
 type SupplMeshList struct {
-	SupplMeshNames []string `xml:"SupplMeshName"`
+	SupplMeshNames []string `xml:"SupplMeshName" json:"suppl_mesh_names"`
 }
 
 // Article contains core article metadata.
 type Article struct {
-	Journal             Journal           `xml:"Journal"`
-	ArticleTitle        string            `xml:"ArticleTitle"`
-	Abstract            Abstract          `xml:"Abstract"`
-	AuthorList          []Author          `xml:"AuthorList>Author"`
-	Language            []string          `xml:"Language"`
-	PublicationTypeList []PublicationType `xml:"PublicationTypeList>PublicationType"`
-	ArticleDate         string            `xml:"ArticleDate"`
+	Journal             Journal           `xml:"Journal" json:"journal"`
+	ArticleTitle        string            `xml:"ArticleTitle" json:"article_title"`
+	Pagination          Pagination        `xml:"Pagination" json:"pagination"`
+	ELocationIDs        []ELocationID     `xml:"ELocationID" json:"elocation_ids"`
+	Abstract            Abstract          `xml:"Abstract" json:"abstract"`
+	AuthorList          []Author          `xml:"AuthorList>Author" json:"author_list"`
+	Language            []string          `xml:"Language" json:"language"`
+	PublicationTypeList []PublicationType `xml:"PublicationTypeList>PublicationType" json:"publication_type_list"`
+	ArticleDate         string            `xml:"ArticleDate" json:"article_date"`
+	DataBankList        DataBankList      `xml:"DataBankList" json:"data_bank_list"`
+	GrantList           GrantList         `xml:"GrantList" json:"grant_list"`
+}
+
+// DataBankList links an article to records in external databases
+// (ClinicalTrials.gov, GenBank, GEO, ...).
+type DataBankList struct {
+	DataBanks  []DataBank `xml:"DataBank" json:"data_banks"`
+	CompleteYN string     `xml:"CompleteYN,attr" json:"complete_yn"`
+}
+
+// DataBank names one external database and the accession numbers cited from it.
+type DataBank struct {
+	DataBankName        string   `xml:"DataBankName" json:"data_bank_name"`
+	AccessionNumberList []string `xml:"AccessionNumberList>AccessionNumber" json:"accession_number_list"`
 }
 
 // Author contains contributor metadata.
 type Author struct {
-	LastName        string            `xml:"LastName"`
-	ForeName        string            `xml:"ForeName"`
-	Initials        string            `xml:"Initials"`
-	Suffix          string            `xml:"Suffix"`
-	CollectiveName  string            `xml:"CollectiveName"`
-	AffiliationInfo []AffiliationInfo `xml:"AffiliationInfo"`
-	ValidYN         string            `xml:"ValidYN,attr"`
+	LastName        string            `xml:"LastName" json:"last_name"`
+	ForeName        string            `xml:"ForeName" json:"fore_name"`
+	Initials        string            `xml:"Initials" json:"initials"`
+	Suffix          string            `xml:"Suffix" json:"suffix"`
+	CollectiveName  string            `xml:"CollectiveName" json:"collective_name"`
+	AffiliationInfo []AffiliationInfo `xml:"AffiliationInfo" json:"affiliation_info"`
+	ValidYN         string            `xml:"ValidYN,attr" json:"valid_yn"`
 }
 
 // PublicationType describes the article type (e.g., "Review").
 type PublicationType struct {
-	Text string `xml:",chardata"`
-	UI   string `xml:"UI,attr"`
+	Text string `xml:",chardata" json:"text"`
+	UI   string `xml:"UI,attr" json:"ui"`
 }
@@ -1,7 +1,6 @@
 package xmlTools
 
 import (
-	"encoding/xml"
 	"fmt"
 	"os"
 )
@@ -11,6 +10,18 @@ import (
 /*
 ParsePubmedXML attempts to detect and parse a PubMed or PMC XML file.
 
+It is shorthand for ParsePubmedXMLWithOptions with default (strict) options.
+*/
+func ParsePubmedXML(filePath string) (interface{}, error) {
+	data, _, err := ParsePubmedXMLWithOptions(filePath, DecodeOptions{})
+	return data, err
+}
+
+// ------------------------ ParsePubmedXMLWithOptions ------------------------
+
+/*
+ParsePubmedXMLWithOptions attempts to detect and parse a PubMed or PMC XML file.
+
 It tries parsing the file into one of the following known formats:
   - *PubmedArticleSet
   - *PubmedBookArticleSet
@@ -18,47 +29,91 @@ It tries parsing the file into one of the following known formats:
 
 Parameters:
   - filePath: Path to the XML file on disk.
+  - opts: Decoding options (e.g., lenient recovery for malformed files).
 
 Returns:
   - Parsed result as an interface{} (e.g., *PubmedArticleSet, *PubmedBookArticleSet, or *PMCArticle).
+  - ParseInfo listing the fixups (charset conversion, DTD entities, non-strict mode) that were needed,
+    the declared DTD version and any version warnings.
   - Error if the file cannot be read or parsed as a recognized structure.
 
 Behavior:
   - Reads the XML file into memory.
+  - Detects the declared DTD/JATS version (see DetectSourceFormat) and stores it on the result.
+  - Decodes with legacy charsets and the JATS/ISO entity sets enabled (see newDecoder).
   - Attempts to unmarshal into PubmedArticleSet.
   - If no articles are found, attempts PubmedBookArticleSet.
+  - If a PubMed set fails to decode as a whole, decodes its records one at a time and
+    skips the malformed ones, listing them in ParseInfo.Skipped (see recoverRecords).
   - Then tries PMCArticle.
-  - Returns an error if none of the known formats match.
+  - Attaches clinical trial registry IDs to every parsed article (see ExtractRegistryIDs).
+  - Returns an error if none of the known formats match, wrapping the decoder
+    error so syntax and encoding problems are not hidden.
 */
-func ParsePubmedXML(filePath string) (interface{}, error) {
+func ParsePubmedXMLWithOptions(filePath string, opts DecodeOptions) (interface{}, ParseInfo, error) {
 	xmlBytes, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, ParseInfo{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	source, warnings := DetectSourceFormat(xmlBytes, opts)
+	withSource := func(info ParseInfo) ParseInfo {
+		info.Source = source
+		info.Warnings = warnings
+		return info
 	}
 
 	// Attempt to parse as PubmedArticleSet
 	var articleSet PubmedArticleSet
-	if err := xml.Unmarshal(xmlBytes, &articleSet); err == nil && len(articleSet.PubmedArticles) > 0 {
-		return &articleSet, nil
+	info, setErr := decodeXML(xmlBytes, &articleSet, opts)
+	if setErr == nil && len(articleSet.PubmedArticles) > 0 {
+		AttachRegistryIDs(&articleSet)
+		articleSet.SourceFormat = &source
+		return &articleSet, withSource(info), nil
 	}
 
 	// Attempt to parse as PubmedBookArticleSet
 	var bookSet PubmedBookArticleSet
-	if err := xml.Unmarshal(xmlBytes, &bookSet); err == nil && len(bookSet.PubmedBookArticles) > 0 {
-		return &bookSet, nil
+	info, err = decodeXML(xmlBytes, &bookSet, opts)
+	if err == nil && len(bookSet.PubmedBookArticles) > 0 {
+		AttachRegistryIDs(&bookSet)
+		bookSet.SourceFormat = &source
+		return &bookSet, withSource(info), nil
+	}
+
+	// A malformed record fails the whole set; keep the records that decode on their own
+	if setErr != nil && (source.RootElement == "PubmedArticleSet" || source.RootElement == "PubmedBookArticleSet") {
+		set, info, err := recoverPubmedSet(xmlBytes, source.RootElement, opts)
+		if err != nil {
+			return nil, withSource(info), fmt.Errorf("failed to decode <%s>: %w (record recovery: %v)", source.RootElement, setErr, err)
+		}
+		AttachRegistryIDs(set)
+		switch v := set.(type) {
+		case *PubmedArticleSet:
+			v.SourceFormat = &source
+		case *PubmedBookArticleSet:
+			v.SourceFormat = &source
+		}
+		return set, withSource(info), nil
 	}
 
 	// Attempt to parse as PMCArticle
 	var pmc PMCArticle
-	if err := xml.Unmarshal(xmlBytes, &pmc); err != nil {
-		fmt.Println("PMC unmarshal error:", err)
-	} else if pmc.XMLName.Local != "article" {
-		fmt.Println("PMC parsed, but root element was:", pmc.XMLName.Local)
-	} else {
-		return &pmc, nil
+	info, err = decodeXML(xmlBytes, &pmc, opts)
+	switch {
+	case err != nil:
+		// The PubMed attempt saw the same bytes; a decode error there is the real cause
+		if setErr != nil {
+			err = setErr
+		}
+		return nil, withSource(info), fmt.Errorf("unrecognized PubMed or PMC XML structure: %w", err)
+	case pmc.XMLName.Local != "article":
+		return nil, withSource(info), fmt.Errorf("unrecognized PubMed or PMC XML structure: root element was <%s>", pmc.XMLName.Local)
 	}
 
-	return nil, fmt.Errorf("unrecognized PubMed or PMC XML structure")
+	pmc.ExtLinks = scanExtLinks(xmlBytes, opts)
+	AttachRegistryIDs(&pmc)
+	pmc.SourceFormat = &source
+	return &pmc, withSource(info), nil
 }
 
 // ------------------------ NormalizePubmedArticleSet ------------------------
@@ -0,0 +1,188 @@
+package xmlTools
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ------------------------ Record recovery ------------------------
+
+// SkippedRecord is an article left out of a multi-article file because it could not be decoded.
+type SkippedRecord struct {
+	Offset int64  // Byte offset of the record's start tag in the file
+	PMID   string // PMID found in the record's raw bytes; "" if none could be recovered
+	Err    error  // Why the record could not be decoded
+}
+
+// String formats the record as "offset <n> PMID <pmid>: <error>".
+func (r SkippedRecord) String() string {
+	pmid := r.PMID
+	if pmid == "" {
+		pmid = "unknown"
+	}
+	return fmt.Sprintf("offset %d PMID %s: %v", r.Offset, pmid, r.Err)
+}
+
+var (
+	// xmlDeclPattern matches the XML declaration, which carries the encoding.
+	xmlDeclPattern = regexp.MustCompile(`^\s*<\?xml[^?]*\?>`)
+
+	// recordPMIDPattern recovers the first PMID from a record's raw bytes.
+	recordPMIDPattern = regexp.MustCompile(`<PMID[^>]*>\s*([0-9]+)\s*</PMID>`)
+)
+
+/*
+recoverRecords decodes the records of a multi-article file one at a time, so that
+a malformed record costs only itself.
+
+Parameters:
+  - xmlBytes: The whole document, which failed to decode as a set.
+  - element: The record element, "PubmedArticle" or "PubmedBookArticle".
+  - decode: Decodes one record, prefixed with the document's XML declaration so
+    that legacy charsets still apply, and keeps it on success.
+
+Returns:
+  - The fixups of the decoded records plus "record-recovery".
+  - The records that failed, in document order.
+  - The number of records found.
+
+Behavior:
+  - Records are delimited in the raw bytes by <element ...> and </element>; a record
+    without its end tag ends where the next one starts. Records do not nest, so a
+    syntax error inside one record does not move the boundaries of the others.
+*/
+func recoverRecords(xmlBytes []byte, element string, decode func(record []byte) (ParseInfo, error)) (ParseInfo, []SkippedRecord, int) {
+	info := ParseInfo{}
+	info.addFixup("record-recovery")
+	prolog := xmlDeclPattern.Find(xmlBytes)
+
+	var skipped []SkippedRecord
+	found := 0
+	for start := nextRecordStart(xmlBytes, element, 0); start >= 0; {
+		end, next := recordEnd(xmlBytes, element, start)
+		found++
+
+		record := append(append([]byte{}, prolog...), xmlBytes[start:end]...)
+		recordInfo, err := decode(record)
+		if err != nil {
+			// Report syntax errors at their line in the file, not in the record
+			var syntax *xml.SyntaxError
+			if errors.As(err, &syntax) {
+				syntax.Line += bytes.Count(xmlBytes[:start], []byte("\n")) - bytes.Count(prolog, []byte("\n"))
+			}
+			skipped = append(skipped, SkippedRecord{Offset: int64(start), PMID: recordPMID(xmlBytes[start:end]), Err: err})
+		}
+		for _, fixup := range recordInfo.Fixups {
+			info.addFixup(fixup)
+		}
+		start = next
+	}
+	return info, skipped, found
+}
+
+// nextRecordStart returns the offset of the next <element> or <element ...> start tag
+// at or after from, or -1.
+func nextRecordStart(xmlBytes []byte, element string, from int) int {
+	open := []byte("<" + element)
+	for from < len(xmlBytes) {
+		i := bytes.Index(xmlBytes[from:], open)
+		if i < 0 {
+			return -1
+		}
+		i += from
+		if after := i + len(open); after < len(xmlBytes) {
+			switch xmlBytes[after] {
+			case '>', ' ', '\t', '\n', '\r', '/':
+				return i
+			}
+		}
+		from = i + len(open) // e.g. <PubmedArticleSet
+	}
+	return -1
+}
+
+// recordEnd returns the end of the record starting at start and the start of the
+// next record (-1 if none).
+func recordEnd(xmlBytes []byte, element string, start int) (end, next int) {
+	next = nextRecordStart(xmlBytes, element, start+1)
+	limit := len(xmlBytes)
+	if next >= 0 {
+		limit = next
+	}
+
+	closing := []byte("</" + element)
+	if i := bytes.Index(xmlBytes[start:limit], closing); i >= 0 {
+		end = start + i + len(closing)
+		if j := bytes.IndexByte(xmlBytes[end:limit], '>'); j >= 0 {
+			return end + j + 1, next
+		}
+	}
+	return limit, next
+}
+
+// recordPMID recovers a record's PMID from its raw bytes.
+func recordPMID(record []byte) string {
+	if m := recordPMIDPattern.FindSubmatch(record); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+/*
+recoverPubmedSet decodes the articles of a PubmedArticleSet or PubmedBookArticleSet
+record by record (see recoverRecords), with opts (including lenient retries)
+applied to every record.
+
+Returns:
+  - The set with every record that decoded, or nil if none did.
+  - The fixups and skipped records.
+  - The error of the first record when no record decoded.
+*/
+func recoverPubmedSet(xmlBytes []byte, root string, opts DecodeOptions) (interface{}, ParseInfo, error) {
+	var set interface{}
+	var info ParseInfo
+	var skipped []SkippedRecord
+	var found int
+
+	switch root {
+	case "PubmedArticleSet":
+		articles := &PubmedArticleSet{}
+		info, skipped, found = recoverRecords(xmlBytes, "PubmedArticle", func(record []byte) (ParseInfo, error) {
+			var article PubmedArticle
+			recordInfo, err := decodeXML(record, &article, opts)
+			if err == nil {
+				articles.PubmedArticles = append(articles.PubmedArticles, article)
+			}
+			return recordInfo, err
+		})
+		if len(articles.PubmedArticles) > 0 {
+			set = articles
+		}
+	case "PubmedBookArticleSet":
+		books := &PubmedBookArticleSet{}
+		info, skipped, found = recoverRecords(xmlBytes, "PubmedBookArticle", func(record []byte) (ParseInfo, error) {
+			var article PubmedBookArticle
+			recordInfo, err := decodeXML(record, &article, opts)
+			if err == nil {
+				books.PubmedBookArticles = append(books.PubmedBookArticles, article)
+			}
+			return recordInfo, err
+		})
+		if len(books.PubmedBookArticles) > 0 {
+			set = books
+		}
+	}
+
+	info.Skipped = skipped
+	switch {
+	case set != nil:
+		return set, info, nil
+	case len(skipped) > 0:
+		return nil, info, fmt.Errorf("none of the %d records could be decoded; first at %s", found, skipped[0])
+	default:
+		return nil, info, fmt.Errorf("no records found")
+	}
+}
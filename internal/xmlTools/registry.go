@@ -0,0 +1,376 @@
+package xmlTools
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RegistryID is one clinical trial or review registration found in an article.
+type RegistryID struct {
+	Registry string `json:"registry"` // "ClinicalTrials.gov", "ISRCTN", "EudraCT" or "PROSPERO"
+	ID       string `json:"id"`       // Normalized identifier, e.g. "NCT01234567"
+	Source   string `json:"source"`   // Part of the record it was found in: "databank", "metadata", "title", "abstract", "body" or "back"
+	Element  string `json:"element"`  // Element it was read from, e.g. "AccessionNumber", "ext-link", "custom-meta" or "text"
+}
+
+// registryPattern matches one registry's identifiers in free text and normalizes them.
+type registryPattern struct {
+	registry  string
+	aliases   []string // Other lowercase names of the registry in DataBankName or ext-link-type
+	re        *regexp.Regexp
+	bare      *regexp.Regexp // The identifier without its prefix, e.g. "12345678" for ISRCTN; nil when it has none
+	normalize func(match []string) string
+}
+
+// named reports whether a DataBankName or ext-link-type names the registry.
+func (p registryPattern) named(name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == strings.ToLower(p.registry) {
+		return true
+	}
+	for _, alias := range p.aliases {
+		if name == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// identify normalizes a value read from an element that names the registry, which
+// may omit the prefix ("12345678" for ISRCTN12345678). It returns "" when the value
+// does not have the registry's shape.
+func (p registryPattern) identify(value string) string {
+	value = strings.TrimSpace(value)
+	if m := p.re.FindStringSubmatch(value); m != nil {
+		return p.normalize(m)
+	}
+	if p.bare != nil {
+		if m := p.bare.FindStringSubmatch(value); m != nil {
+			return p.normalize(m)
+		}
+	}
+	return ""
+}
+
+// registryPatterns lists the registries recognized by ExtractRegistryIDs.
+// Free-text matching tolerates a space or hyphen after the prefix ("NCT 01234567").
+var registryPatterns = []registryPattern{
+	{
+		registry:  "ClinicalTrials.gov",
+		aliases:   []string{"clintrialgov", "clinicaltrials"},
+		re:        regexp.MustCompile(`(?i)\bNCT[\s-]?(\d{8})\b`),
+		bare:      regexp.MustCompile(`^(\d{8})$`),
+		normalize: func(m []string) string { return "NCT" + m[1] },
+	},
+	{
+		registry:  "ISRCTN",
+		re:        regexp.MustCompile(`(?i)\bISRCTN[\s-]?(\d{8})\b`),
+		bare:      regexp.MustCompile(`^(\d{8})$`),
+		normalize: func(m []string) string { return "ISRCTN" + m[1] },
+	},
+	{
+		registry:  "EudraCT",
+		re:        regexp.MustCompile(`\b((?:19|20)\d{2})-(\d{6})-(\d{2})\b`),
+		normalize: func(m []string) string { return m[1] + "-" + m[2] + "-" + m[3] },
+	},
+	{
+		registry:  "PROSPERO",
+		re:        regexp.MustCompile(`(?i)\bCRD[\s-]?(\d{11})\b`),
+		bare:      regexp.MustCompile(`^(\d{11})$`),
+		normalize: func(m []string) string { return "CRD" + m[1] },
+	},
+}
+
+// ------------------------ ExtractRegistryIDs ------------------------
+
+/*
+ExtractRegistryIDs finds trial and review registry identifiers in a parsed article.
+
+Parameters:
+  - article: *PubmedArticle, *PubmedBookArticle or *PMCArticle.
+
+Returns:
+  - A de-duplicated list of normalized identifiers with the place each was found.
+    The list is empty (never nil) when nothing matches or the type is unsupported.
+
+Behavior:
+  - PubMed: reads DataBankList accession numbers, then scans the title and abstracts.
+  - PMC: reads ext-links collected by scanExtLinks (see addLink) and custom-meta values, then scans
+    the title, abstract, body sections and back matter.
+*/
+func ExtractRegistryIDs(article interface{}) []RegistryID {
+	c := &registryCollector{seen: map[RegistryID]bool{}, located: map[RegistryID]bool{}, ids: []RegistryID{}}
+
+	switch v := article.(type) {
+	case *PubmedArticle:
+		for _, bank := range v.MedlineCitation.Article.DataBankList.DataBanks {
+			for _, acc := range bank.AccessionNumberList {
+				c.addAccession(bank.DataBankName, acc)
+			}
+		}
+		c.scan(v.MedlineCitation.Article.ArticleTitle, "title", "text")
+		for _, section := range v.MedlineCitation.Article.Abstract.AbstractText {
+			c.scan(section.Text, "abstract", "text")
+		}
+		for _, section := range v.MedlineCitation.OtherAbstract.AbstractText {
+			c.scan(section.Text, "abstract", "text")
+		}
+
+	case *PubmedBookArticle:
+		c.scan(v.BookDocument.ArticleTitle, "title", "text")
+		for _, section := range v.BookDocument.Abstract.AbstractText {
+			c.scan(section.Text, "abstract", "text")
+		}
+
+	case *PMCArticle:
+		for _, link := range v.ExtLinks {
+			c.addLink(link)
+		}
+		meta := v.Front.ArticleMeta
+		if meta.CustomMetaGroup != nil {
+			for _, cm := range meta.CustomMetaGroup.CustomMeta {
+				c.scan(cm.Value, "metadata", "custom-meta")
+			}
+		}
+		c.scan(meta.TitleGroup.ArticleTitle, "title", "text")
+		if meta.Abstract != nil {
+			for _, p := range meta.Abstract.Paragraphs {
+				c.scan(p, "abstract", "text")
+			}
+			for _, sec := range meta.Abstract.Sec {
+				for _, p := range sec.Paragraphs {
+					c.scan(p, "abstract", "text")
+				}
+			}
+		}
+		if v.Body != nil {
+			c.scanSections(v.Body.Sections)
+		}
+		if v.Back != nil {
+			if v.Back.Acknowledgments != nil {
+				for _, p := range v.Back.Acknowledgments.Paragraphs {
+					c.scan(p, "back", "text")
+				}
+			}
+			if v.Back.FnGroup != nil {
+				for _, fn := range v.Back.FnGroup.Footnotes {
+					for _, p := range fn.Text {
+						c.scan(p, "back", "text")
+					}
+				}
+			}
+		}
+	}
+
+	return c.ids
+}
+
+// AttachRegistryIDs runs ExtractRegistryIDs on every article in a parsed result
+// and stores the findings in each article's RegistryIDs field.
+func AttachRegistryIDs(data interface{}) {
+	switch v := data.(type) {
+	case *PubmedArticleSet:
+		for i := range v.PubmedArticles {
+			v.PubmedArticles[i].RegistryIDs = ExtractRegistryIDs(&v.PubmedArticles[i])
+		}
+	case *PubmedBookArticleSet:
+		for i := range v.PubmedBookArticles {
+			v.PubmedBookArticles[i].RegistryIDs = ExtractRegistryIDs(&v.PubmedBookArticles[i])
+		}
+	case *PMCArticle:
+		v.RegistryIDs = ExtractRegistryIDs(v)
+	}
+}
+
+// registryCollector accumulates registry IDs in discovery order without duplicates.
+type registryCollector struct {
+	seen    map[RegistryID]bool
+	located map[RegistryID]bool // IDs per Source, ignoring Element
+	ids     []RegistryID
+}
+
+// add records id once. Free-text matches are skipped when the same ID was already
+// read from a structured element (ext-link, custom-meta) in the same part of the
+// record, since paragraph text includes the text of its links.
+func (c *registryCollector) add(id RegistryID) {
+	location := RegistryID{Registry: id.Registry, ID: id.ID, Source: id.Source}
+	if c.seen[id] || (id.Element == "text" && c.located[location]) {
+		return
+	}
+	c.seen[id] = true
+	c.located[location] = true
+	c.ids = append(c.ids, id)
+}
+
+// scan records every registry identifier matched in text.
+func (c *registryCollector) scan(text, source, element string) {
+	if text == "" {
+		return
+	}
+	for _, p := range registryPatterns {
+		for _, m := range p.re.FindAllStringSubmatch(text, -1) {
+			c.add(RegistryID{Registry: p.registry, ID: p.normalize(m), Source: source, Element: element})
+		}
+	}
+}
+
+// scanSections walks body sections and their subsections.
+func (c *registryCollector) scanSections(sections []PMCSection) {
+	for _, sec := range sections {
+		c.scan(sec.Title, "body", "text")
+		for _, p := range sec.Paragraphs {
+			c.scan(p, "body", "text")
+		}
+		c.scanSections(sec.SubSections)
+	}
+}
+
+// addAccession records a DataBankList accession number. Numbers from a known
+// registry are normalized with that registry's pattern, adding the prefix to a bare
+// number; other databanks (GenBank, GEO, ...) are only recorded if the number itself
+// looks like a registry ID.
+func (c *registryCollector) addAccession(bankName, accession string) {
+	for _, p := range registryPatterns {
+		if m := p.re.FindStringSubmatch(accession); m != nil {
+			c.add(RegistryID{Registry: p.registry, ID: p.normalize(m), Source: "databank", Element: "AccessionNumber"})
+			return
+		}
+	}
+	// Registry databank: add the missing prefix, or keep an accession that does not
+	// match the expected shape verbatim rather than dropping it.
+	for _, p := range registryPatterns {
+		if p.named(bankName) {
+			id := p.identify(accession)
+			if id == "" {
+				id = strings.TrimSpace(accession)
+			}
+			c.add(RegistryID{Registry: p.registry, ID: id, Source: "databank", Element: "AccessionNumber"})
+			return
+		}
+	}
+}
+
+// addLink records the registry IDs of an ext-link. A link whose ext-link-type names a
+// registry (e.g. "clintrialgov") may omit the prefix, e.g. an ISRCTN link reading
+// "12345678": its text, then the segments and query values of its href, are read as
+// the registry's bare identifier, and the link is dropped when none has the
+// registry's shape. Links of other types are only scanned.
+func (c *registryCollector) addLink(link PMCExtLink) {
+	text := link.Href + " " + link.Text
+	for _, p := range registryPatterns {
+		if p.re.MatchString(text) {
+			c.scan(text, link.Location, "ext-link")
+			return
+		}
+	}
+	for _, p := range registryPatterns {
+		if !p.named(link.Type) {
+			continue
+		}
+		for _, value := range append([]string{link.Text}, hrefValues(link.Href)...) {
+			if id := p.identify(value); id != "" {
+				c.add(RegistryID{Registry: p.registry, ID: id, Source: link.Location, Element: "ext-link"})
+				return
+			}
+		}
+		return
+	}
+}
+
+// hrefValues splits a link target into the values that may hold an identifier: the
+// path segments, last first, and query values of a URL, or the target itself.
+func hrefValues(href string) []string {
+	href = strings.TrimSpace(href)
+	u, err := url.Parse(href)
+	if err != nil || u.Scheme == "" {
+		return []string{href}
+	}
+	var values []string
+	segments := strings.Split(u.Path, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		values = append(values, segments[i])
+	}
+	for _, vs := range u.Query() {
+		values = append(values, vs...)
+	}
+	return values
+}
+
+// ------------------------ scanExtLinks ------------------------
+
+/*
+scanExtLinks collects every <ext-link> element in a PMC document.
+
+Parameters:
+  - xmlBytes: Raw XML of the article.
+  - opts: Decoding options used for the main parse.
+
+Returns:
+  - The links in document order, each tagged with the part of the article it appeared in.
+
+Behavior:
+  - Streams tokens rather than unmarshalling, so links nested inside paragraphs are found.
+  - Stops quietly at the first token error; the main unmarshal reports real syntax errors.
+*/
+func scanExtLinks(xmlBytes []byte, opts DecodeOptions) []PMCExtLink {
+	dec := newDecoder(bytes.NewReader(xmlBytes), !opts.Lenient, &ParseInfo{})
+	var links []PMCExtLink
+	var stack []string
+	var current *PMCExtLink
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if t.Name.Local == "ext-link" && current == nil {
+				current = &PMCExtLink{Location: extLinkLocation(stack)}
+				for _, a := range t.Attr {
+					switch a.Name.Local {
+					case "ext-link-type":
+						current.Type = a.Value
+					case "href":
+						current.Href = a.Value
+					}
+				}
+			}
+		case xml.CharData:
+			if current != nil {
+				current.Text += string(t)
+			}
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if t.Name.Local == "ext-link" && current != nil {
+				current.Text = strings.TrimSpace(current.Text)
+				links = append(links, *current)
+				current = nil
+			}
+		}
+	}
+	return links
+}
+
+// extLinkLocation names the article part enclosing the current element.
+func extLinkLocation(stack []string) string {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch stack[i] {
+		case "abstract", "trans-abstract":
+			return "abstract"
+		case "body":
+			return "body"
+		case "back":
+			return "back"
+		case "front":
+			return "metadata"
+		}
+	}
+	return "metadata"
+}
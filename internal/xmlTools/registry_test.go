@@ -0,0 +1,126 @@
+package xmlTools_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Test: ExtractRegistryIDs ------------------------
+//
+
+// TestExtractRegistryIDs_PubMed verifies that DataBankList accession numbers and
+// free-text mentions in the abstract are both reported, normalized and de-duplicated,
+// and that a registry accession without its prefix is given one.
+func TestExtractRegistryIDs_PubMed(t *testing.T) {
+	article := &xmlTools.PubmedArticle{}
+	article.MedlineCitation.Article.DataBankList.DataBanks = []xmlTools.DataBank{
+		{DataBankName: "ClinicalTrials.gov", AccessionNumberList: []string{"NCT01234567"}},
+		{DataBankName: "GENBANK", AccessionNumberList: []string{"AB123456"}},
+		{DataBankName: "ISRCTN", AccessionNumberList: []string{" 12345678 "}},
+	}
+	article.MedlineCitation.Article.Abstract.AbstractText = xmlTools.AbstractTexts{
+		{Label: "TRIAL REGISTRATION", Text: "Registered as NCT 01234567 and ISRCTN12345678."},
+		{Label: "PROTOCOL", Text: "Protocol CRD42019123456 (EudraCT 2004-000123-45)."},
+	}
+
+	ids := xmlTools.ExtractRegistryIDs(article)
+
+	expected := []xmlTools.RegistryID{
+		{Registry: "ClinicalTrials.gov", ID: "NCT01234567", Source: "databank", Element: "AccessionNumber"},
+		{Registry: "ISRCTN", ID: "ISRCTN12345678", Source: "databank", Element: "AccessionNumber"},
+		{Registry: "ClinicalTrials.gov", ID: "NCT01234567", Source: "abstract", Element: "text"},
+		{Registry: "ISRCTN", ID: "ISRCTN12345678", Source: "abstract", Element: "text"},
+		{Registry: "EudraCT", ID: "2004-000123-45", Source: "abstract", Element: "text"},
+		{Registry: "PROSPERO", ID: "CRD42019123456", Source: "abstract", Element: "text"},
+	}
+	if len(ids) != len(expected) {
+		t.Fatalf("expected %d IDs, got %d: %+v", len(expected), len(ids), ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Errorf("ID %d: expected %+v, got %+v", i, expected[i], ids[i])
+		}
+	}
+}
+
+// TestExtractRegistryIDs_PMCExtLink verifies that trial IDs inside <ext-link>
+// elements nested in abstract paragraphs are found when parsing a PMC file, and that
+// a link whose ext-link-type names a registry is kept, with its prefix, even when its
+// text omits it.
+func TestExtractRegistryIDs_PMCExtLink(t *testing.T) {
+	tmpDir := t.TempDir()
+	xmlPath := filepath.Join(tmpDir, "article.xml")
+
+	doc := `<article xmlns:xlink="http://www.w3.org/1999/xlink" article-type="research-article">
+  <front><article-meta>
+    <title-group><article-title>A trial</article-title></title-group>
+    <abstract><p>Trial registration: <ext-link ext-link-type="clinicaltrials.gov" xlink:href="NCT07654321">NCT07654321</ext-link></p></abstract>
+  </article-meta></front>
+  <body><sec><title>Methods</title>
+    <p>Registered as <ext-link ext-link-type="ISRCTN" xlink:href="https://www.isrctn.com/12345678">12345678</ext-link>.</p>
+    <p>See <ext-link ext-link-type="uri" xlink:href="https://example.org/12345678">our site</ext-link>.</p>
+  </sec></body>
+</article>`
+	if err := os.WriteFile(xmlPath, []byte(doc), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	data, err := xmlTools.ParsePubmedXML(xmlPath)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	pmc, ok := data.(*xmlTools.PMCArticle)
+	if !ok {
+		t.Fatalf("expected *PMCArticle, got %T", data)
+	}
+
+	expected := []xmlTools.RegistryID{
+		{Registry: "ClinicalTrials.gov", ID: "NCT07654321", Source: "abstract", Element: "ext-link"},
+		{Registry: "ISRCTN", ID: "ISRCTN12345678", Source: "body", Element: "ext-link"},
+	}
+	if len(pmc.RegistryIDs) != len(expected) {
+		t.Fatalf("expected %d registry IDs, got %d: %+v", len(expected), len(pmc.RegistryIDs), pmc.RegistryIDs)
+	}
+	for i := range expected {
+		if pmc.RegistryIDs[i] != expected[i] {
+			t.Errorf("ID %d: expected %+v, got %+v", i, expected[i], pmc.RegistryIDs[i])
+		}
+	}
+}
+
+// TestExtractRegistryIDs_RegistryLinks verifies that links typed with a registry are
+// read from their text or href, given the registry's prefix, and dropped rather than
+// recorded with a URL when neither holds an identifier.
+func TestExtractRegistryIDs_RegistryLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		link     xmlTools.PMCExtLink
+		expected string // Expected ID, "" when the link is dropped
+	}{
+		{"bare text", xmlTools.PMCExtLink{Type: "ISRCTN", Href: "https://www.isrctn.com/12345678", Text: "12345678"}, "ISRCTN12345678"},
+		{"padded text", xmlTools.PMCExtLink{Type: "PROSPERO", Text: " 42019123456\n"}, "CRD42019123456"},
+		{"href path", xmlTools.PMCExtLink{Type: "ISRCTN", Href: "https://www.isrctn.com/12345678/", Text: "the registry entry"}, "ISRCTN12345678"},
+		{"href query", xmlTools.PMCExtLink{Type: "clinicaltrials.gov", Href: "https://clinicaltrials.gov/show?term=01234567"}, "NCT01234567"},
+		{"bare href", xmlTools.PMCExtLink{Type: "clintrialgov", Href: " 07654321 "}, "NCT07654321"},
+		{"url without id", xmlTools.PMCExtLink{Type: "ISRCTN", Href: "https://www.isrctn.com/search", Text: "Registration"}, ""},
+		{"url only", xmlTools.PMCExtLink{Type: "clintrialgov", Href: "https://clinicaltrials.gov/"}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.link.Location = "body"
+			ids := xmlTools.ExtractRegistryIDs(&xmlTools.PMCArticle{ExtLinks: []xmlTools.PMCExtLink{test.link}})
+
+			switch {
+			case test.expected == "" && len(ids) != 0:
+				t.Errorf("expected the link to be dropped, got %+v", ids)
+			case test.expected != "" && (len(ids) != 1 || ids[0].ID != test.expected || ids[0].Element != "ext-link"):
+				t.Errorf("expected %q from an ext-link, got %+v", test.expected, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package xmlTools
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SourceFormat records which DTD an input file declared.
+// It is written to the JSON output as the "source_format" block.
+type SourceFormat struct {
+	RootElement     string `json:"root_element" legacy:"root_element" schema:"required"`
+	DoctypePublicID string `json:"doctype_public_id,omitempty" legacy:"doctype_public_id"`
+	DoctypeSystemID string `json:"doctype_system_id,omitempty" legacy:"doctype_system_id"`
+	DTDVersion      string `json:"dtd_version,omitempty" legacy:"dtd_version"`                                        // dtd-version attribute of the root, if any
+	Family          string `json:"family" legacy:"family" schema:"required,enum=pubmed|pubmed-book|jats|nlm|unknown"` // "pubmed", "pubmed-book", "jats", "nlm" or "unknown"
+	Version         string `json:"version,omitempty" legacy:"version"`                                                // normalized version, e.g. "2025" or "1.3"
+	Supported       bool   `json:"supported" legacy:"supported" schema:"required"`                                    // whether the xmlTools structs were written against this version
+}
+
+// SupportedVersions lists, per DTD family, the versions the xmlTools structs were designed for.
+// Files declaring anything else still parse, but produce a warning.
+var SupportedVersions = map[string][]string{
+	"pubmed":      {"2019", "2023", "2025"},
+	"pubmed-book": {"2019", "2023", "2025"},
+	"jats":        {"1.0", "1.1", "1.2", "1.3"},
+	"nlm":         {"3.0"},
+}
+
+var (
+	doctypePattern       = regexp.MustCompile(`(?s)^DOCTYPE\s+(\S+)(?:\s+PUBLIC\s+"([^"]*)"(?:\s+"([^"]*)")?|\s+SYSTEM\s+"([^"]*)")?`)
+	pubmedDTDPattern     = regexp.MustCompile(`(?:pubmed|bookdoc)_(\d{2})\d{4}\.dtd`)
+	pubmedYearPattern    = regexp.MustCompile(`(\d{4})//EN$`)
+	jatsPublicPattern    = regexp.MustCompile(`JATS.*\bv(\d+\.\d+)`)
+	nlmPublicPattern     = regexp.MustCompile(`DTD v(\d+\.\d+)`)
+	versionNumberPattern = regexp.MustCompile(`^(\d+)\.(\d+)`)
+)
+
+// ------------------------ DetectSourceFormat ------------------------
+
+/*
+DetectSourceFormat reads the prolog of an XML document and identifies its DTD.
+
+Parameters:
+  - xmlBytes: Raw XML document.
+  - opts: Decoding options used for the main parse.
+
+Returns:
+  - The detected SourceFormat.
+  - Warnings for undeclared or unsupported DTD versions.
+
+Behavior:
+  - Reads tokens only up to the root element (DOCTYPE directive and root attributes).
+  - PubMed versions come from the DTD file name (pubmed_250101.dtd → "2025") or public ID year.
+  - PMC versions come from the root dtd-version attribute or the public ID; "JATS" public IDs
+    and 1.x versions are reported as "jats", older 2.x/3.x as "nlm".
+*/
+func DetectSourceFormat(xmlBytes []byte, opts DecodeOptions) (SourceFormat, []string) {
+	var sf SourceFormat
+	dec := newDecoder(bytes.NewReader(xmlBytes), !opts.Lenient, &ParseInfo{})
+
+	for sf.RootElement == "" {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.Directive:
+			if m := doctypePattern.FindSubmatch(bytes.TrimSpace(t)); m != nil {
+				sf.DoctypePublicID = string(m[2])
+				sf.DoctypeSystemID = string(m[3]) + string(m[4])
+			}
+		case xml.StartElement:
+			sf.RootElement = t.Name.Local
+			for _, a := range t.Attr {
+				if a.Name.Local == "dtd-version" {
+					sf.DTDVersion = strings.TrimSpace(a.Value)
+				}
+			}
+		}
+	}
+
+	classifySourceFormat(&sf)
+
+	var warnings []string
+	switch {
+	case sf.Family == "unknown":
+		warnings = append(warnings, fmt.Sprintf("unrecognized document type <%s>", sf.RootElement))
+	case sf.Version == "":
+		warnings = append(warnings, fmt.Sprintf("%s document does not declare a DTD version", sf.Family))
+	case !sf.Supported:
+		warnings = append(warnings, fmt.Sprintf("%s DTD version %s is not one the parser was designed for (supported: %s)",
+			sf.Family, sf.Version, strings.Join(SupportedVersions[sf.Family], ", ")))
+	}
+	return sf, warnings
+}
+
+// classifySourceFormat fills Family, Version and Supported from the raw prolog fields.
+func classifySourceFormat(sf *SourceFormat) {
+	switch sf.RootElement {
+	case "PubmedArticleSet", "PubmedBookArticleSet":
+		sf.Family = "pubmed"
+		if sf.RootElement == "PubmedBookArticleSet" {
+			sf.Family = "pubmed-book"
+		}
+		if m := pubmedDTDPattern.FindStringSubmatch(sf.DoctypeSystemID); m != nil {
+			sf.Version = "20" + m[1]
+		} else if m := pubmedYearPattern.FindStringSubmatch(sf.DoctypePublicID); m != nil {
+			sf.Version = m[1]
+		}
+
+	case "article":
+		sf.Family = "jats"
+		if m := jatsPublicPattern.FindStringSubmatch(sf.DoctypePublicID); m != nil {
+			sf.Version = m[1]
+		} else if m := nlmPublicPattern.FindStringSubmatch(sf.DoctypePublicID); m != nil {
+			sf.Version = m[1]
+			sf.Family = "nlm"
+		}
+		if sf.DTDVersion != "" {
+			// The attribute is authoritative; drafts such as "1.1d3" count as their release
+			if m := versionNumberPattern.FindStringSubmatch(sf.DTDVersion); m != nil {
+				sf.Version = m[1] + "." + m[2]
+			} else {
+				sf.Version = sf.DTDVersion
+			}
+		}
+		if !strings.Contains(sf.DoctypePublicID, "JATS") && !strings.HasPrefix(sf.Version, "1.") && sf.Version != "" {
+			sf.Family = "nlm"
+		}
+
+	default:
+		sf.Family = "unknown"
+	}
+
+	for _, v := range SupportedVersions[sf.Family] {
+		if v == sf.Version {
+			sf.Supported = true
+		}
+	}
+}
+
+// String summarizes the format for logs and reports, e.g. "jats 1.3".
+func (sf SourceFormat) String() string {
+	version := sf.Version
+	if version == "" {
+		version = "(undeclared)"
+	}
+	return sf.Family + " " + version
+}
@@ -0,0 +1,80 @@
+package xmlTools_test
+
+import (
+	"testing"
+
+	"github.com/ashahide/pubparse/internal/xmlTools"
+)
+
+//
+// ------------------------ Test: DetectSourceFormat ------------------------
+//
+
+// TestDetectSourceFormat checks family/version detection for the DOCTYPE and
+// dtd-version variants seen in PubMed and PMC archives.
+func TestDetectSourceFormat(t *testing.T) {
+	tests := []struct {
+		name      string // Descriptive name for subtest
+		doc       string // XML prolog and root element
+		family    string // Expected DTD family
+		version   string // Expected normalized version
+		supported bool   // Expected support flag
+		warnings  int    // Expected number of warnings
+	}{
+		{
+			name:      "pubmed 2025",
+			doc:       `<!DOCTYPE PubmedArticleSet PUBLIC "-//NLM//DTD PubMedArticle, 1st January 2025//EN" "https://dtd.nlm.nih.gov/ncbi/pubmed/out/pubmed_250101.dtd"><PubmedArticleSet/>`,
+			family:    "pubmed",
+			version:   "2025",
+			supported: true,
+		},
+		{
+			name:     "pubmed 2024 unsupported",
+			doc:      `<!DOCTYPE PubmedArticleSet PUBLIC "-//NLM//DTD PubMedArticle, 1st January 2024//EN" "https://dtd.nlm.nih.gov/ncbi/pubmed/out/pubmed_240101.dtd"><PubmedArticleSet/>`,
+			family:   "pubmed",
+			version:  "2024",
+			warnings: 1,
+		},
+		{
+			name:      "jats 1.3 via public id",
+			doc:       `<!DOCTYPE article PUBLIC "-//NLM//DTD JATS (Z39.96) Journal Archiving and Interchange DTD v1.3 20210610//EN" "JATS-archivearticle1-3.dtd"><article/>`,
+			family:    "jats",
+			version:   "1.3",
+			supported: true,
+		},
+		{
+			name:      "jats draft via attribute",
+			doc:       `<article dtd-version="1.1d3"/>`,
+			family:    "jats",
+			version:   "1.1",
+			supported: true,
+		},
+		{
+			name:     "nlm 2.3",
+			doc:      `<!DOCTYPE article PUBLIC "-//NLM//DTD Journal Archiving and Interchange DTD v2.3 20070202//EN" "archivearticle.dtd"><article dtd-version="2.3"/>`,
+			family:   "nlm",
+			version:  "2.3",
+			warnings: 1,
+		},
+		{
+			name:     "undeclared",
+			doc:      `<article/>`,
+			family:   "jats",
+			warnings: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sf, warnings := xmlTools.DetectSourceFormat([]byte(test.doc), xmlTools.DecodeOptions{})
+
+			if sf.Family != test.family || sf.Version != test.version || sf.Supported != test.supported {
+				t.Errorf("expected %s %q (supported=%v), got %s %q (supported=%v)",
+					test.family, test.version, test.supported, sf.Family, sf.Version, sf.Supported)
+			}
+			if len(warnings) != test.warnings {
+				t.Errorf("expected %d warnings, got %v", test.warnings, warnings)
+			}
+		})
+	}
+}